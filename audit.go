@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/verifydb"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+var auditOlderThan string
+
+func newAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "List files that have not been verified recently",
+		Long: `audit reads the database written with --verify-db and lists the files whose last
+successful verification is older than --older-than. Files that were never verified
+successfully are not in the database and are therefore not listed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAudit(cmd, verifyDataOptions, auditOlderThan)
+		},
+	}
+	cmd.Flags().StringVar(&auditOlderThan, "older-than", "90d", "Age after which a verification counts as stale, e.g. 90d or 12h")
+	return cmd
+}
+
+func runAudit(cmd *cobra.Command, opts VerifyDataOptions, olderThan string) error {
+	if opts.VerifyDB == "" {
+		return errors.New("--verify-db is required")
+	}
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return err
+	}
+
+	db, err := verifydb.Open(opts.VerifyDB)
+	if err != nil {
+		slog.Error("opening verify database failed", "path", opts.VerifyDB, "error", err)
+		return err
+	}
+	stale := db.OlderThan(time.Now().Add(-age))
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(stale, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+		return nil
+	}
+	if len(stale) == 0 {
+		fmt.Fprintln(w, "None")
+		return nil
+	}
+	tbl := table.New("File Path", "Verified At")
+	tbl.WithWriter(w)
+	tbl.WithHeaderSeparatorRow('-')
+	tbl.WithPadding(10)
+	for _, file := range stale {
+		tbl.AddRow(file.FilePath, file.VerifiedAt.Format(time.RFC3339))
+	}
+	tbl.Print()
+	return nil
+}
+
+// parseAge parses a duration that may also be given in days, such as 90d.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+var benchRuns int
+
+func newBenchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark verification of the given paths",
+		Long: `bench runs the checker several times over the given paths with the given options
+and reports the mean, median and 95th percentile duration together with the throughput.
+It accepts the same flags as the root command.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBench(cmd, verifyDataOptions, benchRuns)
+		},
+	}
+	cmd.Flags().IntVarP(&benchRuns, "runs", "n", 5, "Number of times to run the checker")
+	return cmd
+}
+
+func runBench(cmd *cobra.Command, opts VerifyDataOptions, runs int) error {
+	if runs < 1 {
+		return fmt.Errorf("number of runs must be at least 1, got %d", runs)
+	}
+
+	folderPaths, err := getFolderPaths(opts)
+	if err != nil {
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	durations := make([]time.Duration, runs)
+	var files int
+	var bytes int64
+	for i := 0; i < runs; i++ {
+		files, bytes = 0, 0
+		start := time.Now()
+		for _, folderPath := range folderPaths {
+			result, err := processFolder(folderPath, validatorOpts)
+			if err != nil {
+				slog.Error("processing folder failed", "folder", folderPath, "error", err)
+				return err
+			}
+			files += result.TotalFiles
+			bytes += result.HashedBytes
+		}
+		durations[i] = time.Since(start)
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	mean := total / time.Duration(runs)
+
+	tbl := table.New("Result", "Value")
+	tbl.WithHeaderSeparatorRow('-')
+	tbl.WithPadding(10)
+	tbl.WithWriter(cmd.OutOrStdout())
+	tbl.AddRow("Runs", runs)
+	tbl.AddRow("Files", files)
+	tbl.AddRow("Hashed Bytes", bytes)
+	tbl.AddRow("Mean", mean)
+	tbl.AddRow("Median", percentile(durations, 50))
+	tbl.AddRow("P95", percentile(durations, 95))
+	tbl.AddRow("Files/s", fmt.Sprintf("%.1f", float64(files)/mean.Seconds()))
+	tbl.AddRow("MiB/s", fmt.Sprintf("%.1f", float64(bytes)/(1<<20)/mean.Seconds()))
+	tbl.Print()
+	return nil
+}
+
+// percentile returns the p-th percentile of the sorted durations using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+var canonicalizeKeepExt bool
+
+func newCanonicalizeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "canonicalize",
+		Short: "Rename files to the hash of their content",
+		Long: `canonicalize hashes every file in the given paths and renames the files whose name
+is not a hash to the SHA256 hash of their content. Files whose name is a different
+hash are corrupted and are left alone. Renames whose target already exists are
+reported as collisions and not performed. With --dry-run, the renames are only listed.
+Otherwise the renames must be confirmed on the terminal, or with -y.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCanonicalize(cmd, verifyDataOptions, canonicalizeKeepExt)
+		},
+	}
+	cmd.Flags().BoolVar(&canonicalizeKeepExt, "keep-ext", false, "Keep the file extension, renaming photo.jpg to <hash>.jpg")
+	return cmd
+}
+
+func runCanonicalize(cmd *cobra.Command, opts VerifyDataOptions, keepExt bool) error {
+	folderPaths, err := getFolderPaths(opts)
+	if err != nil {
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	for _, folderPath := range folderPaths {
+		if isRemote(folderPath) {
+			return fmt.Errorf("canonicalize does not support remote folders: %s", folderPath)
+		}
+	}
+	if !opts.DryRun && !confirm(cmd, opts, fmt.Sprintf("Rename the files in %s to their hashes?", strings.Join(folderPaths, ", "))) {
+		cmd.SilenceUsage = true
+		return errors.New("canonicalize not confirmed, nothing renamed")
+	}
+	release, err := lockFolders(opts, folderPaths)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	defer release()
+
+	var results []*validator.CanonicalizeResult
+	for _, folderPath := range folderPaths {
+		result, err := validator.Canonicalize(folderPath, keepExt, opts.DryRun, validatorOpts)
+		if err != nil {
+			slog.Error("canonicalizing folder failed", "folder", folderPath, "error", err)
+			return err
+		}
+		results = append(results, result)
+	}
+
+	printCanonicalizeResults(cmd.OutOrStdout(), opts, results)
+	return nil
+}
+
+// printCanonicalizeResults prints the renames of canonicalize and fix-names.
+func printCanonicalizeResults(w io.Writer, opts VerifyDataOptions, results []*validator.CanonicalizeResult) {
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+		return
+	}
+	for _, result := range results {
+		fmt.Fprintln(w, "Folder Path:", result.FolderPath)
+		fmt.Fprintln(w, "Already Canonical:", result.CanonicalFiles)
+		title := "Renamed Files:"
+		if result.DryRun {
+			title = "Files To Rename:"
+		}
+		printRenames(w, title, result.Renamed)
+		printRenames(w, "Collisions:", result.Collisions)
+		if len(result.CorruptedFiles) > 0 {
+			fmt.Fprintln(w, "\nCorrupted Files (not renamed):")
+			for _, path := range result.CorruptedFiles {
+				fmt.Fprintln(w, path)
+			}
+		}
+		if len(result.Errors) > 0 {
+			fmt.Fprintln(w, "\nErrors:")
+			tbl := table.New("File Path", "Error")
+			tbl.WithWriter(w)
+			tbl.WithHeaderSeparatorRow('-')
+			tbl.WithPadding(10)
+			for _, file := range result.Errors {
+				tbl.AddRow(file.FilePath, file.Error)
+			}
+			tbl.Print()
+		}
+		fmt.Fprintln(w, "")
+	}
+}
+
+func printRenames(w io.Writer, title string, renames []validator.Rename) {
+	fmt.Fprintln(w, "\n"+title)
+	if len(renames) == 0 {
+		fmt.Fprintln(w, "None")
+		return
+	}
+	tbl := table.New("File Path", "New Path")
+	tbl.WithWriter(w)
+	tbl.WithHeaderSeparatorRow('-')
+	tbl.WithPadding(10)
+	for _, rename := range renames {
+		tbl.AddRow(rename.FilePath, rename.NewPath)
+	}
+	tbl.Print()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/konidev20/verifydata/internal/manifest"
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+func newChunksCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "chunks <file> <chunk-manifest>",
+		Short: "Verify the byte ranges of a file against per-chunk hashes",
+		Long: `chunks checks a large file against a list of per-chunk SHA256 hashes, such as a
+BitTorrent piece list, and reports the byte ranges that are corrupted. Every line
+of the chunk manifest has the form "<offset>,<length>,<hash>". The file is read
+once, and the command exits with a non-zero status when a chunk does not match.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runChunks(cmd, verifyDataOptions, args[0], args[1])
+		},
+	}
+}
+
+func runChunks(cmd *cobra.Command, opts VerifyDataOptions, filePath, chunkManifest string) error {
+	if isRemote(filePath) {
+		return fmt.Errorf("chunks does not support remote files: %s", filePath)
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(chunkManifest)
+	if err != nil {
+		return err
+	}
+	chunks, err := manifest.ParseChunks(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", chunkManifest, err)
+	}
+
+	result, err := validator.VerifyChunks(filePath, chunks, validatorOpts)
+	if err != nil {
+		slog.Error("verifying chunks failed", "path", filePath, "error", err)
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+	} else {
+		fmt.Fprintln(w, "File Path:", result.FilePath)
+		fmt.Fprintln(w, "Total Chunks:", result.TotalChunks)
+		fmt.Fprintln(w, "Intact Chunks:", result.IntactChunks)
+		fmt.Fprintln(w, "\nCorrupted Ranges:")
+		if len(result.CorruptedChunks) == 0 {
+			fmt.Fprintln(w, "None")
+		} else {
+			tbl := table.New("Offset", "Length", "Expected Hash", "Actual Hash", "Reason")
+			tbl.WithWriter(w)
+			tbl.WithHeaderSeparatorRow('-')
+			tbl.WithPadding(10)
+			for _, chunk := range result.CorruptedChunks {
+				tbl.AddRow(chunk.Offset, chunk.Length, chunk.ExpectedHash, chunk.ActualHash, chunk.Reason)
+			}
+			tbl.Print()
+		}
+	}
+
+	if len(result.CorruptedChunks) > 0 {
+		cmd.SilenceUsage = true
+		return errors.New("corrupted chunks found")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+func newCompareCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compare <folder-a> <folder-b>",
+		Short: "Check that two folders hold the same files with the same content",
+		Long: `compare hashes the files found in both folders and lists the files whose content
+differs and those that exist in only one of them. Files are matched by their path
+relative to the folder. The command exits with a non-zero status when the folders
+differ. --exclude, --template and --workers apply as for a regular run.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCompare(cmd, verifyDataOptions, args[0], args[1])
+		},
+	}
+}
+
+func runCompare(cmd *cobra.Command, opts VerifyDataOptions, folderA, folderB string) error {
+	for _, folderPath := range []string{folderA, folderB} {
+		if isRemote(folderPath) {
+			return fmt.Errorf("compare does not support remote folders: %s", folderPath)
+		}
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	result, err := validator.Compare(folderA, folderB, validatorOpts)
+	if err != nil {
+		slog.Error("comparing folders failed", "error", err)
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+	} else {
+		fmt.Fprintln(w, "Identical Files:", result.IdenticalFiles)
+		printPaths(w, "Different Files:", result.DifferentFiles)
+		printPaths(w, "Only In "+folderA+":", result.OnlyInA)
+		printPaths(w, "Only In "+folderB+":", result.OnlyInB)
+		if len(result.Errors) > 0 {
+			fmt.Fprintln(w, "\nErrors:")
+			tbl := table.New("File Path", "Error")
+			tbl.WithWriter(w)
+			tbl.WithHeaderSeparatorRow('-')
+			tbl.WithPadding(10)
+			for _, file := range result.Errors {
+				tbl.AddRow(file.FilePath, file.Error)
+			}
+			tbl.Print()
+		}
+	}
+
+	if !result.Identical() {
+		cmd.SilenceUsage = true
+		return errors.New("folders differ")
+	}
+	return nil
+}
+
+func printPaths(w io.Writer, title string, paths []string) {
+	fmt.Fprintln(w, "\n"+title)
+	if len(paths) == 0 {
+		fmt.Fprintln(w, "None")
+		return
+	}
+	for _, path := range paths {
+		fmt.Fprintln(w, path)
+	}
+}
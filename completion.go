@@ -0,0 +1,34 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/konidev20/verifydata/internal/template"
+	"github.com/spf13/cobra"
+)
+
+// registerFlagCompletions lets shells complete the values of the persistent
+// flags that take one of a fixed set of names. Flags of subcommands are
+// registered with the subcommand, such as --hash of file.
+func registerFlagCompletions(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return template.Complete(toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+
+	failOn := make([]string, 0, len(failOnCategories))
+	for category := range failOnCategories {
+		failOn = append(failOn, category)
+	}
+	sort.Strings(failOn)
+	for flag, values := range map[string][]string{
+		"fail-on":         failOn,
+		"hash-source":     {"filename", "xattr", "sidecar", "index-db"},
+		"decrypt":         {"aes-gcm", "chacha20poly1305"},
+		"manifest-format": {"gnu", "bsd", "auto"},
+		"log-level":       {"debug", "info", "warn", "error"},
+		"log-format":      {"text", "json"},
+		"workers":         {autoIOWorkers},
+	} {
+		cmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// confirm asks whether to go ahead with a destructive operation and reports
+// whether the answer was yes, reading the answer from the command's input.
+// With -y, it answers yes without asking. Otherwise a standard input or
+// error that is a file must be a terminal: when it is not, confirm refuses
+// instead of waiting for an answer that never comes.
+func confirm(cmd *cobra.Command, opts VerifyDataOptions, prompt string) bool {
+	if opts.AssumeYes {
+		return true
+	}
+	in, out := cmd.InOrStdin(), cmd.ErrOrStderr()
+	if !interactive(in) || !interactive(out) {
+		slog.Error("not asking for confirmation without a terminal, pass -y to proceed", "prompt", prompt)
+		return false
+	}
+	fmt.Fprintf(out, "%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// interactive reports whether v is a terminal, or not a file at all, as
+// the readers and writers set with cmd.SetIn and cmd.SetErr.
+func interactive(v any) bool {
+	f, ok := v.(interface{ Fd() uintptr })
+	return !ok || isatty.IsTerminal(f.Fd())
+}
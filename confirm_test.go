@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestConfirm(t *testing.T) {
+	for _, tt := range []struct {
+		input     string
+		assumeYes bool
+		want      bool
+	}{
+		{"y\n", false, true},
+		{"YES\n", false, true},
+		{"no\n", false, false},
+		{"\n", false, false},
+		{"", false, false},
+		{"", true, true},
+	} {
+		cmd := &cobra.Command{}
+		cmd.SetIn(strings.NewReader(tt.input))
+		var prompt bytes.Buffer
+		cmd.SetErr(&prompt)
+		if got := confirm(cmd, VerifyDataOptions{AssumeYes: tt.assumeYes}, "Rename?"); got != tt.want {
+			t.Errorf("confirm with %q and -y=%v = %v, want %v", tt.input, tt.assumeYes, got, tt.want)
+		}
+		if want := map[bool]string{false: "Rename? [y/N] ", true: ""}[tt.assumeYes]; prompt.String() != want {
+			t.Errorf("Expected the prompt %q, got %q", want, prompt.String())
+		}
+	}
+}
+
+func TestConfirmWithoutTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	w.WriteString("y\n")
+	w.Close()
+
+	cmd := &cobra.Command{}
+	cmd.SetIn(r)
+	var prompt bytes.Buffer
+	cmd.SetErr(&prompt)
+	if confirm(cmd, VerifyDataOptions{}, "Rename?") {
+		t.Error("Expected confirm to refuse when stdin is a pipe")
+	}
+	if prompt.Len() != 0 {
+		t.Errorf("Expected no prompt, got %q", prompt.String())
+	}
+}
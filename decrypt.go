@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// newDecryptor returns the cipher selected by --decrypt, or nil when files
+// are not encrypted. The key is given hex encoded with --decrypt-key, or in
+// --decrypt-key-file, either as raw bytes or hex encoded.
+func newDecryptor(opts VerifyDataOptions) (cipher.AEAD, error) {
+	if opts.Decrypt == "" {
+		if opts.DecryptKey != "" || opts.DecryptKeyFile != "" {
+			return nil, errors.New("--decrypt-key and --decrypt-key-file require --decrypt")
+		}
+		return nil, nil
+	}
+
+	var key []byte
+	switch {
+	case opts.DecryptKey != "" && opts.DecryptKeyFile != "":
+		return nil, errors.New("--decrypt-key and --decrypt-key-file cannot be used together")
+	case opts.DecryptKey != "":
+		var err error
+		if key, err = hex.DecodeString(opts.DecryptKey); err != nil {
+			return nil, fmt.Errorf("--decrypt-key is not hex encoded: %w", err)
+		}
+	case opts.DecryptKeyFile != "":
+		data, err := os.ReadFile(opts.DecryptKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key = data
+		if decoded, err := hex.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+			key = decoded
+		}
+	default:
+		return nil, errors.New("--decrypt requires --decrypt-key or --decrypt-key-file")
+	}
+
+	aead, err := validator.NewAEAD(opts.Decrypt, key)
+	if err != nil {
+		return nil, fmt.Errorf("--decrypt: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/konidev20/verifydata/internal/doctor"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+var doctorSample int
+
+func newDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Find out why file names do not match their hashes",
+		Long: `doctor hashes a sample of the files in the given paths with several algorithms and
+checks how their names relate to their content: named after the hash with an
+extension, with the hash embedded in a longer name, with the hash in a sidecar
+file, in upper case, in base64, or after another algorithm. It lists what it
+found and prints the command line that would check the files, when verifydata
+supports the naming.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(cmd, verifyDataOptions, doctorSample)
+		},
+	}
+	cmd.Flags().IntVar(&doctorSample, "sample", 20, "Number of files of each folder to examine")
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, opts VerifyDataOptions, size int) error {
+	folderPaths, err := getFolderPaths(opts)
+	if err != nil {
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+	exclude, err := collectExcludePatterns(opts)
+	if err != nil {
+		return err
+	}
+
+	var reports []*doctor.Report
+	for _, folderPath := range folderPaths {
+		if isRemote(folderPath) {
+			return fmt.Errorf("doctor does not support remote folders: %s", folderPath)
+		}
+		report, err := doctor.Diagnose(folderPath, size, exclude)
+		if err != nil {
+			slog.Error("diagnosing folder failed", "folder", folderPath, "error", err)
+			return err
+		}
+		reports = append(reports, report)
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+		return nil
+	}
+	for _, report := range reports {
+		printReport(w, report)
+	}
+	return nil
+}
+
+func printReport(w io.Writer, report *doctor.Report) {
+	fmt.Fprintln(w, "Folder Path:", report.FolderPath)
+	fmt.Fprintln(w, "Sampled Files:", report.Sampled)
+	fmt.Fprintln(w, "\nFindings:")
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(w, "None")
+	} else {
+		tbl := table.New("Naming", "Files", "Supported", "Details")
+		tbl.WithWriter(w)
+		tbl.WithHeaderSeparatorRow('-')
+		tbl.WithPadding(4)
+		for _, finding := range report.Findings {
+			supported := "no"
+			if finding.Supported {
+				supported = "yes"
+			}
+			tbl.AddRow(finding.Strategy, fmt.Sprintf("%d/%d", finding.Matches, report.Sampled), supported, finding.Note)
+		}
+		tbl.Print()
+	}
+	if len(report.Unmatched) > 0 {
+		fmt.Fprintln(w, "\nFiles whose name matches no strategy, which may be corrupted or not content-addressed:")
+		for _, path := range report.Unmatched {
+			fmt.Fprintln(w, path)
+		}
+	}
+
+	fmt.Fprintln(w, "\nRecommendation:")
+	switch {
+	case report.Recommended == nil:
+		fmt.Fprintln(w, "No supported naming found. Check that the folder holds content-addressed files.")
+	case len(report.Recommended.Flags) == 0:
+		fmt.Fprintln(w, "The file names already match; no additional flags are needed.")
+		fmt.Fprintf(w, "verifydata -p '%s'\n", report.FolderPath)
+	default:
+		fmt.Fprintf(w, "verifydata -p '%s' %s\n", report.FolderPath, strings.Join(report.Recommended.Flags, " "))
+	}
+	fmt.Fprintln(w, "")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix is the prefix of the environment variables setting flags, such
+// as VERIFYDATA_PATH for --path.
+const envPrefix = "VERIFYDATA_"
+
+// envName returns the environment variable setting the flag name.
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnv sets every flag of cmd that is not given on the command line from
+// its environment variable, if it is set and not empty. Lists are read as
+// comma-separated values, as on the command line. The variables are
+// inherited by --on-corrupt commands along with VERIFYDATA_FILE and the
+// hashes, which set no flag since none is named file, expected-hash or
+// actual-hash.
+func applyEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		name := envName(flag.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", name, setErr)
+		}
+	})
+	return err
+}
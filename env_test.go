@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// envCommand returns a command with flags of the kinds applyEnv sets.
+func envCommand(path *string, workers *int, exclude *[]string, json *bool) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(path, "path", "", "")
+	cmd.Flags().IntVar(workers, "workers", 1, "")
+	cmd.Flags().StringSliceVar(exclude, "exclude-types", nil, "")
+	cmd.Flags().BoolVar(json, "json", false, "")
+	return cmd
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("VERIFYDATA_PATH", "/from/env")
+	t.Setenv("VERIFYDATA_WORKERS", "16")
+	t.Setenv("VERIFYDATA_EXCLUDE_TYPES", "log,tmp")
+	t.Setenv("VERIFYDATA_JSON", "")
+
+	var path string
+	var workers int
+	var exclude []string
+	var json bool
+	cmd := envCommand(&path, &workers, &exclude, &json)
+	// The command line takes precedence over the environment.
+	if err := cmd.ParseFlags([]string{"--path", "/from/cli"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if err := applyEnv(cmd); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+
+	if path != "/from/cli" {
+		t.Errorf("Expected --path from the command line, got %q", path)
+	}
+	if workers != 16 {
+		t.Errorf("Expected --workers 16 from the environment, got %d", workers)
+	}
+	if strings.Join(exclude, "|") != "log|tmp" {
+		t.Errorf("Expected the comma-separated list from the environment, got %q", exclude)
+	}
+	if json {
+		t.Error("Expected an empty variable to leave --json unset")
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	t.Setenv("VERIFYDATA_WORKERS", "many")
+
+	var path string
+	var workers int
+	var exclude []string
+	var json bool
+	cmd := envCommand(&path, &workers, &exclude, &json)
+	err := applyEnv(cmd)
+	if err == nil || !strings.HasPrefix(err.Error(), "VERIFYDATA_WORKERS: ") {
+		t.Errorf("Expected an error naming VERIFYDATA_WORKERS, got %v", err)
+	}
+
+	// A valid value on the command line leaves the variable unread.
+	cmd = envCommand(&path, &workers, &exclude, &json)
+	if err := cmd.ParseFlags([]string{"--workers", "2"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if err := applyEnv(cmd); err != nil || workers != 2 {
+		t.Errorf("Expected --workers 2 from the command line, got %d and %v", workers, err)
+	}
+}
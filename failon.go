@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// failOnCategories are the categories accepted by --fail-on, with the number
+// of files of that category in a result.
+var failOnCategories = map[string]func(*validator.Result) int{
+	"corrupted": func(r *validator.Result) int { return r.CorruptedFiles + r.DecryptErrors + r.ModifiedFiles },
+	"invalid":   func(r *validator.Result) int { return r.InvalidFiles + r.AlgorithmMismatches },
+	"missing":   func(r *validator.Result) int { return r.MissingFiles },
+	"errored":   func(r *validator.Result) int { return r.ErroredFiles + r.PathErrors },
+}
+
+// failOnStatuses are the categories of --fail-on in the order in which they
+// give the status of a failed result, with that status.
+var failOnStatuses = []struct{ category, status string }{
+	{"corrupted", "corrupted"},
+	{"errored", "error"},
+	{"missing", "missing"},
+	{"invalid", "invalid"},
+}
+
+// setStatus sets Failed and Status of the result following failOn, the same
+// way the exit status is decided: the result fails when it exceeds the
+// deadline, has files of a category in failOn, does not match the expected
+// tree hash or, with failOnZero, has no files at all. The status is then the
+// most severe of these categories, "error" for an exceeded deadline,
+// "tree_mismatch" for the tree hash or "empty" for no files, and "ok"
+// otherwise.
+func setStatus(result *validator.Result, failOn []string, failOnZero bool) {
+	result.Failed, result.Status = false, "ok"
+	for _, s := range failOnStatuses {
+		if slices.Contains(failOn, s.category) && failOnCategories[s.category](result) > 0 {
+			result.Failed, result.Status = true, s.status
+			return
+		}
+	}
+	switch {
+	case result.DeadlineExceeded:
+		result.Failed, result.Status = true, "error"
+	case result.TreeHashMismatch:
+		result.Failed, result.Status = true, "tree_mismatch"
+	case failOnZero && result.TotalFiles == 0:
+		result.Failed, result.Status = true, "empty"
+	}
+}
+
+// parseFailOn validates the categories given with --fail-on.
+func parseFailOn(categories []string) ([]string, error) {
+	var parsed []string
+	for _, c := range categories {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		if _, ok := failOnCategories[c]; !ok {
+			return nil, fmt.Errorf("unknown --fail-on category %q, expected corrupted, invalid, missing or errored", c)
+		}
+		parsed = append(parsed, c)
+	}
+	return parsed, nil
+}
+
+// checkFailOn returns an error naming the categories of files found in the
+// results that are listed in failOn.
+func checkFailOn(results []*validator.Result, failOn []string) error {
+	var failures []string
+	for _, c := range failOn {
+		n := 0
+		for _, result := range results {
+			n += failOnCategories[c](result)
+		}
+		if n > 0 {
+			failures = append(failures, fmt.Sprintf("%d %s", n, c))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %s files", strings.Join(failures, ", "))
+}
+
+// checkZeroFiles returns an error naming the folders of the results in which
+// no files matched.
+func checkZeroFiles(results []*validator.Result) error {
+	var empty []string
+	for _, result := range results {
+		if result.TotalFiles == 0 {
+			empty = append(empty, result.FolderPath)
+		}
+	}
+	if len(empty) == 0 {
+		return nil
+	}
+	return fmt.Errorf("no files matched in %s", strings.Join(empty, ", "))
+}
+
+// checkTreeHash returns an error giving the expected and the actual tree hash
+// of the first result that does not match the expected one.
+func checkTreeHash(results []*validator.Result) error {
+	for _, result := range results {
+		if !result.TreeHashMismatch {
+			continue
+		}
+		if result.TreeHash == "" {
+			return fmt.Errorf("tree hash mismatch: expected %s, but the tree hash of %s is unknown since not every file was checked", result.ExpectedTreeHash, result.FolderPath)
+		}
+		return fmt.Errorf("tree hash mismatch: expected %s, got %s", result.ExpectedTreeHash, result.TreeHash)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+func TestSetStatusModified(t *testing.T) {
+	// A file changed while it was read may have been tampered with, so it
+	// fails the default --fail-on like a corrupted one.
+	result := &validator.Result{TotalFiles: 1, ModifiedFiles: 1}
+	setStatus(result, []string{"corrupted"}, false)
+	if !result.Failed || result.Status != "corrupted" {
+		t.Errorf("Expected a modified file to fail as corrupted, got %v and %q", result.Failed, result.Status)
+	}
+	setStatus(result, []string{"errored"}, false)
+	if result.Failed {
+		t.Errorf("Expected a modified file not to fail as errored, got %q", result.Status)
+	}
+	if err := checkFailOn([]*validator.Result{result}, []string{"corrupted"}); err == nil {
+		t.Error("Expected checkFailOn to fail on a modified file")
+	}
+}
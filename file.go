@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fileExpect    string
+	fileAlgorithm string
+)
+
+func newFileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "file <path>",
+		Short: "Check a single file against a given hash",
+		Long: `file hashes a single file and compares the hash with the one given by --expect,
+whatever the name of the file, for checking a download against a published
+checksum. The command exits with a non-zero status when the file is corrupted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFile(cmd, verifyDataOptions, args[0], fileExpect, fileAlgorithm)
+		},
+	}
+	cmd.Flags().StringVar(&fileExpect, "expect", "", "Expected hash of the file, as hex")
+	cmd.Flags().StringVar(&fileAlgorithm, "hash", "sha256", "Hash algorithm of --expect: "+strings.Join(validator.FileAlgorithms(), ", "))
+	cmd.MarkFlagRequired("expect")
+	cmd.RegisterFlagCompletionFunc("hash", cobra.FixedCompletions(validator.FileAlgorithms(), cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func runFile(cmd *cobra.Command, opts VerifyDataOptions, filePath, expect, algorithm string) error {
+	if isRemote(filePath) {
+		return fmt.Errorf("file does not support remote files: %s", filePath)
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	check, err := validator.VerifyFile(filePath, expect, algorithm, validatorOpts)
+	if err != nil {
+		slog.Error("verifying file failed", "path", filePath, "error", err)
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(check, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+	} else {
+		status := "intact"
+		if !check.Intact {
+			status = "corrupted"
+		}
+		fmt.Fprintln(w, "File Path:", check.FilePath)
+		fmt.Fprintln(w, "Algorithm:", check.Algorithm)
+		fmt.Fprintln(w, "Expected Hash:", check.ExpectedHash)
+		fmt.Fprintln(w, "Actual Hash:", check.ActualHash)
+		fmt.Fprintln(w, "Status:", status)
+	}
+
+	if !check.Intact {
+		cmd.SilenceUsage = true
+		return errors.New("file is corrupted")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+func newFixNamesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "fix-names",
+		Short: "Normalize the case and extension of hash-named files",
+		Long: `fix-names renames the files in the given paths whose name is a SHA256 hash in upper-
+or mixed-case hex, or followed by an extension, to the lowercase hash alone, once their
+content is found to have that hash. Files whose content does not match are corrupted
+and are left alone, and files whose name is not a hash are left to canonicalize.
+Renames whose target already exists are reported as collisions and not performed.
+With --dry-run, the renames are only listed. Otherwise the renames must be confirmed
+on the terminal, or with -y.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFixNames(cmd, verifyDataOptions)
+		},
+	}
+}
+
+func runFixNames(cmd *cobra.Command, opts VerifyDataOptions) error {
+	folderPaths, err := getFolderPaths(opts)
+	if err != nil {
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	for _, folderPath := range folderPaths {
+		if isRemote(folderPath) {
+			return fmt.Errorf("fix-names does not support remote folders: %s", folderPath)
+		}
+	}
+	if !opts.DryRun && !confirm(cmd, opts, fmt.Sprintf("Normalize the names of the files in %s?", strings.Join(folderPaths, ", "))) {
+		cmd.SilenceUsage = true
+		return errors.New("fix-names not confirmed, nothing renamed")
+	}
+	release, err := lockFolders(opts, folderPaths)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	defer release()
+
+	var results []*validator.CanonicalizeResult
+	for _, folderPath := range folderPaths {
+		result, err := validator.FixNames(folderPath, opts.DryRun, validatorOpts)
+		if err != nil {
+			slog.Error("fixing names failed", "folder", folderPath, "error", err)
+			return err
+		}
+		results = append(results, result)
+	}
+
+	printCanonicalizeResults(cmd.OutOrStdout(), opts, results)
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/konidev20/verifydata/internal/manifest"
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateOutput string
+	generateAppend bool
+	generateSizes  bool
+)
+
+func newGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Write a sha256sum manifest of the given paths",
+		Long: `generate hashes every file in the given paths and writes a manifest in the format of
+sha256sum, which can be checked with --manifest or sha256sum -c. Paths in the manifest
+are relative to the directory of --output, or to the current directory when the
+manifest is written to stdout.
+
+With --output, entries are written as soon as they are hashed, so an interrupted run
+leaves a partial manifest behind. --append resumes from it: files already listed are
+not hashed again. The manifest is sorted by path once the run completes.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerate(cmd, verifyDataOptions, generateOutput, generateAppend, generateSizes)
+		},
+	}
+	cmd.Flags().StringVarP(&generateOutput, "output", "o", "", "Path of the manifest to write. The manifest is written to stdout when it is empty.")
+	cmd.Flags().BoolVar(&generateSizes, "sizes", false, "Record the size of every file as \"<hash> <size> <path>\", so that truncated files are found without hashing them")
+	cmd.Flags().BoolVar(&generateAppend, "append", false, "Resume an interrupted run, skipping the files already listed in --output")
+	return cmd
+}
+
+func runGenerate(cmd *cobra.Command, opts VerifyDataOptions, output string, appendTo, sizes bool) error {
+	if appendTo && output == "" {
+		return errors.New("--append requires --output")
+	}
+	folderPaths, err := getFolderPaths(opts)
+	if err != nil {
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+	for _, folderPath := range folderPaths {
+		if isRemote(folderPath) {
+			return fmt.Errorf("generate does not support remote folders: %s", folderPath)
+		}
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	base := "."
+	if output != "" {
+		base = filepath.Dir(output)
+	}
+	manifestPath := func(path string) string {
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			rel = path
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	if output == "" {
+		var mu sync.Mutex
+		var entries []validator.ManifestEntry
+		for _, folderPath := range folderPaths {
+			err := validator.GenerateManifest(folderPath, nil, validatorOpts, func(entry validator.ManifestEntry) error {
+				mu.Lock()
+				defer mu.Unlock()
+				entry.Path, entry.HasSize = manifestPath(entry.Path), sizes
+				entries = append(entries, entry)
+				return nil
+			})
+			if err != nil {
+				slog.Error("generating manifest failed", "folder", folderPath, "error", err)
+				return err
+			}
+		}
+		return manifest.Write(cmd.OutOrStdout(), manifest.Tidy(entries))
+	}
+
+	listed := make(map[string]bool)
+	if appendTo {
+		existing, err := readPartialManifest(output)
+		if err != nil {
+			slog.Error("reading partial manifest failed", "path", output, "error", err)
+			return err
+		}
+		for _, entry := range existing {
+			listed[entry.Path] = true
+		}
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	file, err := os.OpenFile(output, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, folderPath := range folderPaths {
+		// The manifest must not list itself when it is written into the folder.
+		skip := func(path string) bool {
+			p := manifestPath(path)
+			return listed[p] || p == filepath.Base(output)
+		}
+		err := validator.GenerateManifest(folderPath, skip, validatorOpts, func(entry validator.ManifestEntry) error {
+			entry.Path, entry.HasSize = manifestPath(entry.Path), sizes
+			_, err := fmt.Fprintln(file, manifest.Format(entry))
+			return err
+		})
+		if err != nil {
+			file.Close()
+			slog.Error("generating manifest failed", "folder", folderPath, "error", err)
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return tidyManifest(output)
+}
+
+// readPartialManifest reads the manifest left behind by an interrupted run. A
+// last line that was cut off while it was written is removed from the file.
+func readPartialManifest(path string) ([]validator.ManifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = data[:bytes.LastIndexByte(data, '\n')+1]
+		if err := os.Truncate(path, int64(len(data))); err != nil {
+			return nil, err
+		}
+	}
+	return manifest.Parse(bytes.NewReader(data))
+}
+
+// tidyManifest sorts the manifest at path and removes duplicate entries.
+func tidyManifest(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	entries, err := manifest.Parse(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := manifest.Write(file, manifest.Tidy(entries)); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/konidev20/verifydata/internal/indexdb"
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// newHashSource returns the source of expected hashes selected by
+// --hash-source, or nil to take them from the file names.
+func newHashSource(opts VerifyDataOptions) (validator.HashSource, error) {
+	name := opts.HashSource
+	if name == "" && opts.IndexDB != "" {
+		name = "index-db"
+	}
+	if opts.IndexDB != "" && name != "index-db" {
+		return nil, fmt.Errorf("--index-db cannot be used with --hash-source %s", name)
+	}
+
+	switch name {
+	case "", "filename":
+		return nil, nil
+	case "xattr":
+		if opts.XattrName == "" {
+			return nil, errors.New("--hash-source xattr requires --xattr-name")
+		}
+		return validator.XattrSource{Name: opts.XattrName}, nil
+	case "sidecar":
+		return validator.SidecarSource{}, nil
+	case "index-db":
+		if opts.IndexDB == "" {
+			return nil, errors.New("--hash-source index-db requires --index-db")
+		}
+		index, err := indexdb.Load(opts.IndexDB)
+		if err != nil {
+			slog.Error("reading index failed", "path", opts.IndexDB, "error", err)
+			return nil, err
+		}
+		return validator.IndexSource(index), nil
+	default:
+		return nil, fmt.Errorf("unknown --hash-source %q, expected filename, xattr, sidecar or index-db", name)
+	}
+}
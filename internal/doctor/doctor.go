@@ -0,0 +1,265 @@
+// Package doctor samples the files of a folder to find out why their names do
+// not match their hashes, and which flags would make them match.
+package doctor
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const sidecarExt = ".sha256"
+
+// Finding is a way of naming files that some of the sampled files follow.
+type Finding struct {
+	Strategy string `json:"strategy"`
+	Matches  int    `json:"matches"`
+	// Flags make verifydata check the files named this way. They are empty
+	// for names that already match and for namings verifydata does not support.
+	Flags     []string `json:"flags,omitempty"`
+	Supported bool     `json:"supported"`
+	Note      string   `json:"note"`
+}
+
+// Report is the outcome of diagnosing a folder.
+type Report struct {
+	FolderPath string    `json:"folder_path"`
+	Sampled    int       `json:"sampled"`
+	Unmatched  []string  `json:"unmatched,omitempty"`
+	Findings   []Finding `json:"findings"`
+	// Recommended is the finding that matched the most sampled files among
+	// those verifydata supports, if any.
+	Recommended *Finding `json:"recommended,omitempty"`
+}
+
+// strategy recognizes one way of naming a file after its content.
+type strategy struct {
+	name      string
+	flags     []string
+	supported bool
+	note      string
+	match     func(s sample) bool
+}
+
+// sample is a sampled file with the digests of its content.
+type sample struct {
+	name    string
+	sidecar string
+	sums    map[string][]byte
+}
+
+func (s sample) hex(alg string) string {
+	return hex.EncodeToString(s.sums[alg])
+}
+
+var strategies = []strategy{
+	{
+		name:      "exact",
+		supported: true,
+		note:      "The file name is the SHA256 hash of the content; no flags are needed.",
+		match:     func(s sample) bool { return s.name == s.hex("sha256") },
+	},
+	{
+		name:      "extension",
+		flags:     []string{`--name-pattern '^(?P<hash>[a-f0-9]{64})\.'`},
+		supported: true,
+		note:      "The file name is the SHA256 hash followed by an extension.",
+		match: func(s sample) bool {
+			base, _, ok := strings.Cut(s.name, ".")
+			return ok && base == s.hex("sha256")
+		},
+	},
+	{
+		name:      "embedded",
+		flags:     []string{`--name-pattern '(?P<hash>[a-f0-9]{64})'`},
+		supported: true,
+		note:      "The SHA256 hash is part of a longer file name.",
+		match: func(s sample) bool {
+			return s.name != s.hex("sha256") && strings.Contains(s.name, s.hex("sha256"))
+		},
+	},
+	{
+		name:      "sidecar",
+		flags:     []string{"--hash-source sidecar"},
+		supported: true,
+		note:      "The SHA256 hash is stored in a " + sidecarExt + " file next to the file.",
+		match:     func(s sample) bool { return s.sidecar != "" && s.sidecar == s.hex("sha256") },
+	},
+	{
+		name: "uppercase",
+		note: "The file name contains the SHA256 hash in upper-case hex; verifydata expects lower case, so the files need to be renamed.",
+		match: func(s sample) bool {
+			return strings.Contains(s.name, strings.ToUpper(s.hex("sha256")))
+		},
+	},
+	{
+		name: "base64",
+		note: "The file name contains the SHA256 hash encoded in base64 or base32 rather than hex, which verifydata does not support.",
+		match: func(s sample) bool {
+			sum := s.sums["sha256"]
+			// Padded encodings start with the unpadded ones, and base32 is
+			// matched regardless of case.
+			for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.RawURLEncoding} {
+				if strings.Contains(s.name, enc.EncodeToString(sum)) {
+					return true
+				}
+			}
+			return strings.Contains(strings.ToUpper(s.name), base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum))
+		},
+	},
+	{
+		name:  "md5",
+		note:  "The file name contains the MD5 hash of the content; verifydata only checks SHA256.",
+		match: func(s sample) bool { return strings.Contains(strings.ToLower(s.name), s.hex("md5")) },
+	},
+	{
+		name:  "sha1",
+		note:  "The file name contains the SHA1 hash of the content; verifydata only checks SHA256.",
+		match: func(s sample) bool { return strings.Contains(strings.ToLower(s.name), s.hex("sha1")) },
+	},
+	{
+		name:  "sha512",
+		note:  "The file name contains the SHA512 hash of the content; verifydata only checks SHA256.",
+		match: func(s sample) bool { return strings.Contains(strings.ToLower(s.name), s.hex("sha512")) },
+	},
+}
+
+// Diagnose hashes up to size files of the folder, in walk order, and tries
+// every known naming strategy on them. Files matching exclude are skipped.
+func Diagnose(folderPath string, size int, exclude *regexp.Regexp) (*Report, error) {
+	paths, err := samplePaths(folderPath, size, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{FolderPath: folderPath, Sampled: len(paths)}
+	counts := make([]int, len(strategies))
+	for _, path := range paths {
+		s, err := newSample(path)
+		if err != nil {
+			return nil, err
+		}
+		matched := false
+		for i, st := range strategies {
+			if st.match(s) {
+				counts[i]++
+				matched = true
+			}
+		}
+		if !matched {
+			report.Unmatched = append(report.Unmatched, path)
+		}
+	}
+
+	for i, st := range strategies {
+		if counts[i] == 0 {
+			continue
+		}
+		report.Findings = append(report.Findings, Finding{
+			Strategy:  st.name,
+			Matches:   counts[i],
+			Flags:     st.flags,
+			Supported: st.supported,
+			Note:      st.note,
+		})
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].Matches > report.Findings[j].Matches
+	})
+	for i := range report.Findings {
+		if report.Findings[i].Supported {
+			report.Recommended = &report.Findings[i]
+			break
+		}
+	}
+	return report, nil
+}
+
+// samplePaths returns the first size regular files of the folder, leaving out
+// sidecar files.
+func samplePaths(folderPath string, size int, exclude *regexp.Regexp) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if len(paths) >= size {
+			return filepath.SkipAll
+		}
+		if !info.Mode().IsRegular() || strings.HasSuffix(path, sidecarExt) {
+			return nil
+		}
+		if exclude != nil && exclude.MatchString(path) {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// newSample hashes the file with every algorithm in one pass and reads its
+// sidecar file, if there is one.
+func newSample(path string) (sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return sample{}, err
+	}
+	defer file.Close()
+
+	hashes := map[string]interface {
+		io.Writer
+		Sum([]byte) []byte
+	}{
+		"sha256": sha256.New(),
+		"md5":    md5.New(),
+		"sha1":   sha1.New(),
+		"sha512": sha512.New(),
+	}
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), file); err != nil {
+		return sample{}, err
+	}
+
+	s := sample{name: filepath.Base(path), sums: make(map[string][]byte, len(hashes))}
+	for alg, h := range hashes {
+		s.sums[alg] = h.Sum(nil)
+	}
+	s.sidecar, err = readSidecar(path + sidecarExt)
+	return s, err
+}
+
+func readSidecar(path string) (string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(io.LimitReader(file, 4096)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), nil
+}
@@ -0,0 +1,83 @@
+package doctor
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDiagnoseExtension(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		content := fmt.Sprint("photo ", i)
+		writeFile(t, filepath.Join(dir, sha256Hex(content)+".jpg"), content)
+	}
+	writeFile(t, filepath.Join(dir, sha256Hex("exact")), "exact")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not content-addressed")
+
+	report, err := Diagnose(dir, 20, nil)
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if report.Sampled != 5 {
+		t.Errorf("Expected 5 sampled files, got %d", report.Sampled)
+	}
+	if report.Recommended == nil || report.Recommended.Strategy != "extension" || report.Recommended.Matches != 3 {
+		t.Fatalf("Expected the extension strategy to be recommended, got %+v", report.Recommended)
+	}
+	if !strings.Contains(report.Recommended.Flags[0], `^(?P<hash>[a-f0-9]{64})\.`) {
+		t.Errorf("Unexpected flags %v", report.Recommended.Flags)
+	}
+	if len(report.Unmatched) != 1 || filepath.Base(report.Unmatched[0]) != "notes.txt" {
+		t.Errorf("Expected notes.txt to be unmatched, got %v", report.Unmatched)
+	}
+}
+
+func TestDiagnoseUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	md5Sum := md5.Sum([]byte("legacy"))
+	writeFile(t, filepath.Join(dir, hex.EncodeToString(md5Sum[:])), "legacy")
+	writeFile(t, filepath.Join(dir, strings.ToUpper(sha256Hex("upper"))), "upper")
+	sum := sha256.Sum256([]byte("b64"))
+	writeFile(t, filepath.Join(dir, base64.RawURLEncoding.EncodeToString(sum[:])), "b64")
+	writeFile(t, filepath.Join(dir, "blob"), "sidecar")
+	writeFile(t, filepath.Join(dir, "blob.sha256"), sha256Hex("sidecar")+"  blob\n")
+
+	report, err := Diagnose(dir, 20, nil)
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	found := make(map[string]Finding)
+	for _, finding := range report.Findings {
+		found[finding.Strategy] = finding
+	}
+	for _, strategy := range []string{"md5", "uppercase", "base64"} {
+		if f, ok := found[strategy]; !ok || f.Supported || f.Matches != 1 {
+			t.Errorf("Expected one file named by %s, got %+v", strategy, found)
+		}
+	}
+	if report.Recommended == nil || report.Recommended.Strategy != "sidecar" {
+		t.Errorf("Expected the sidecar strategy to be recommended, got %+v", report.Recommended)
+	}
+	if report.Sampled != 4 {
+		t.Errorf("Expected the sidecar file not to be sampled, got %d files", report.Sampled)
+	}
+}
@@ -0,0 +1,88 @@
+// Package hook runs a user supplied command for every corrupted file.
+package hook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Failure is a hook invocation that could not be run or exited unsuccessfully.
+type Failure struct {
+	FilePath string
+	Err      error
+	Output   string
+}
+
+// Runner runs the command asynchronously with a bounded number of concurrent invocations.
+// The file path and hashes are passed in the VERIFYDATA_FILE, VERIFYDATA_EXPECTED_HASH
+// and VERIFYDATA_ACTUAL_HASH environment variables.
+type Runner struct {
+	command string
+	dryRun  bool
+	out     io.Writer
+	sem     chan struct{}
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	failures []Failure
+}
+
+// New returns a runner for the shell command. In dry-run mode the command is
+// only printed to out.
+func New(command string, jobs int, dryRun bool, out io.Writer) *Runner {
+	if jobs < 1 {
+		jobs = 1
+	}
+	return &Runner{command: command, dryRun: dryRun, out: out, sem: make(chan struct{}, jobs)}
+}
+
+// Corrupted schedules the command for a corrupted file. It does not block.
+func (r *Runner) Corrupted(filePath, expectedHash, actualHash string) {
+	if r.dryRun {
+		r.mu.Lock()
+		fmt.Fprintf(r.out, "Would run %q for %s\n", r.command, filePath)
+		r.mu.Unlock()
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.sem <- struct{}{}
+		defer func() { <-r.sem }()
+
+		cmd := shellCommand(context.Background(), r.command)
+		cmd.Env = append(os.Environ(),
+			"VERIFYDATA_FILE="+filePath,
+			"VERIFYDATA_EXPECTED_HASH="+expectedHash,
+			"VERIFYDATA_ACTUAL_HASH="+actualHash,
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			r.mu.Lock()
+			r.failures = append(r.failures, Failure{FilePath: filePath, Err: err, Output: strings.TrimSpace(string(output))})
+			r.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for all scheduled invocations and returns the ones that failed.
+func (r *Runner) Wait() []Failure {
+	r.wg.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test commands use sh syntax")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+
+	r := New(`echo "$VERIFYDATA_FILE $VERIFYDATA_EXPECTED_HASH $VERIFYDATA_ACTUAL_HASH" >> `+out, 2, false, nil)
+	r.Corrupted("/data/a", "expected", "actual")
+	if failures := r.Wait(); len(failures) != 0 {
+		t.Fatalf("Expected no failures, got %v", failures)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "/data/a expected actual" {
+		t.Errorf("Hook got %q", got)
+	}
+
+	r = New("echo broken; exit 3", 2, false, nil)
+	r.Corrupted("/data/a", "expected", "actual")
+	r.Corrupted("/data/b", "expected", "actual")
+	failures := r.Wait()
+	if len(failures) != 2 || failures[0].Output != "broken" {
+		t.Errorf("Expected 2 captured failures, got %v", failures)
+	}
+}
+
+func TestRunnerDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	r := New("exit 1", 1, true, &buf)
+	r.Corrupted("/data/a", "expected", "actual")
+	if failures := r.Wait(); len(failures) != 0 {
+		t.Errorf("Expected the command not to run, got %v", failures)
+	}
+	if !strings.Contains(buf.String(), "/data/a") {
+		t.Errorf("Expected dry run to print the file, got %q", buf.String())
+	}
+}
@@ -0,0 +1,42 @@
+// Package indexdb reads expected hashes from a SQLite index.
+//
+// The index holds a table
+//
+//	CREATE TABLE files (path TEXT PRIMARY KEY, hash TEXT NOT NULL)
+//
+// where path is relative to the verified folder, with forward slashes, and
+// hash is the hex encoded SHA256 hash of the file's content.
+package indexdb
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// Load reads all entries of the index at path, keyed by relative path.
+func Load(path string) (map[string]string, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT path, hash FROM files")
+	if err != nil {
+		return nil, fmt.Errorf("reading index %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]string)
+	for rows.Next() {
+		var p, hash string
+		if err := rows.Scan(&p, &hash); err != nil {
+			return nil, fmt.Errorf("reading index %s: %w", path, err)
+		}
+		index[p] = strings.ToLower(hash)
+	}
+	return index, rows.Err()
+}
@@ -0,0 +1,33 @@
+package indexdb
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE files (path TEXT PRIMARY KEY, hash TEXT NOT NULL);
+		INSERT INTO files VALUES ('a/b.bin', '6AE8A75555209FD6C44157C0AED8016E763FF435A19CF186F76863140143FF72')`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	index, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := index["a/b.bin"]; got != "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72" {
+		t.Errorf("Expected the lower-cased hash of a/b.bin, got %q", got)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("Expected an error for a missing index")
+	}
+}
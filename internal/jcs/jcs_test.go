@@ -0,0 +1,100 @@
+package jcs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+func TestMarshalNumbers(t *testing.T) {
+	// Values from the number serialization samples of RFC 8785, appendix B.
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		{0, "0"},
+		{math.Copysign(0, -1), "0"},
+		{1, "1"},
+		{-1.5, "-1.5"},
+		{333333333.3333333, "333333333.3333333"},
+		{1e21, "1e+21"},
+		{1e20, "100000000000000000000"},
+		{0.000001, "0.000001"},
+		{0.0000001, "1e-7"},
+		{4.50, "4.5"},
+		{2e-3, "0.002"},
+		{9007199254740992, "9007199254740992"},
+		{1.7976931348623157e308, "1.7976931348623157e+308"},
+		{5e-324, "5e-324"},
+		{-1.4999999999999999e-15, "-1.5e-15"},
+	}
+	for _, tt := range tests {
+		f, want := tt.f, tt.want
+		got, err := Marshal(f)
+		if err != nil {
+			t.Fatalf("Marshal(%v) failed: %v", f, err)
+		}
+		if string(got) != want {
+			t.Errorf("Marshal(%v) = %s, want %s", f, got, want)
+		}
+	}
+}
+
+func TestMarshalObjects(t *testing.T) {
+	// The member names of the sorting sample of RFC 8785, section 3.2.3, and
+	// strings that encoding/json would escape but canonical JSON does not.
+	value := map[string]any{
+		"\u20ac":     "Euro Sign",
+		"\r":         "Carriage Return",
+		"\ufb33":     "Hebrew Letter Dalet With Dagesh",
+		"1":          "One",
+		"\U0001f600": "Emoji: Grinning Face",
+		"\u0080":     "Control\u007f",
+		"\u00f6":     "Latin Small Letter O With Diaeresis",
+		"nested":     []any{map[string]any{"b": 1, "a": "<&>\u2028"}, nil, true},
+	}
+	want := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"nested\":[{\"a\":\"<&>\u2028\",\"b\":1},null,true]," +
+		"\"\u0080\":\"Control\u007f\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\"," +
+		"\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	got, err := Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Unexpected encoding\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestMarshalResultStable(t *testing.T) {
+	newResult := func() *validator.Result {
+		return &validator.Result{
+			FolderPath:        "/srv/store",
+			TotalFiles:        3,
+			IntactFiles:       1,
+			CorruptedFiles:    1,
+			CorruptedFileList: []validator.CorruptedFile{{FilePath: "/srv/store/a\tb", ActualHash: "e3b0c442"}},
+			CorruptionRate:    1.0 / 3,
+			HashedBytes:       1 << 40,
+			Files:             []validator.FileRecord{{FilePath: "/srv/store/c", ModTime: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}},
+		}
+	}
+	first, err := Marshal([]*validator.Result{newResult()})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, err := Marshal([]*validator.Result{newResult()})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(again) != string(first) {
+			t.Fatalf("Expected identical output, got\n%s\n%s", first, again)
+		}
+	}
+	const prefix = `[{"algorithm_mismatches":0,"alternate_matches":0,"broken_symlinks":0,"content_cache_bytes":0,"content_cache_hits":0,"corrupted_bytes":0,"corrupted_file_list":[{"actual_hash":"e3b0c442","file_path":"/srv/store/a\tb","size":0}],"corrupted_files":1,"corruption_rate":0.3333333333333333,`
+	if got := string(first); len(got) < len(prefix) || got[:len(prefix)] != prefix {
+		t.Errorf("Expected output to start with %s, got %s", prefix, got)
+	}
+}
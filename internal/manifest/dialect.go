@@ -0,0 +1,89 @@
+package manifest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// Dialect is the line format of a manifest.
+type Dialect string
+
+const (
+	// DialectGNU is the format of sha256sum: "<hash>  <path>".
+	DialectGNU Dialect = "gnu"
+	// DialectBSD is the tagged format of BSD sha256, shasum --tag and
+	// openssl dgst: "SHA256 (<path>) = <hash>".
+	DialectBSD Dialect = "bsd"
+	// DialectAuto tells the formats apart by the shape of every line.
+	DialectAuto Dialect = "auto"
+)
+
+// ParseDialect returns the dialect with the given name.
+func ParseDialect(name string) (Dialect, error) {
+	switch dialect := Dialect(name); dialect {
+	case DialectGNU, DialectBSD, DialectAuto:
+		return dialect, nil
+	}
+	return "", fmt.Errorf("unknown manifest format %q: expected gnu, bsd or auto", name)
+}
+
+// bsdLine matches a tagged line. openssl dgst leaves out the space before the
+// parenthesis and the equals sign, and other tools pad them with more.
+var bsdLine = regexp.MustCompile(`^([A-Za-z0-9-]+)[ \t]*\((.*)\)[ \t]*=[ \t]*([0-9A-Fa-f]+)$`)
+
+// Parse reads a manifest in the given dialect. Blank lines and lines
+// starting with # are skipped, except that a header line must be valid. Tagged
+// lines must name SHA256, since that is the only algorithm verifydata checks.
+func (d Dialect) Parse(r io.Reader) ([]validator.ManifestEntry, error) {
+	entries, _, err := d.parse(r)
+	return entries, err
+}
+
+func (d Dialect) parse(r io.Reader) ([]validator.ManifestEntry, Header, error) {
+	if d == DialectGNU || d == "" {
+		return parse(r)
+	}
+
+	var entries []validator.ManifestEntry
+	var header Header
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := cleanLine(scanner.Text(), lineNo)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if err := header.comment(line); err != nil {
+				return nil, Header{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		match := bsdLine.FindStringSubmatch(line)
+		if match == nil {
+			if d == DialectBSD {
+				return nil, Header{}, fmt.Errorf("line %d: expected \"SHA256 (<path>) = <hash>\"", lineNo)
+			}
+			entry, err := parseLine(line)
+			if err != nil {
+				return nil, Header{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			entries = append(entries, entry)
+			continue
+		}
+		if tag := strings.ToUpper(match[1]); tag != "SHA256" && tag != "SHA2-256" {
+			return nil, Header{}, fmt.Errorf("line %d: %w %s, only SHA256 can be verified", lineNo, validator.ErrUnsupportedAlgo, match[1])
+		}
+		if match[2] == "" {
+			return nil, Header{}, fmt.Errorf("line %d: missing path", lineNo)
+		}
+		entries = append(entries, validator.ManifestEntry{Path: match[2], Hash: strings.ToLower(match[3])})
+	}
+	return entries, header, scanner.Err()
+}
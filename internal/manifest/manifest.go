@@ -0,0 +1,306 @@
+// Package manifest reads checksum manifests such as the output of sha256sum.
+package manifest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// maxRedirects is the number of redirects followed when fetching a manifest.
+const maxRedirects = 10
+
+// totalHeader starts the comment line of a manifest giving the expected sum
+// of the sizes of the listed files, such as "# total-bytes: 1048576".
+const totalHeader = "# total-bytes:"
+
+// Header is what the comment lines of a manifest say about the listed files
+// as a whole.
+type Header struct {
+	// TotalBytes is the expected sum of the sizes of the listed files. It is
+	// only known when HasTotal is set.
+	TotalBytes int64
+	HasTotal   bool
+}
+
+// comment reads the comment line into the header when it is a header line.
+func (h *Header) comment(line string) error {
+	value, ok := strings.CutPrefix(line, totalHeader)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid total size %q", strings.TrimSpace(value))
+	}
+	h.TotalBytes, h.HasTotal = n, true
+	return nil
+}
+
+// Parse reads a manifest with lines of the form "<hash>  <path>", where the
+// path may be prefixed with "*" to mark binary mode as sha256sum does, or
+// "<hash> <size> <path>" with the expected size in bytes. Blank lines and lines
+// starting with # are skipped, except that a header line must be valid, see
+// Header. Byte order marks, CRLF line endings and other trailing whitespace
+// are tolerated.
+func Parse(r io.Reader) ([]validator.ManifestEntry, error) {
+	entries, _, err := parse(r)
+	return entries, err
+}
+
+func parse(r io.Reader) ([]validator.ManifestEntry, Header, error) {
+	var entries []validator.ManifestEntry
+	var header Header
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := cleanLine(scanner.Text(), lineNo)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if err := header.comment(line); err != nil {
+				return nil, Header{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		entry, err := parseLine(line)
+		if err != nil {
+			return nil, Header{}, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, header, scanner.Err()
+}
+
+// cleanLine drops what tools on other platforms add to the lines of a
+// manifest: the byte order mark before the first line, and the carriage
+// return and any other trailing whitespace at the end of every line.
+func cleanLine(line string, lineNo int) string {
+	if lineNo == 1 {
+		line = strings.TrimPrefix(line, "\ufeff")
+	}
+	return strings.TrimRight(line, " \t\r")
+}
+
+// parseLine parses a line of a sha256sum manifest. The hash may be in any
+// case, and may be followed by any run of spaces and tabs before the path.
+// A single space followed by a number gives the size.
+func parseLine(line string) (validator.ManifestEntry, error) {
+	i := strings.IndexAny(line, " \t")
+	if i <= 0 {
+		return validator.ManifestEntry{}, errors.New("expected \"<hash>  <path>\"")
+	}
+	rest := strings.TrimLeft(line[i:], " \t")
+	separator := line[i : len(line)-len(rest)]
+	entry := validator.ManifestEntry{Hash: strings.ToLower(line[:i])}
+	switch {
+	case strings.HasPrefix(rest, "*"):
+		entry.Path = rest[1:]
+	case separator != " ":
+		entry.Path = rest
+	default:
+		size, path, ok := strings.Cut(rest, " ")
+		n, err := strconv.ParseInt(size, 10, 64)
+		if !ok || err != nil || n < 0 {
+			return validator.ManifestEntry{}, errors.New("expected \"<hash>  <path>\" or \"<hash> <size> <path>\"")
+		}
+		entry.Path, entry.Size, entry.HasSize = path, n, true
+	}
+	if entry.Path == "" {
+		return validator.ManifestEntry{}, errors.New("missing path")
+	}
+	return entry, nil
+}
+
+// IsURL reports whether the manifest location is an http or https URL.
+func IsURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Load reads the manifest from a local file or an http(s) URL. Relative paths
+// are resolved against base. When base is empty, they are resolved against the
+// manifest's directory for a local manifest and against the current directory
+// for a remote one. Absolute paths are kept as they are. The lines are read in
+// the given dialect.
+func Load(location, base string, dialect Dialect) ([]validator.ManifestEntry, error) {
+	entries, _, err := LoadHeader(location, base, dialect)
+	return entries, err
+}
+
+// LoadHeader is Load, also returning the header of the manifest.
+func LoadHeader(location, base string, dialect Dialect) ([]validator.ManifestEntry, Header, error) {
+	var entries []validator.ManifestEntry
+	var header Header
+	if IsURL(location) {
+		var err error
+		if entries, header, err = fetch(location, dialect); err != nil {
+			return nil, Header{}, err
+		}
+		if base == "" {
+			base = "."
+		}
+	} else {
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, Header{}, err
+		}
+		defer file.Close()
+		if entries, header, err = dialect.parse(file); err != nil {
+			return nil, Header{}, fmt.Errorf("%s: %w", location, err)
+		}
+		if base == "" {
+			base = filepath.Dir(location)
+		}
+	}
+
+	for i, entry := range entries {
+		entries[i].Path = resolve(base, entry.Path)
+	}
+	return entries, header, nil
+}
+
+func resolve(base, path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+var httpClient = &http.Client{
+	Timeout: time.Minute,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	},
+}
+
+func fetch(url string, dialect Dialect) ([]validator.ManifestEntry, Header, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, Header{}, fmt.Errorf("fetching manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, Header{}, fmt.Errorf("fetching manifest %s: %s", url, resp.Status)
+	}
+	entries, header, err := dialect.parse(resp.Body)
+	if err != nil {
+		return nil, Header{}, fmt.Errorf("%s: %w", url, err)
+	}
+	return entries, header, nil
+}
+
+// Format returns the manifest line for the entry, without a trailing newline.
+// The size is included when it is known.
+func Format(entry validator.ManifestEntry) string {
+	if entry.HasSize {
+		return fmt.Sprintf("%s %d %s", entry.Hash, entry.Size, entry.Path)
+	}
+	return entry.Hash + "  " + entry.Path
+}
+
+// Write writes the entries as manifest lines.
+func Write(w io.Writer, entries []validator.ManifestEntry) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range entries {
+		if _, err := fmt.Fprintln(bw, Format(entry)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Tidy sorts the entries by path and removes duplicate paths, keeping the
+// entry that comes last.
+func Tidy(entries []validator.ManifestEntry) []validator.ManifestEntry {
+	last := make(map[string]validator.ManifestEntry, len(entries))
+	for _, entry := range entries {
+		last[entry.Path] = entry
+	}
+	tidy := make([]validator.ManifestEntry, 0, len(last))
+	for _, entry := range last {
+		tidy = append(tidy, entry)
+	}
+	sort.Slice(tidy, func(i, j int) bool { return tidy[i].Path < tidy[j].Path })
+	return tidy
+}
+
+// ParseChunks reads a chunk manifest with lines of the form
+// "<offset>,<length>,<hash>", giving the expected hash of each byte range of a
+// file. Blank lines and lines starting with # are skipped.
+func ParseChunks(r io.Reader) ([]validator.Chunk, error) {
+	var chunks []validator.Chunk
+	err := scanChunks(r, 3, "<offset>,<length>,<hash>", func(chunk validator.Chunk, _ string) {
+		chunks = append(chunks, chunk)
+	})
+	return chunks, err
+}
+
+// ParsePackIndex reads the index of a store of content-defined chunks kept in
+// pack files, with lines of the form "<offset>,<length>,<hash>,<pack>" giving
+// the expected hash of each chunk and the pack it is stored in, and returns
+// the chunks of every pack. Relative pack paths are resolved against base.
+// Blank lines and lines starting with # are skipped.
+func ParsePackIndex(r io.Reader, base string) (map[string][]validator.Chunk, error) {
+	packs := make(map[string][]validator.Chunk)
+	err := scanChunks(r, 4, "<offset>,<length>,<hash>,<pack>", func(chunk validator.Chunk, pack string) {
+		pack = resolve(base, pack)
+		packs[pack] = append(packs[pack], chunk)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+// scanChunks calls add with every chunk of r, whose lines have n
+// comma-separated fields in the given format: the range and hash of the
+// chunk, followed by a path when n is 4. The path may contain commas.
+func scanChunks(r io.Reader, n int, format string, add func(chunk validator.Chunk, path string)) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ",", n)
+		if len(fields) != n || (n == 4 && strings.TrimSpace(fields[3]) == "") {
+			return fmt.Errorf("line %d: expected %q", lineNo, format)
+		}
+		offset, err := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 64)
+		if err != nil || offset < 0 {
+			return fmt.Errorf("line %d: invalid offset %q", lineNo, fields[0])
+		}
+		length, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64)
+		if err != nil || length < 0 {
+			return fmt.Errorf("line %d: invalid length %q", lineNo, fields[1])
+		}
+		hash := strings.ToLower(strings.TrimSpace(fields[2]))
+		if !validator.IsValidSha256(hash) {
+			return fmt.Errorf("line %d: invalid SHA256 hash %q", lineNo, fields[2])
+		}
+		var path string
+		if n == 4 {
+			path = strings.TrimSpace(fields[3])
+		}
+		add(validator.Chunk{Offset: offset, Length: length, Hash: hash}, path)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,248 @@
+package manifest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+const testManifest = `# generated by sha256sum
+6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72  data/a
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *empty
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  /srv/absolute
+6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72 12 sized name
+
+`
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(entries))
+	}
+	if entries[0].Path != "data/a" || entries[1].Path != "empty" {
+		t.Errorf("Unexpected paths %q and %q", entries[0].Path, entries[1].Path)
+	}
+	if entries[0].HasSize || !entries[3].HasSize || entries[3].Size != 12 || entries[3].Path != "sized name" {
+		t.Errorf("Unexpected sizes in %v", entries)
+	}
+	if got := Format(entries[3]); got != "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72 12 sized name" {
+		t.Errorf("Unexpected line for a sized entry: %q", got)
+	}
+
+	if _, err := Parse(strings.NewReader("abc x y\n")); err == nil {
+		t.Error("Expected an error for a size that is not a number")
+	}
+	if _, err := Parse(strings.NewReader("nohash\n")); err == nil {
+		t.Error("Expected an error for a line without path")
+	}
+}
+
+// messyManifest has the quirks of manifests written by Windows tools and
+// other utilities: a byte order mark, CRLF line endings, upper-case hashes,
+// tabs and runs of spaces, binary markers and trailing whitespace.
+const messyManifest = "\ufeff# written on Windows\r\n" +
+	"6AE8A75555209FD6C44157C0AED8016E763FF435A19CF186F76863140143FF72 *data/a\r\n" +
+	"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855\tempty \r\n" +
+	"\r\n" +
+	"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855    *spaced name\t\r\n" +
+	"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 0 sized\r\n" +
+	"SHA256 (tagged)  =  6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72\r\n"
+
+func TestParseMessy(t *testing.T) {
+	entries, err := DialectAuto.Parse(strings.NewReader(messyManifest))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []validator.ManifestEntry{
+		{Path: "data/a", Hash: "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"},
+		{Path: "empty", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Path: "spaced name", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Path: "sized", Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HasSize: true},
+		{Path: "tagged", Hash: "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %v", len(want), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want[i], entries[i])
+		}
+	}
+
+	if _, err := DialectGNU.Parse(strings.NewReader("\ufeff" + testManifest)); err != nil {
+		t.Errorf("Expected a byte order mark to be skipped with gnu, got %v", err)
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	const hash = "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	const bsd = "SHA256 (data/a) = " + hash + "\n" +
+		"SHA256 (name (1).txt) = " + hash + "\n" +
+		"SHA2-256(openssl)= 6AE8A75555209FD6C44157C0AED8016E763FF435A19CF186F76863140143FF72\n"
+
+	entries, err := DialectBSD.Parse(strings.NewReader(bsd))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Path != "data/a" || entries[1].Path != "name (1).txt" || entries[2].Path != "openssl" {
+		t.Fatalf("Unexpected entries %v", entries)
+	}
+	if entries[2].Hash != hash {
+		t.Errorf("Expected the hash to be lower-cased, got %s", entries[2].Hash)
+	}
+
+	entries, err = DialectAuto.Parse(strings.NewReader(testManifest + bsd))
+	if err != nil {
+		t.Fatalf("Parse with auto failed: %v", err)
+	}
+	if len(entries) != 7 || entries[0].Path != "data/a" || entries[3].Path != "sized name" || entries[6].Path != "openssl" {
+		t.Errorf("Unexpected entries for a mixed manifest %v", entries)
+	}
+
+	if _, err := DialectBSD.Parse(strings.NewReader(testManifest)); err == nil {
+		t.Error("Expected an error for sha256sum lines with bsd")
+	}
+	if _, err := DialectGNU.Parse(strings.NewReader(bsd)); err == nil {
+		t.Error("Expected an error for tagged lines with gnu")
+	}
+	if _, err := DialectAuto.Parse(strings.NewReader("MD5 (a) = d41d8cd98f00b204e9800998ecf8427e\n")); !errors.Is(err, validator.ErrUnsupportedAlgo) || !strings.Contains(err.Error(), "MD5") {
+		t.Errorf("Expected an error for an MD5 line, got %v", err)
+	}
+	if _, err := ParseDialect("sfv"); err == nil {
+		t.Error("Expected an error for an unknown dialect")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	location := filepath.Join(dir, "SHA256SUMS")
+	if err := os.WriteFile(location, []byte(testManifest), 0o644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	entries, err := Load(location, "", DialectGNU)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := filepath.Join(dir, "data", "a"); entries[0].Path != want {
+		t.Errorf("Expected path relative to the manifest %s, got %s", want, entries[0].Path)
+	}
+
+	base := t.TempDir()
+	entries, err = Load(location, base, DialectGNU)
+	if err != nil {
+		t.Fatalf("Load with a base failed: %v", err)
+	}
+	if want := filepath.Join(base, "data", "a"); entries[0].Path != want {
+		t.Errorf("Expected path relative to the base %s, got %s", want, entries[0].Path)
+	}
+	if want := filepath.FromSlash("/srv/absolute"); runtime.GOOS != "windows" && entries[2].Path != want {
+		t.Errorf("Expected the absolute path %s to be kept, got %s", want, entries[2].Path)
+	}
+}
+
+func TestLoadURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testManifest))
+	})
+	mux.Handle("/latest", http.RedirectHandler("/SHA256SUMS", http.StatusFound))
+	mux.Handle("/loop", http.RedirectHandler("/loop", http.StatusFound))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	entries, err := Load(server.URL+"/latest", "", DialectAuto)
+	if err != nil {
+		t.Fatalf("Load through a redirect failed: %v", err)
+	}
+	if len(entries) != 4 || entries[0].Path != filepath.Join("data", "a") {
+		t.Errorf("Unexpected entries %v", entries)
+	}
+
+	if _, err := Load(server.URL+"/missing", "", DialectGNU); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+	if _, err := Load(server.URL+"/loop", "", DialectGNU); err == nil || !strings.Contains(err.Error(), "redirects") {
+		t.Errorf("Expected a redirect loop error, got %v", err)
+	}
+}
+
+func TestLoadHeader(t *testing.T) {
+	dir := t.TempDir()
+	location := filepath.Join(dir, "SHA256SUMS")
+	if err := os.WriteFile(location, []byte("# total-bytes: 24\n"+testManifest), 0o644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	for _, dialect := range []Dialect{DialectGNU, DialectAuto} {
+		entries, header, err := LoadHeader(location, "", dialect)
+		if err != nil {
+			t.Fatalf("LoadHeader with %s failed: %v", dialect, err)
+		}
+		if len(entries) != 4 || !header.HasTotal || header.TotalBytes != 24 {
+			t.Errorf("Unexpected header %+v with %s", header, dialect)
+		}
+	}
+
+	if _, err := Parse(strings.NewReader("# total-bytes: many\n")); err == nil {
+		t.Error("Expected an error for a total size that is not a number")
+	}
+	if _, err := DialectBSD.Parse(strings.NewReader("# total-bytes: -1\n")); err == nil {
+		t.Error("Expected an error for a negative total size")
+	}
+}
+
+func TestTidy(t *testing.T) {
+	entries := Tidy([]validator.ManifestEntry{
+		{Path: "b", Hash: "1"},
+		{Path: "a", Hash: "2"},
+		{Path: "b", Hash: "3"},
+	})
+	if len(entries) != 2 || entries[0].Path != "a" || entries[1].Hash != "3" {
+		t.Errorf("Expected entries sorted by path keeping the last duplicate, got %v", entries)
+	}
+}
+
+func TestParseChunks(t *testing.T) {
+	const hash = "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	chunks, err := ParseChunks(strings.NewReader("# pieces\n0,4," + hash + "\n\n4, 4, " + strings.ToUpper(hash) + "\n"))
+	if err != nil {
+		t.Fatalf("ParseChunks failed: %v", err)
+	}
+	if len(chunks) != 2 || chunks[1].Offset != 4 || chunks[1].Length != 4 || chunks[1].Hash != hash {
+		t.Errorf("Unexpected chunks %v", chunks)
+	}
+
+	for _, line := range []string{"0,4", "x,4," + hash, "0,-1," + hash, "0,4,nothash"} {
+		if _, err := ParseChunks(strings.NewReader(line)); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestParsePackIndex(t *testing.T) {
+	const hash = "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	packs, err := ParsePackIndex(strings.NewReader("# chunks\n0,4,"+hash+",data/00/pack,1\n4,4,"+hash+",data/00/pack,1\n0,8,"+hash+",/srv/pack\n"), "repo")
+	if err != nil {
+		t.Fatalf("ParsePackIndex failed: %v", err)
+	}
+	if chunks := packs[filepath.Join("repo", "data", "00", "pack,1")]; len(packs) != 2 || len(chunks) != 2 || chunks[1].Offset != 4 {
+		t.Errorf("Unexpected packs %v", packs)
+	}
+
+	for _, line := range []string{"0,4," + hash, "0,4," + hash + ", ", "0,4,nothash,pack"} {
+		if _, err := ParsePackIndex(strings.NewReader(line), ""); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package resultdb writes the files of a run to a SQLite database, so that
+// the results of a large store can be queried with SQL.
+//
+// The database holds a table
+//
+//	CREATE TABLE files (
+//		path TEXT NOT NULL,
+//		status TEXT NOT NULL,
+//		expected_hash TEXT,
+//		actual_hash TEXT,
+//		size INTEGER NOT NULL,
+//		mod_time TEXT NOT NULL,
+//		content_type TEXT
+//	)
+//
+// where mod_time is in UTC in the "YYYY-MM-DD HH:MM:SS" format of the SQLite
+// date functions, and the hashes are NULL when unknown or not computed.
+package resultdb
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	_ "modernc.org/sqlite"
+)
+
+// batchSize is the number of rows written in a single transaction, and the
+// number of rows waiting to be written before Add blocks.
+const batchSize = 1000
+
+// timeFormat is the format of mod_time, which the SQLite date functions
+// compare with, as in mod_time > datetime('now', '-7 days').
+const timeFormat = "2006-01-02 15:04:05"
+
+const schema = `DROP TABLE IF EXISTS files;
+CREATE TABLE files (
+	path TEXT NOT NULL,
+	status TEXT NOT NULL,
+	expected_hash TEXT,
+	actual_hash TEXT,
+	size INTEGER NOT NULL,
+	mod_time TEXT NOT NULL,
+	content_type TEXT
+);
+CREATE INDEX files_status ON files (status);`
+
+type row struct {
+	record       validator.FileRecord
+	expectedHash string
+	actualHash   string
+}
+
+// Writer writes rows to the files table in batches on a separate goroutine,
+// so that adding a row costs no more than a channel send. It is safe for
+// concurrent use.
+type Writer struct {
+	path string
+	db   *sql.DB
+	rows chan row
+	done chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// Create opens the database at path, creating it if needed, and replaces
+// its files table with an empty one. Other tables are left alone.
+func Create(path string) (*Writer, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating table in %s: %w", path, err)
+	}
+	w := &Writer{path: path, db: db, rows: make(chan row, batchSize), done: make(chan struct{})}
+	go w.run()
+	return w, nil
+}
+
+// Add queues a row for the file, with the expected hash, or "" when it is
+// unknown, and the actual hash, or "-" when the file was not hashed. Its
+// signature matches validator.Options.Checked.
+func (w *Writer) Add(record validator.FileRecord, expectedHash, actualHash string) {
+	w.rows <- row{record: record, expectedHash: expectedHash, actualHash: actualHash}
+}
+
+// Close writes the queued rows, closes the database and returns the first
+// error writing to it.
+func (w *Writer) Close() error {
+	close(w.rows)
+	<-w.done
+	if err := w.db.Close(); err != nil {
+		w.setErr(err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	batch := make([]row, 0, batchSize)
+	for r := range w.rows {
+		batch = append(batch, r)
+		// Whatever has queued up meanwhile goes into the same transaction.
+		for len(batch) < batchSize && len(w.rows) > 0 {
+			batch = append(batch, <-w.rows)
+		}
+		if err := w.write(batch); err != nil {
+			w.setErr(fmt.Errorf("writing to %s: %w", w.path, err))
+		}
+		batch = batch[:0]
+	}
+}
+
+func (w *Writer) write(batch []row) error {
+	if w.failed() {
+		return nil
+	}
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT INTO files VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range batch {
+		_, err := stmt.Exec(r.record.FilePath, r.record.Status, nullable(r.expectedHash), nullable(r.actualHash),
+			r.record.Size, r.record.ModTime.UTC().Format(timeFormat), nullable(r.record.ContentType))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// nullable returns nil for an unknown or uncomputed value, which is stored as NULL.
+func nullable(s string) interface{} {
+	if s == "" || s == "-" {
+		return nil
+	}
+	return s
+}
+
+func (w *Writer) setErr(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *Writer) failed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
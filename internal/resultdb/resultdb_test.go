@@ -0,0 +1,61 @@
+package resultdb
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	// A second run replaces the rows of the first.
+	for run := 0; run < 2; run++ {
+		w, err := Create(path)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		for i := 0; i < 2*batchSize+1; i++ {
+			w.Add(validator.FileRecord{FilePath: fmt.Sprintf("store/%d", i), Size: int64(i), ModTime: modTime, Status: validator.StatusIntact}, "aa", "aa")
+		}
+		w.Add(validator.FileRecord{FilePath: "store/bad", Size: 1 << 30, ModTime: modTime, Status: validator.StatusCorrupted, ContentType: "text/plain"}, "aa", "bb")
+		w.Add(validator.FileRecord{FilePath: "store/name", ModTime: modTime, Status: validator.StatusInvalid}, "", "-")
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM files").Scan(&count); err != nil {
+		t.Fatalf("Failed to count rows: %v", err)
+	}
+	if count != 2*batchSize+3 {
+		t.Errorf("Expected %d rows, got %d", 2*batchSize+3, count)
+	}
+
+	var filePath, contentType, mtime string
+	err = db.QueryRow("SELECT path, content_type, mod_time FROM files WHERE status = 'corrupted' AND size >= 1 << 30 AND mod_time < datetime('2024-05-02')").Scan(&filePath, &contentType, &mtime)
+	if err != nil {
+		t.Fatalf("Failed to query the corrupted file: %v", err)
+	}
+	if filePath != "store/bad" || contentType != "text/plain" || mtime != "2024-05-01 10:00:00" {
+		t.Errorf("Expected store/bad of type text/plain modified at 2024-05-01 10:00:00, got %s, %s and %s", filePath, contentType, mtime)
+	}
+
+	var expected, actual sql.NullString
+	if err := db.QueryRow("SELECT expected_hash, actual_hash FROM files WHERE path = 'store/name'").Scan(&expected, &actual); err != nil {
+		t.Fatalf("Failed to query the invalid file: %v", err)
+	}
+	if expected.Valid || actual.Valid {
+		t.Errorf("Expected NULL hashes for the invalid file, got %v and %v", expected, actual)
+	}
+}
@@ -0,0 +1,144 @@
+// Package rpc serves verifications over gRPC, streaming the findings of a run
+// as the workers find them, for platforms that integrate verifydata as a
+// service.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative verifydata.proto
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Service is the Verifier service, checking the folders of every request
+// with the options it was created with.
+type Service struct {
+	UnimplementedVerifierServer
+	opts validator.Options
+}
+
+// NewService returns a service checking folders with the options, which the
+// options of a request are added to.
+func NewService(opts validator.Options) *Service {
+	return &Service{opts: opts}
+}
+
+// Verify checks the folders of the request one after the other, streaming
+// their findings and then their summary. The run stops when the client goes
+// away.
+func (s *Service) Verify(req *VerifyRequest, stream grpc.ServerStreamingServer[VerifyResponse]) error {
+	if len(req.Paths) == 0 {
+		return status.Error(codes.InvalidArgument, "no paths to verify")
+	}
+	opts, err := s.requestOptions(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts.Context = stream.Context()
+
+	for _, folderPath := range req.Paths {
+		findings := &findingStream{folderPath: folderPath, stream: stream}
+		opts.Lists = findings
+		result, err := validator.ProcessFolder(folderPath, opts)
+		if err != nil {
+			return status.Errorf(codes.FailedPrecondition, "verifying %s: %v", folderPath, err)
+		}
+		// Missing files come in bulk once the walk is done and are always
+		// kept in the result.
+		for _, path := range result.MissingFileList {
+			findings.Add("missing_file_list", path)
+		}
+		if err := findings.err; err != nil {
+			return err
+		}
+		if err := stream.Send(&VerifyResponse{Event: &VerifyResponse_Summary{Summary: summary(result)}}); err != nil {
+			return err
+		}
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+	}
+	return nil
+}
+
+// requestOptions returns the options of the service with those of the
+// request added.
+func (s *Service) requestOptions(req *VerifyRequest) (validator.Options, error) {
+	opts := s.opts
+	if req.Workers < 0 {
+		return opts, fmt.Errorf("workers must not be negative, got %d", req.Workers)
+	}
+	if req.Workers > 0 {
+		opts.Workers = int(req.Workers)
+	}
+	if len(req.Exclude) > 0 {
+		patterns := req.Exclude
+		if opts.Exclude != nil {
+			patterns = append([]string{opts.Exclude.String()}, patterns...)
+		}
+		exclude, err := validator.CompileExcludes(patterns)
+		if err != nil {
+			return opts, err
+		}
+		opts.Exclude = exclude
+	}
+	opts.RecordFiles = opts.RecordFiles || req.RecordFiles
+	return opts, nil
+}
+
+// findingStream sends the entries of the lists of a result as findings. It
+// is safe for concurrent use, and keeps the first error sending a finding.
+type findingStream struct {
+	folderPath string
+	mu         sync.Mutex
+	stream     grpc.ServerStreamingServer[VerifyResponse]
+	err        error
+}
+
+func (f *findingStream) Add(list string, entry interface{}) {
+	finding := &Finding{FolderPath: f.folderPath, List: list}
+	switch entry := entry.(type) {
+	case string:
+		finding.FilePath = entry
+	case validator.CorruptedFile:
+		finding.FilePath, finding.Size, finding.ExpectedHash, finding.ActualHash, finding.Reason = entry.FilePath, entry.Size, entry.ExpectedHash, entry.ActualHash, entry.Reason
+	case validator.ErroredFile:
+		finding.FilePath, finding.Error = entry.FilePath, entry.Error
+	case validator.AlgorithmMismatch:
+		finding.FilePath, finding.Algorithm = entry.FilePath, entry.Algorithm
+	case validator.HardLink:
+		finding.FilePath, finding.Original = entry.FilePath, entry.Original
+	case validator.FileRecord:
+		finding.FilePath, finding.Size, finding.Status = entry.FilePath, entry.Size, entry.Status
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err == nil {
+		f.err = f.stream.Send(&VerifyResponse{Event: &VerifyResponse_Finding{Finding: finding}})
+	}
+}
+
+func summary(result *validator.Result) *Summary {
+	return &Summary{
+		FolderPath:          result.FolderPath,
+		TotalFiles:          int64(result.TotalFiles),
+		IntactFiles:         int64(result.IntactFiles),
+		CorruptedFiles:      int64(result.CorruptedFiles),
+		InvalidFiles:        int64(result.InvalidFiles),
+		MissingFiles:        int64(result.MissingFiles),
+		IgnoredFiles:        int64(result.IgnoredFiles),
+		ErroredFiles:        int64(result.ErroredFiles),
+		DecryptErrors:       int64(result.DecryptErrors),
+		PathErrors:          int64(result.PathErrors),
+		AlgorithmMismatches: int64(result.AlgorithmMismatches),
+		IntactBytes:         result.IntactBytes,
+		CorruptedBytes:      result.CorruptedBytes,
+		StoppedEarly:        result.StoppedEarly,
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	for _, name := range []string{hash, strings.Repeat("0", 64), "data.bin", "skipped.bin"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterVerifierServer(server, NewService(validator.Options{Workers: 2}))
+	go server.Serve(listener)
+	defer server.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer conn.Close()
+	client := NewVerifierClient(conn)
+
+	stream, err := client.Verify(context.Background(), &VerifyRequest{Paths: []string{dir}, Exclude: []string{`skipped\.bin$`}})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	lists := make(map[string][]string)
+	var summaries []*Summary
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if finding := resp.GetFinding(); finding != nil {
+			lists[finding.List] = append(lists[finding.List], filepath.Base(finding.FilePath))
+			if finding.List == "corrupted_file_list" && (finding.ActualHash != hash || finding.Size != 12) {
+				t.Errorf("Expected the actual hash and size of the corrupted file, got %v", finding)
+			}
+		}
+		if s := resp.GetSummary(); s != nil {
+			summaries = append(summaries, s)
+		}
+	}
+	if len(lists["corrupted_file_list"]) != 1 || len(lists["invalid_file_list"]) != 1 || lists["invalid_file_list"][0] != "data.bin" {
+		t.Errorf("Expected 1 corrupted file and data.bin to be invalid, got %v", lists)
+	}
+	if len(summaries) != 1 || summaries[0].TotalFiles != 3 || summaries[0].IntactFiles != 1 {
+		t.Errorf("Expected a summary of 3 files with 1 intact, got %v", summaries)
+	}
+
+	stream, err = client.Verify(context.Background(), &VerifyRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected a request without paths to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+// Package schema provides the JSON Schema describing the JSON output of verifydata.
+package schema
+
+import _ "embed"
+
+// Result is the JSON Schema of the array of validator.Result values printed with --json.
+//
+//go:embed result.schema.json
+var Result []byte
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// TestSchemaMatchesResult derives a schema from the json tags of validator.Result
+// and compares it, ignoring descriptions, with the hand-maintained schema.
+func TestSchemaMatchesResult(t *testing.T) {
+	defs := map[string]any{}
+	want := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "verifydata results",
+		"type":    "array",
+		"items":   typeSchema(reflect.TypeOf(validator.Result{}), defs),
+		"$defs":   defs,
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Failed to marshal derived schema: %v", err)
+	}
+	var derived any
+	if err := json.Unmarshal(wantJSON, &derived); err != nil {
+		t.Fatalf("Failed to unmarshal derived schema: %v", err)
+	}
+
+	var got any
+	if err := json.Unmarshal(Result, &got); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	stripDescriptions(got)
+
+	if !reflect.DeepEqual(got, derived) {
+		indented, _ := json.MarshalIndent(derived, "", "  ")
+		t.Errorf("Schema does not match validator.Result, expected (without descriptions):\n%s", indented)
+	}
+}
+
+func typeSchema(typ reflect.Type, defs map[string]any) any {
+	if typ == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": []string{"array", "null"}, "items": typeSchema(typ.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": typeSchema(typ.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs[typ.Name()]; !ok {
+			defs[typ.Name()] = nil
+			defs[typ.Name()] = structSchema(typ, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + typ.Name()}
+	}
+	panic("unsupported type " + typ.String())
+}
+
+func structSchema(typ reflect.Type, defs map[string]any) any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		prop := typeSchema(field.Type, defs)
+		if options == "omitempty" {
+			// Empty values are omitted rather than encoded as null.
+			if m, ok := prop.(map[string]any); ok {
+				if types, ok := m["type"].([]string); ok {
+					m["type"] = types[0]
+				}
+			}
+		} else {
+			required = append(required, name)
+		}
+		properties[name] = prop
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func stripDescriptions(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		delete(v, "description")
+		for _, child := range v {
+			stripDescriptions(child)
+		}
+	case []any:
+		for _, child := range v {
+			stripDescriptions(child)
+		}
+	}
+}
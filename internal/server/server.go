@@ -0,0 +1,188 @@
+// Package server checks files submitted over a connection, so that other
+// processes can use verifydata as a verification service.
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// Request is a file to check, sent as a JSON object on a line of its own.
+// A line that is not a JSON object is taken as the path of the file.
+type Request struct {
+	// ID is echoed in the response, to match responses to requests.
+	ID   json.RawMessage `json:"id,omitempty"`
+	Path string          `json:"path"`
+	// ExpectedHash is checked instead of the hash from the name of the file
+	// when it is set.
+	ExpectedHash string `json:"expected_hash,omitempty"`
+}
+
+// Response is the outcome of a request, written as a JSON object on a line
+// of its own.
+type Response struct {
+	ID json.RawMessage `json:"id,omitempty"`
+	*validator.PathCheck
+}
+
+// Server checks the files requested over its connections with at most
+// opts.Workers files checked at a time over all connections. The responses
+// of a connection are written as the checks complete, not in the order of
+// the requests.
+type Server struct {
+	opts  validator.Options
+	slots chan struct{}
+
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	stopping bool
+}
+
+// New returns a server checking files with the options.
+func New(opts validator.Options) *Server {
+	return &Server{opts: opts, slots: make(chan struct{}, max(opts.Workers, 1)), conns: make(map[net.Conn]struct{})}
+}
+
+// Listen listens on the Unix socket at path. A socket left behind by a
+// server that did not shut down, as after a crash, is removed first, while
+// one that a server still listens on makes Listen fail.
+func Listen(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+		} else if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale socket: %w", err)
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// Serve accepts connections on the listener until it is closed, and returns
+// nil then, once the checks of the open connections are answered. Requests
+// that clients send after the listener is closed are not read.
+func (s *Server) Serve(listener net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			s.stop()
+			return nil
+		}
+		if err != nil {
+			s.stop()
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.untrack(conn)
+			s.handle(conn)
+		}()
+	}
+}
+
+// track adds the connection to those closed for reading by stop, and reports
+// false when the server is already stopping.
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+}
+
+// stop closes every open connection for reading, so that handle stops
+// waiting for requests and answers those already read.
+func (s *Server) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopping = true
+	for conn := range s.conns {
+		closeRead(conn)
+	}
+}
+
+// closeRead closes the reading side of the connection, or the whole
+// connection when it cannot be closed for reading alone.
+func closeRead(conn net.Conn) {
+	if c, ok := conn.(interface{ CloseRead() error }); ok {
+		c.CloseRead()
+		return
+	}
+	conn.Close()
+}
+
+// handle checks the files requested on the connection until the client
+// closes its side.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	var mu sync.Mutex
+	encoder := json.NewEncoder(conn)
+	var wg sync.WaitGroup
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		req, err := parseRequest(line)
+		if err != nil {
+			mu.Lock()
+			encoder.Encode(Response{PathCheck: &validator.PathCheck{Status: validator.StatusErrored, Error: err.Error()}})
+			mu.Unlock()
+			continue
+		}
+		s.slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check := validator.CheckPath(req.Path, req.ExpectedHash, s.opts)
+			<-s.slots
+			mu.Lock()
+			defer mu.Unlock()
+			if err := encoder.Encode(Response{ID: req.ID, PathCheck: check}); err != nil {
+				slog.Debug("writing response failed", "path", req.Path, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := scanner.Err(); err != nil {
+		slog.Debug("reading requests failed", "error", err)
+	}
+}
+
+func parseRequest(line string) (Request, error) {
+	if !strings.HasPrefix(line, "{") {
+		return Request{Path: line}, nil
+	}
+	var req Request
+	if err := json.Unmarshal([]byte(line), &req); err != nil {
+		return Request{}, fmt.Errorf("invalid request: %w", err)
+	}
+	if req.Path == "" {
+		return Request{}, errors.New("invalid request: missing path")
+	}
+	return req, nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	intact := filepath.Join(dir, hash)
+	corrupted := filepath.Join(dir, strings.Repeat("0", 64))
+	for _, path := range []string{intact, corrupted, filepath.Join(dir, "data.bin")} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "serve.sock"))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	served := make(chan error)
+	go func() { served <- New(validator.Options{Workers: 2}).Serve(listener) }()
+
+	conn, err := net.Dial("unix", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	requests := []string{
+		intact,
+		corrupted,
+		`{"id": 7, "path": "` + filepath.Join(dir, "data.bin") + `", "expected_hash": "` + strings.ToUpper(hash) + `"}`,
+		filepath.Join(dir, "missing"),
+		`{"path": `,
+	}
+	if _, err := conn.Write([]byte(strings.Join(requests, "\n") + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	conn.(*net.UnixConn).CloseWrite()
+
+	statuses := make(map[string]string)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var resp struct {
+			ID json.RawMessage `json:"id"`
+			validator.PathCheck
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid response %s: %v", scanner.Text(), err)
+		}
+		key := filepath.Base(resp.FilePath)
+		if resp.ID != nil {
+			key = string(resp.ID)
+		}
+		statuses[key] = resp.Status
+		if resp.Status == validator.StatusCorrupted && resp.ActualHash != hash {
+			t.Errorf("Expected the actual hash of a corrupted file, got %q", resp.ActualHash)
+		}
+	}
+	conn.Close()
+
+	want := map[string]string{
+		hash:                    validator.StatusIntact,
+		strings.Repeat("0", 64): validator.StatusCorrupted,
+		"7":                     validator.StatusIntact,
+		"missing":               validator.StatusMissing,
+		".":                     validator.StatusErrored,
+	}
+	for key, status := range want {
+		if statuses[key] != status {
+			t.Errorf("Expected %s to be %s, got %q", key, status, statuses[key])
+		}
+	}
+
+	listener.Close()
+	if err := <-served; err != nil {
+		t.Errorf("Serve failed: %v", err)
+	}
+}
+
+func TestServeStopsWithOpenConnections(t *testing.T) {
+	dir := t.TempDir()
+	listener, err := Listen(filepath.Join(dir, "serve.sock"))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	served := make(chan error)
+	go func() { served <- New(validator.Options{Workers: 2}).Serve(listener) }()
+
+	// The client sends a request and then keeps the connection open.
+	conn, err := net.Dial("unix", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(filepath.Join(dir, "missing") + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() {
+		t.Fatalf("Expected a response, got %v", scanner.Err())
+	}
+
+	listener.Close()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return while a client was connected")
+	}
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "serve.sock")
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	if _, err := Listen(socket); err == nil {
+		t.Error("Expected Listen to fail while another server listens on the socket")
+	}
+
+	// A crashed server leaves its socket behind.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	if _, err := os.Lstat(socket); err != nil {
+		t.Fatalf("Expected the socket to be left behind: %v", err)
+	}
+	listener, err = Listen(socket)
+	if err != nil {
+		t.Fatalf("Expected the stale socket to be replaced, got %v", err)
+	}
+	listener.Close()
+}
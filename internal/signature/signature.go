@@ -0,0 +1,108 @@
+// Package signature signs JSON reports with Ed25519 and verifies them, so
+// that a report can be shown not to have been altered since it was written.
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/konidev20/verifydata/internal/jcs"
+)
+
+// ErrInvalid is returned by Verify when the signature does not match.
+var ErrInvalid = errors.New("signature does not match the report")
+
+// ParsePrivateKey reads an Ed25519 private key as written by
+// "openssl genpkey -algorithm ed25519", in a PKCS #8 PEM block, or as the
+// 32 byte seed or 64 byte key, raw or hex encoded.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		private, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("expected an Ed25519 private key, got %T", key)
+		}
+		return private, nil
+	}
+	switch key := decodeKey(data); len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("expected an Ed25519 private key of %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(key))
+	}
+}
+
+// ParsePublicKey reads an Ed25519 public key in a PKIX PEM block, as written
+// by "openssl pkey -pubout", or as 32 bytes, raw or hex encoded.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		public, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("expected an Ed25519 public key, got %T", key)
+		}
+		return public, nil
+	}
+	key := decodeKey(data)
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("expected an Ed25519 public key of %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// decodeKey returns the key hex decoded when it is hex, and as it is otherwise.
+func decodeKey(data []byte) []byte {
+	if decoded, err := hex.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		return decoded
+	}
+	return data
+}
+
+// Sign returns the signature of the canonical JSON encoding of the report,
+// hex encoded on a line of its own. Signing the canonical encoding lets a
+// report that was reformatted, but not changed, still be verified.
+func Sign(key ed25519.PrivateKey, report []byte) ([]byte, error) {
+	message, err := canonical(report)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(ed25519.Sign(key, message)) + "\n"), nil
+}
+
+// Verify checks the signature written by Sign against the report, failing
+// with ErrInvalid when they do not match.
+func Verify(key ed25519.PublicKey, report, sig []byte) error {
+	decoded, err := hex.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil || len(decoded) != ed25519.SignatureSize {
+		return fmt.Errorf("expected a hex encoded signature of %d bytes", ed25519.SignatureSize)
+	}
+	message, err := canonical(report)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, message, decoded) {
+		return ErrInvalid
+	}
+	return nil
+}
+
+func canonical(report []byte) ([]byte, error) {
+	if !json.Valid(report) {
+		return nil, errors.New("report is not valid JSON")
+	}
+	return jcs.Marshal(json.RawMessage(report))
+}
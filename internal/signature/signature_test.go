@@ -0,0 +1,77 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	report := []byte(`[{"folder_path":"/data","corrupted_files":0}]` + "\n")
+	sig, err := Sign(private, report)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := Verify(public, report, sig); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+	reformatted := []byte("[\n  {\n    \"corrupted_files\": 0,\n    \"folder_path\": \"/data\"\n  }\n]")
+	if err := Verify(public, reformatted, sig); err != nil {
+		t.Errorf("Expected a reformatted report to verify, got %v", err)
+	}
+	altered := []byte(`[{"folder_path":"/data","corrupted_files":1}]`)
+	if err := Verify(public, altered, sig); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for an altered report, got %v", err)
+	}
+	if err := Verify(public, report, []byte("abc")); err == nil {
+		t.Error("Expected an error for a malformed signature")
+	}
+	if _, err := Sign(private, []byte("not json")); err == nil {
+		t.Error("Expected an error for a report that is not JSON")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey failed: %v", err)
+	}
+
+	for name, data := range map[string][]byte{
+		"pem":  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		"seed": []byte(hex.EncodeToString(private.Seed()) + "\n"),
+		"raw":  private,
+	} {
+		key, err := ParsePrivateKey(data)
+		if err != nil || !key.Equal(private) {
+			t.Errorf("ParsePrivateKey(%s) = %v, want the generated key", name, err)
+		}
+	}
+	for name, data := range map[string][]byte{
+		"pem": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		"hex": []byte(hex.EncodeToString(public)),
+	} {
+		key, err := ParsePublicKey(data)
+		if err != nil || !key.Equal(public) {
+			t.Errorf("ParsePublicKey(%s) = %v, want the generated key", name, err)
+		}
+	}
+	if _, err := ParsePublicKey([]byte("abcd")); err == nil {
+		t.Error("Expected an error for a short public key")
+	}
+}
@@ -0,0 +1,295 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxListingSize is the largest directory listing read, to not keep reading
+// a file that was served in place of a listing.
+const maxListingSize = 64 << 20
+
+// idleTimeout is how long a request may go without the server sending
+// anything, from the headers to the last byte of the body, before it fails.
+// A whole request has no time limit, since files can be large.
+var idleTimeout = time.Minute
+
+// hrefPattern matches the links of an HTML directory listing, such as those
+// of Apache's mod_autoindex or nginx's autoindex.
+var hrefPattern = regexp.MustCompile(`(?i)href\s*=\s*["']([^"'#]+)["']`)
+
+// HTTP walks and reads the files of a folder served over HTTP(S) with
+// directory listings. A listing is either an HTML page linking to the entries
+// of the directory, or a plain text index with the name of one entry per line,
+// where the names of directories end with a slash.
+type HTTP struct {
+	client *http.Client
+	base   *url.URL
+}
+
+// NewHTTP returns the source for an http(s)://host[:port]/path URL along with
+// the path of the folder on the server. Credentials in the URL are sent with
+// basic authentication, and left out of errors.
+func NewHTTP(rawURL string) (*HTTP, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The error of url.Parse quotes the URL, password included.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("missing host in %s URL", u.Scheme)
+	}
+	folderPath := path.Clean("/" + u.Path)
+	base := &url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}
+	return &HTTP{client: &http.Client{}, base: base}, folderPath, nil
+}
+
+// RedactURL returns the URL with the password of its credentials replaced,
+// for printing it.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// url returns the URL of the path on the server.
+func (h *HTTP) url(p string) *url.URL {
+	u := *h.base
+	u.Path = p
+	return &u
+}
+
+// get requests the path and fails for any status other than 200 OK, or when
+// the server sends nothing for idleTimeout.
+func (h *HTTP) get(method, p string) (*http.Response, error) {
+	u := h.url(p)
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := time.AfterFunc(idleTimeout, cancel)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		timer.Stop()
+		cancel()
+		return nil, fmt.Errorf("%s %s: invalid URL", method, u.Redacted())
+	}
+	// The client leaves the password out of its errors.
+	resp, err := h.client.Do(req)
+	if err != nil {
+		timer.Stop()
+		cancel()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s %s: no response for %s", method, u.Redacted(), idleTimeout)
+		}
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		timer.Stop()
+		cancel()
+		return nil, fmt.Errorf("%s %s: %s", method, u.Redacted(), resp.Status)
+	}
+	// Only the time spent waiting for the server counts from now on.
+	timer.Stop()
+	resp.Body = &idleBody{ReadCloser: resp.Body, timer: timer, cancel: cancel, url: u.Redacted()}
+	return resp, nil
+}
+
+// idleBody is the body of a response whose request is canceled once a read
+// has waited for the server for idleTimeout.
+type idleBody struct {
+	io.ReadCloser
+	timer  *time.Timer
+	cancel context.CancelFunc
+	url    string
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	b.timer.Reset(idleTimeout)
+	n, err := b.ReadCloser.Read(p)
+	if !b.timer.Stop() && err != nil && !errors.Is(err, io.EOF) {
+		return n, fmt.Errorf("reading %s: no data for %s", b.url, idleTimeout)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// Walk walks the tree of directory listings rooted at root, calling fn for
+// each file or directory in lexical order. The size and modification time of
+// every file are taken from a HEAD request. A listing that cannot be fetched
+// is passed to fn as an error.
+func (h *HTTP) Walk(root string, fn filepath.WalkFunc) error {
+	err := h.walk(path.Clean(root), &httpFileInfo{name: path.Base(root), dir: true}, fn)
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (h *HTTP) walk(p string, info *httpFileInfo, fn filepath.WalkFunc) error {
+	if !info.dir {
+		return fn(p, info, nil)
+	}
+	names, err := h.list(p)
+	if err != nil {
+		return fn(p, nil, err)
+	}
+	if err := fn(p, info, nil); err != nil {
+		return err
+	}
+	for _, name := range names {
+		child := path.Join(p, strings.TrimSuffix(name, "/"))
+		childInfo := &httpFileInfo{name: path.Base(child), dir: strings.HasSuffix(name, "/")}
+		// A file that cannot be stat'ed is still checked, so that it is
+		// reported as errored by Open instead of ending the walk, or
+		// checked with an unknown size when the server only fails HEAD.
+		if !childInfo.dir {
+			if err := h.stat(child, childInfo); err != nil {
+				slog.Warn("getting the size and modification time failed", "path", child, "error", err)
+			}
+		}
+		if err := h.walk(child, childInfo, fn); err != nil {
+			if err == filepath.SkipDir && childInfo.dir {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// list returns the entries of the directory listing at p, sorted, with a
+// trailing slash for directories.
+func (h *HTTP) list(p string) ([]string, error) {
+	dir := strings.TrimSuffix(p, "/") + "/"
+	resp, err := h.get(http.MethodGet, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var refs []string
+	body := io.LimitReader(resp.Body, maxListingSize)
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/plain" {
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				refs = append(refs, (&url.URL{Path: line}).EscapedPath())
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range hrefPattern.FindAllStringSubmatch(string(data), -1) {
+			refs = append(refs, match[1])
+		}
+	}
+
+	// The listing's URL after redirects is what relative links resolve against.
+	dirURL := *resp.Request.URL
+	dirURL.RawQuery = ""
+	seen := make(map[string]bool)
+	var names []string
+	for _, ref := range refs {
+		name, ok := childName(&dirURL, ref)
+		if ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// childName returns the name of the entry ref links to when it is directly
+// below the directory at dirURL, with a trailing slash for a directory. Links
+// to other hosts, to parent directories and with a query, such as the sort
+// links of Apache, are left out.
+func childName(dirURL *url.URL, ref string) (string, bool) {
+	u, err := dirURL.Parse(strings.TrimSpace(ref))
+	if err != nil || u.RawQuery != "" || u.Scheme != dirURL.Scheme || u.Host != dirURL.Host {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(u.Path, dirURL.Path)
+	if !ok || name == "" || name == "/" {
+		return "", false
+	}
+	if i := strings.Index(name, "/"); i >= 0 && i != len(name)-1 || name == "." || name == ".." {
+		return "", false
+	}
+	return name, true
+}
+
+// stat fills in the size and modification time of the file at p.
+func (h *HTTP) stat(p string, info *httpFileInfo) error {
+	resp, err := h.get(http.MethodHead, p)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	info.size = max(resp.ContentLength, 0)
+	info.modTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return nil
+}
+
+// Open requests the file at path, failing for any status other than 200 OK.
+func (h *HTTP) Open(path string) (io.ReadCloser, error) {
+	resp, err := h.get(http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// httpFileInfo describes an entry of a directory listing.
+type httpFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *httpFileInfo) Name() string       { return i.name }
+func (i *httpFileInfo) Size() int64        { return i.size }
+func (i *httpFileInfo) ModTime() time.Time { return i.modTime }
+func (i *httpFileInfo) IsDir() bool        { return i.dir }
+func (i *httpFileInfo) Sys() interface{}   { return nil }
+
+func (i *httpFileInfo) Mode() os.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
@@ -0,0 +1,167 @@
+// Package source implements validator.FileSource for folders on remote hosts.
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTP walks and reads the files of a folder over SFTP.
+type SFTP struct {
+	client *sftp.Client
+	conn   *ssh.Client
+	agent  net.Conn
+}
+
+// DialSFTP connects to the host of an sftp://[user@]host[:port]/path URL and
+// returns the source along with the remote path of the folder. Credentials are
+// taken from the SSH agent and the default key files in ~/.ssh, and the host
+// key is checked against ~/.ssh/known_hosts.
+func DialSFTP(rawURL string) (*SFTP, string, error) {
+	addr, username, folderPath, err := parseSFTPURL(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, "", err
+	}
+	hostKeyCallback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, "", fmt.Errorf("loading known hosts: %w", err)
+	}
+
+	auth, agentConn := authMethods(home)
+	config := &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}
+	conn, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		return nil, "", err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		return nil, "", err
+	}
+	return &SFTP{client: client, conn: conn, agent: agentConn}, folderPath, nil
+}
+
+func parseSFTPURL(rawURL string) (addr, username, folderPath string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Scheme != "sftp" {
+		return "", "", "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", "", "", errors.New("missing host in sftp URL")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+	username = u.User.Username()
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return "", "", "", err
+		}
+		username = current.Username
+	}
+	folderPath = u.Path
+	if folderPath == "" {
+		folderPath = "."
+	}
+	return net.JoinHostPort(u.Hostname(), port), username, folderPath, nil
+}
+
+// authMethods returns the SSH agent and key file authentication methods. The
+// returned agent connection, if any, must be closed once the SSH session ends.
+func authMethods(home string) ([]ssh.AuthMethod, net.Conn) {
+	var methods []ssh.AuthMethod
+	var agentConn net.Conn
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentConn = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		key, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+		if err != nil {
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods, agentConn
+}
+
+// Walk walks the remote file tree rooted at root, calling fn for each file or directory.
+func (s *SFTP) Walk(root string, fn filepath.WalkFunc) error {
+	walker := s.client.Walk(root)
+	for walker.Step() {
+		err := fn(walker.Path(), walker.Stat(), walker.Err())
+		if err == filepath.SkipDir {
+			walker.SkipDir()
+		} else if err == filepath.SkipAll {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open opens the remote file for reading.
+func (s *SFTP) Open(path string) (io.ReadCloser, error) {
+	return s.client.Open(path)
+}
+
+// Close closes the SFTP session, the underlying SSH connection and the SSH
+// agent connection. It returns the first error encountered.
+func (s *SFTP) Close() error {
+	err := s.client.Close()
+	if s.conn != nil {
+		if cerr := s.conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if s.agent != nil {
+		if cerr := s.agent.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/pkg/sftp"
+)
+
+func TestParseSFTPURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		addr       string
+		username   string
+		folderPath string
+	}{
+		{"sftp://alice@example.com/data/blobs", "example.com:22", "alice", "/data/blobs"},
+		{"sftp://bob@example.com:2222/srv", "example.com:2222", "bob", "/srv"},
+		{"sftp://carol@[::1]", "[::1]:22", "carol", "."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			addr, username, folderPath, err := parseSFTPURL(test.url)
+			if err != nil {
+				t.Fatalf("parseSFTPURL(%s) failed: %v", test.url, err)
+			}
+			if addr != test.addr || username != test.username || folderPath != test.folderPath {
+				t.Errorf("parseSFTPURL(%s) = %s, %s, %s, want %s, %s, %s", test.url, addr, username, folderPath, test.addr, test.username, test.folderPath)
+			}
+		})
+	}
+
+	for _, bad := range []string{"http://example.com/data", "sftp:///data"} {
+		if _, _, _, err := parseSFTPURL(bad); err == nil {
+			t.Errorf("parseSFTPURL(%s) succeeded, want error", bad)
+		}
+	}
+}
+
+// newPipeSFTP connects an SFTP client to an in-process server serving the local file system.
+func newPipeSFTP(t *testing.T) *SFTP {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter})
+	if err != nil {
+		t.Fatalf("Failed to create SFTP server: %v", err)
+	}
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	if err != nil {
+		t.Fatalf("Failed to create SFTP client: %v", err)
+	}
+	t.Cleanup(func() {
+		// Shut the server side down first so the client's receive loop sees EOF.
+		server.Close()
+		serverReader.Close()
+		serverWriter.Close()
+		client.Close()
+	})
+	return &SFTP{client: client}
+}
+
+func TestSFTPProcessFolder(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("test content")
+	sum := sha256.Sum256(content)
+	if err := os.WriteFile(filepath.Join(dir, hex.EncodeToString(sum[:])), content, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "not-a-hash"), content, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	opts := validator.Options{Exclude: regexp.MustCompile("^$"), Workers: 2, Source: newPipeSFTP(t)}
+	result, err := validator.ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 2 || result.IntactFiles != 1 || result.InvalidFiles != 1 {
+		t.Errorf("Expected 2 files with 1 intact and 1 invalid, got %d, %d and %d", result.TotalFiles, result.IntactFiles, result.InvalidFiles)
+	}
+}
@@ -0,0 +1,49 @@
+// Package storage guesses what kind of storage a path is on, to pick a number
+// of workers that suits it.
+package storage
+
+import "runtime"
+
+// Kind is the kind of storage a path is on.
+type Kind string
+
+const (
+	// Unknown is storage that could not be told apart.
+	Unknown Kind = "unknown"
+	// SSD is a local solid state disk, or memory.
+	SSD Kind = "ssd"
+	// HDD is a local spinning disk.
+	HDD Kind = "hdd"
+	// Network is a network file system, or a remote folder.
+	Network Kind = "network"
+)
+
+// DefaultWorkers is the number of workers for storage of an unknown kind,
+// the default of --workers.
+const DefaultWorkers = 4
+
+// networkWorkers is the number of workers for network storage, where every
+// read waits for a round trip rather than for the disk.
+const networkWorkers = 32
+
+// hddWorkers is the number of workers for a spinning disk, which slows down
+// when reads of more files at once make it seek between them.
+const hddWorkers = 2
+
+// Detect returns the kind of storage the local path is on.
+func Detect(path string) Kind {
+	return detect(path)
+}
+
+// Workers returns the number of workers suiting the kind of storage.
+func (k Kind) Workers() int {
+	switch k {
+	case SSD:
+		return runtime.NumCPU()
+	case HDD:
+		return hddWorkers
+	case Network:
+		return max(networkWorkers, runtime.NumCPU())
+	}
+	return DefaultWorkers
+}
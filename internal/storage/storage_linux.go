@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// networkFileSystems are the statfs magic numbers of network file systems.
+var networkFileSystems = map[int64]bool{
+	unix.NFS_SUPER_MAGIC:  true,
+	unix.SMB_SUPER_MAGIC:  true,
+	unix.SMB2_SUPER_MAGIC: true,
+	unix.CIFS_SUPER_MAGIC: true,
+	unix.CEPH_SUPER_MAGIC: true,
+	unix.AFS_SUPER_MAGIC:  true,
+	unix.V9FS_MAGIC:       true,
+	// FUSE file systems are mostly network ones, such as sshfs or s3fs.
+	unix.FUSE_SUPER_MAGIC: true,
+}
+
+// detect tells network file systems apart by statfs, and local disks by
+// whether the kernel reports the block device the path is on as rotational.
+func detect(path string) Kind {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return Unknown
+	}
+	if networkFileSystems[int64(fs.Type)] {
+		return Network
+	}
+	if fs.Type == unix.TMPFS_MAGIC {
+		return SSD
+	}
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return Unknown
+	}
+	return blockDeviceKind("/sys/dev/block", uint64(st.Dev))
+}
+
+// blockDeviceKind reads whether the block device is rotational from dir, the
+// /sys/dev/block directory. The queue of a partition is that of its disk.
+func blockDeviceKind(dir string, dev uint64) Kind {
+	device := filepath.Join(dir, fmt.Sprintf("%d:%d", unix.Major(dev), unix.Minor(dev)))
+	// The device is a symlink, so its parent is not found by cleaning the path.
+	for _, rotational := range []string{device + "/queue/rotational", device + "/../queue/rotational"} {
+		data, err := os.ReadFile(rotational)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(data)) {
+		case "0":
+			return SSD
+		case "1":
+			return HDD
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestBlockDeviceKind(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	// Disks and partitions are laid out as in /sys/block, and linked to from
+	// /sys/dev/block by their device numbers.
+	write(filepath.Join(dir, "block", "sda", "queue", "rotational"), "1\n")
+	write(filepath.Join(dir, "block", "sda", "sda1", "partition"), "1\n")
+	write(filepath.Join(dir, "block", "nvme0n1", "queue", "rotational"), "0\n")
+	links := filepath.Join(dir, "dev")
+	if err := os.Mkdir(links, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for name, target := range map[string]string{"8:0": "sda", "8:1": "sda/sda1", "259:0": "nvme0n1"} {
+		if err := os.Symlink(filepath.Join(dir, "block", target), filepath.Join(links, name)); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	for _, test := range []struct {
+		dev  uint64
+		want Kind
+	}{
+		{unix.Mkdev(8, 0), HDD},
+		{unix.Mkdev(8, 1), HDD},
+		{unix.Mkdev(259, 0), SSD},
+		{unix.Mkdev(0, 42), Unknown},
+	} {
+		if got := blockDeviceKind(links, test.dev); got != test.want {
+			t.Errorf("Expected %s for %d:%d, got %s", test.want, unix.Major(test.dev), unix.Minor(test.dev), got)
+		}
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	if Unknown.Workers() != DefaultWorkers || HDD.Workers() >= Network.Workers() {
+		t.Errorf("Unexpected workers %d for unknown, %d for hdd and %d for network", Unknown.Workers(), HDD.Workers(), Network.Workers())
+	}
+	if kind := Detect(t.TempDir()); kind == "" {
+		t.Error("Expected a kind for the temporary directory")
+	}
+}
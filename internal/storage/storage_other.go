@@ -0,0 +1,8 @@
+//go:build !linux
+
+package storage
+
+// detect cannot tell kinds of storage apart on platforms other than Linux.
+func detect(path string) Kind {
+	return Unknown
+}
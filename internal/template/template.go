@@ -1,5 +1,12 @@
 package template
 
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
 type Template struct {
 	Exclude []string
 }
@@ -20,3 +27,95 @@ func init() {
 		"darwin": macOSTemplate,
 	}
 }
+
+// Defaults returns the templates applied when none are selected: restic and,
+// if one is registered, the template of the given operating system.
+func Defaults(goos string) []string {
+	names := []string{"restic"}
+	if _, ok := Templates[goos]; ok {
+		names = append(names, goos)
+	}
+	return names
+}
+
+// Names returns the names of all registered templates in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(Templates))
+	for name := range Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Complete returns the completions of a comma-separated list of templates
+// being typed: the names of the templates, and "all", that start with the
+// last element, each following the elements before it. Templates already in
+// the list are left out.
+func Complete(toComplete string) []string {
+	var prefix, last string
+	listed := make(map[string]bool)
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix, last = toComplete[:i+1], toComplete[i+1:]
+		for _, name := range strings.Split(toComplete[:i], ",") {
+			listed[name] = true
+		}
+	} else {
+		last = toComplete
+	}
+	var completions []string
+	for _, name := range append(Names(), "all") {
+		if strings.HasPrefix(name, last) && !listed[name] {
+			completions = append(completions, prefix+name)
+		}
+	}
+	return completions
+}
+
+// Validate returns an error naming the first template that is not registered.
+func Validate(names []string) error {
+	for _, name := range names {
+		if _, ok := Templates[name]; !ok {
+			return fmt.Errorf("unknown template: %s (available: %s)", name, strings.Join(Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// Expand replaces "all" with every registered template and glob patterns such
+// as "os-*" with the templates whose names match, in sorted order. Other names,
+// and patterns that match nothing, are kept as they are so that Validate
+// reports them. Duplicates are removed.
+func Expand(names []string) []string {
+	var expanded []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			expanded = append(expanded, name)
+		}
+	}
+	for _, name := range names {
+		if name == "all" {
+			for _, n := range Names() {
+				add(n)
+			}
+			continue
+		}
+		if !strings.ContainsAny(name, "*?[") {
+			add(name)
+			continue
+		}
+		matched := false
+		for _, n := range Names() {
+			if ok, _ := path.Match(name, n); ok {
+				add(n)
+				matched = true
+			}
+		}
+		if !matched {
+			add(name)
+		}
+	}
+	return expanded
+}
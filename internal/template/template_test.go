@@ -0,0 +1,65 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{"restic", "darwin"}); err != nil {
+		t.Errorf("Validate of registered templates failed: %v", err)
+	}
+
+	err := Validate([]string{"restic", "foo"})
+	if err == nil {
+		t.Fatal("Expected an error for an unknown template")
+	}
+	want := "unknown template: foo (available: darwin, restic)"
+	if err.Error() != want {
+		t.Errorf("Validate error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	if got := strings.Join(Defaults("darwin"), ","); got != "restic,darwin" {
+		t.Errorf("Defaults(darwin) = %s, want restic,darwin", got)
+	}
+	if got := strings.Join(Defaults("plan9"), ","); got != "restic" {
+		t.Errorf("Defaults(plan9) = %s, want restic", got)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"all"}, "darwin,restic"},
+		{[]string{"res*"}, "restic"},
+		{[]string{"restic", "r?stic", "all"}, "restic,darwin"},
+		{[]string{"os-*"}, "os-*"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(Expand(tt.names), ","); got != tt.want {
+			t.Errorf("Expand(%v) = %s, want %s", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		toComplete string
+		want       string
+	}{
+		{"", "darwin restic all"},
+		{"r", "restic"},
+		{"restic,", "restic,darwin restic,all"},
+		{"restic,d", "restic,darwin"},
+		{"x", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(Complete(tt.toComplete), " "); got != tt.want {
+			t.Errorf("Complete(%q) = %s, want %s", tt.toComplete, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// Finding is a corrupted or invalid file as it is passed to a finding
+// template.
+type Finding struct {
+	// Kind is "corrupted" or "invalid". The CSV, SARIF and JUnit reports
+	// list the other kinds of files that are not intact too.
+	Kind         string
+	FilePath     string
+	Size         int64
+	ExpectedHash string
+	ActualHash   string
+	ContentType  string
+	Reason       string
+}
+
+// FindingTemplate writes every corrupted and invalid file through a
+// text/template as the workers report it. The other lists of files are
+// dropped. It is safe for concurrent use.
+type FindingTemplate struct {
+	mu   sync.Mutex
+	tmpl *template.Template
+	w    *bufio.Writer
+	err  error
+}
+
+// NewFindingTemplate parses the template, which is executed once per finding
+// and followed by a newline unless it ends with one. It is tried on an empty
+// finding, so that a misspelt field is reported before the run.
+func NewFindingTemplate(text string, w io.Writer) (*FindingTemplate, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	tmpl, err := template.New("finding").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, Finding{}); err != nil {
+		return nil, err
+	}
+	return &FindingTemplate{tmpl: tmpl, w: bufio.NewWriter(w)}, nil
+}
+
+// Add writes the entry when it is a corrupted or an invalid file.
+func (f *FindingTemplate) Add(list string, entry interface{}) {
+	var finding Finding
+	switch list {
+	case "corrupted_file_list":
+		file := entry.(validator.CorruptedFile)
+		finding = Finding{Kind: "corrupted", FilePath: file.FilePath, Size: file.Size, ExpectedHash: file.ExpectedHash,
+			ActualHash: file.ActualHash, ContentType: file.ContentType, Reason: file.Reason}
+	case "invalid_file_list":
+		finding = Finding{Kind: "invalid", FilePath: entry.(string)}
+	default:
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return
+	}
+	if f.err = f.tmpl.Execute(f.w, finding); f.err == nil {
+		// Flushing every finding lets a reader of the output follow along.
+		f.err = f.w.Flush()
+	}
+}
+
+// Err returns the first error executing the template or writing its output.
+func (f *FindingTemplate) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// Kinds of findings beyond "corrupted" and "invalid", which are only listed
+// in the CSV, SARIF and JUnit reports.
+const (
+	kindDecryptError      = "decrypt_error"
+	kindMissing           = "missing"
+	kindModified          = "modified"
+	kindErrored           = "errored"
+	kindPathError         = "path_error"
+	kindAlgorithmMismatch = "algorithm_mismatch"
+)
+
+// findings lists every file of the result that is not intact, in the order
+// of the table output.
+func findings(result *validator.Result) []Finding {
+	var list []Finding
+	for _, file := range result.CorruptedFileList {
+		list = append(list, Finding{Kind: "corrupted", FilePath: file.FilePath, Size: file.Size, ExpectedHash: file.ExpectedHash,
+			ActualHash: file.ActualHash, ContentType: file.ContentType, Reason: file.Reason})
+	}
+	for _, file := range result.DecryptErrorList {
+		list = append(list, Finding{Kind: kindDecryptError, FilePath: file.FilePath, Reason: file.Error})
+	}
+	for _, path := range result.MissingFileList {
+		list = append(list, Finding{Kind: kindMissing, FilePath: path})
+	}
+	for _, path := range result.ModifiedFileList {
+		list = append(list, Finding{Kind: kindModified, FilePath: path, Reason: "changed while it was hashed"})
+	}
+	for _, file := range result.ErroredFileList {
+		list = append(list, Finding{Kind: kindErrored, FilePath: file.FilePath, Reason: file.Error})
+	}
+	for _, file := range result.PathErrorList {
+		list = append(list, Finding{Kind: kindPathError, FilePath: file.FilePath, Reason: file.Error})
+	}
+	for _, file := range result.AlgorithmMismatchList {
+		list = append(list, Finding{Kind: kindAlgorithmMismatch, FilePath: file.FilePath, Reason: "named by a " + file.Algorithm + " hash"})
+	}
+	for _, path := range result.InvalidFileList {
+		list = append(list, Finding{Kind: "invalid", FilePath: path, Reason: "name is not a hash"})
+	}
+	return list
+}
+
+// message describes the finding in a sentence.
+func (f Finding) message() string {
+	switch f.Kind {
+	case "corrupted":
+		if f.Reason != "" {
+			return "Corrupted: " + f.Reason
+		}
+		return fmt.Sprintf("Corrupted: expected hash %s, actual hash %s", f.ExpectedHash, f.ActualHash)
+	case kindMissing:
+		return "Missing: listed but not found"
+	}
+	return fmt.Sprintf("%s: %s", kindTitles[f.Kind], f.Reason)
+}
+
+var kindTitles = map[string]string{
+	"corrupted":           "Corrupted",
+	"invalid":             "Invalid file name",
+	kindDecryptError:      "Decrypt error",
+	kindMissing:           "Missing",
+	kindModified:          "Modified",
+	kindErrored:           "Errored",
+	kindPathError:         "Path error",
+	kindAlgorithmMismatch: "Algorithm mismatch",
+}
+
+// csvHeader is the first line of the CSV report.
+var csvHeader = []string{"folder_path", "kind", "file_path", "size", "expected_hash", "actual_hash", "content_type", "reason"}
+
+// printCSV writes one line for every file that is not intact. The size is
+// only known for corrupted files and left empty for the others.
+func printCSV(w io.Writer, results []*validator.Result) {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, result := range results {
+		for _, f := range findings(result) {
+			size := ""
+			if f.Kind == "corrupted" {
+				size = strconv.FormatInt(f.Size, 10)
+			}
+			cw.Write([]string{result.FolderPath, f.Kind, f.FilePath, size, f.ExpectedHash, f.ActualHash, f.ContentType, f.Reason})
+		}
+	}
+	cw.Flush()
+}
+
+// sarifLevels are the SARIF levels of the kinds of findings: errors for
+// files that count as corrupted or missing for --fail-on, warnings for the
+// others.
+var sarifLevels = map[string]string{
+	"corrupted":           "error",
+	kindDecryptError:      "error",
+	kindMissing:           "error",
+	kindModified:          "error",
+	kindErrored:           "warning",
+	kindPathError:         "warning",
+	kindAlgorithmMismatch: "warning",
+	"invalid":             "warning",
+}
+
+// sarifKinds is the order of the rules of the SARIF report.
+var sarifKinds = []string{"corrupted", kindDecryptError, kindMissing, kindModified, kindErrored, kindPathError, kindAlgorithmMismatch, "invalid"}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+// printSARIF writes a SARIF 2.1.0 log with a run for every result and a
+// result for every file that is not intact, for code scanning dashboards.
+func printSARIF(w io.Writer, results []*validator.Result) {
+	log := sarifLog{Version: "2.1.0", Schema: "https://json.schemastore.org/sarif-2.1.0.json", Runs: []sarifRun{}}
+	for _, result := range results {
+		run := sarifRun{Results: []sarifResult{}}
+		run.Tool.Driver = sarifDriver{Name: "verifydata", Version: result.ToolVersion, InformationURI: "https://github.com/konidev20/verifydata"}
+		for _, kind := range sarifKinds {
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: kind, ShortDescription: sarifMessage{kindTitles[kind]}})
+		}
+		for _, f := range findings(result) {
+			r := sarifResult{RuleID: f.Kind, Level: sarifLevels[f.Kind], Message: sarifMessage{f.message()}}
+			var location sarifLocation
+			location.PhysicalLocation.ArtifactLocation.URI = (&url.URL{Path: filepath.ToSlash(f.FilePath)}).String()
+			r.Locations = []sarifLocation{location}
+			run.Results = append(run.Results, r)
+		}
+		log.Runs = append(log.Runs, run)
+	}
+	jsonData, _ := json.MarshalIndent(log, "", "  ")
+	fmt.Fprintln(w, string(jsonData))
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Type    string `xml:"type,attr,omitempty"`
+	Message string `xml:"message,attr"`
+}
+
+// printJUnit writes a JUnit XML report with a test suite for every result
+// and a test case for every file that is not intact, for CI systems. The
+// files that count as corrupted or missing for --fail-on fail, the files
+// that could not be checked are errors, and invalid file names are skipped. Intact files are
+// only listed as passing test cases when they were recorded with
+// Options.RecordFiles.
+func printJUnit(w io.Writer, results []*validator.Result) {
+	var suites junitSuites
+	for _, result := range results {
+		suite := junitSuite{Name: result.FolderPath}
+		for _, file := range result.Files {
+			if file.Status == validator.StatusIntact {
+				suite.Cases = append(suite.Cases, junitCase{Name: file.FilePath, ClassName: result.FolderPath})
+			}
+		}
+		for _, f := range findings(result) {
+			c := junitCase{Name: f.FilePath, ClassName: result.FolderPath}
+			problem := &junitProblem{Type: f.Kind, Message: f.message()}
+			switch sarifLevels[f.Kind] {
+			case "error":
+				c.Failure = problem
+				suite.Failures++
+			default:
+				if f.Kind == "invalid" {
+					c.Skipped = problem
+					suite.Skipped++
+				} else {
+					c.Error = problem
+					suite.Errors++
+				}
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+	xmlData, _ := xml.MarshalIndent(suites, "", "  ")
+	fmt.Fprintln(w, xml.Header+string(xmlData))
+}
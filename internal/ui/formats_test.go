@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// reportResult is a result with one file of most kinds that are not intact.
+func reportResult() *validator.Result {
+	return &validator.Result{
+		FolderPath:  "store",
+		ToolVersion: "1.0.0",
+		IntactFiles: 1,
+		CorruptedFileList: []validator.CorruptedFile{
+			{FilePath: "store/bad", Size: 12, ExpectedHash: "aa", ActualHash: "bb"},
+		},
+		MissingFileList: []string{"store/gone"},
+		ErroredFileList: []validator.ErroredFile{{FilePath: "store/locked", Error: "permission denied"}},
+		InvalidFileList: []string{"store/name with space"},
+		Files:           []validator.FileRecord{{FilePath: "store/good", Status: validator.StatusIntact}},
+	}
+}
+
+func TestPrintCSV(t *testing.T) {
+	var out bytes.Buffer
+	PrintResult([]*validator.Result{reportResult()}, Options{CSV: true}, &out)
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	want := [][]string{
+		csvHeader,
+		{"store", "corrupted", "store/bad", "12", "aa", "bb", "", ""},
+		{"store", "missing", "store/gone", "", "", "", "", ""},
+		{"store", "errored", "store/locked", "", "", "", "", "permission denied"},
+		{"store", "invalid", "store/name with space", "", "", "", "", "name is not a hash"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d lines, got %q", len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("Line %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestPrintSARIF(t *testing.T) {
+	var out bytes.Buffer
+	PrintResult([]*validator.Result{reportResult()}, Options{SARIF: true}, &out)
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Failed to decode SARIF %s: %v", out.Bytes(), err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Version != "1.0.0" {
+		t.Fatalf("Expected a SARIF 2.1.0 log with one run, got %s", out.Bytes())
+	}
+	results := log.Runs[0].Results
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %+v", results)
+	}
+	if results[0].RuleID != "corrupted" || results[0].Level != "error" || results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "store/bad" {
+		t.Errorf("Expected the corrupted file as an error, got %+v", results[0])
+	}
+	if uri := results[3].Locations[0].PhysicalLocation.ArtifactLocation.URI; results[3].Level != "warning" || uri != "store/name%20with%20space" {
+		t.Errorf("Expected the invalid file as a warning at an escaped URI, got %+v", results[3])
+	}
+}
+
+func TestPrintJUnit(t *testing.T) {
+	var out bytes.Buffer
+	PrintResult([]*validator.Result{reportResult()}, Options{JUnit: true}, &out)
+
+	var suites junitSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("Failed to decode JUnit XML %s: %v", out.Bytes(), err)
+	}
+	if suites.Tests != 5 || suites.Failures != 2 || suites.Errors != 1 || suites.Skipped != 1 {
+		t.Errorf("Expected 5 tests with 2 failures, 1 error and 1 skipped, got %+v", suites)
+	}
+	if len(suites.Suites) != 1 || suites.Suites[0].Name != "store" {
+		t.Fatalf("Expected a test suite for the folder, got %+v", suites.Suites)
+	}
+	cases := suites.Suites[0].Cases
+	if cases[0].Name != "store/good" || cases[0].Failure != nil || cases[0].Error != nil {
+		t.Errorf("Expected the recorded intact file to pass, got %+v", cases[0])
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Type != "corrupted" {
+		t.Errorf("Expected the corrupted file to fail, got %+v", cases[1])
+	}
+}
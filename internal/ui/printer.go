@@ -1,65 +1,367 @@
 package ui
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"unicode/utf8"
 
+	"github.com/konidev20/verifydata/internal/jcs"
 	"github.com/konidev20/verifydata/internal/validator"
 	"github.com/rodaine/table"
+	"golang.org/x/term"
 )
 
-func PrintResult(results []*validator.Result, jsonOutput bool, w io.Writer) {
-	if jsonOutput {
-		jsonData, _ := json.MarshalIndent(results, "", "  ")
-		fmt.Println(string(jsonData))
-	} else {
+// Options controls how results are printed.
+type Options struct {
+	// JSON prints the results as indented JSON instead of tables.
+	JSON bool
+	// CompactJSON prints the results as JSON on a single line.
+	CompactJSON bool
+	// CanonicalJSON prints the results as canonical JSON following RFC 8785.
+	CanonicalJSON bool
+	// SummaryOnly leaves out the lists of files and prints only the counts.
+	SummaryOnly bool
+	// Compact prints "key: value" lines instead of tables. Tables too wide
+	// for the terminal are printed this way too.
+	Compact bool
+	// CSV prints a line for every file that is not intact.
+	CSV bool
+	// SARIF prints a SARIF 2.1.0 log for code scanning dashboards.
+	SARIF bool
+	// JUnit prints a JUnit XML report for CI systems.
+	JUnit bool
+}
+
+func PrintResult(results []*validator.Result, opts Options, w io.Writer) {
+	if opts.SummaryOnly {
 		for _, result := range results {
-			fmt.Println("")
-			fmt.Println("-------------------")
-			fmt.Println("Folder Path:", result.FolderPath)
-			fmt.Println("")
-			tbl := table.New("Result", "Value")
-			tbl.WithHeaderSeparatorRow('-')
-			tbl.WithPadding(10)
-			tbl.WithWriter(w)
-
-			tbl.AddRow("Total Files", result.TotalFiles)
-			tbl.AddRow("Intact Files", result.IntactFiles)
-			tbl.AddRow("Corrupted Files", result.CorruptedFiles)
-			tbl.AddRow("Invalid Files", result.InvalidFiles)
-			tbl.Print()
-			fmt.Println("")
-			fmt.Println("\nCorrupted Files:")
-			if len(result.CorruptedFileList) > 0 {
-				tbl = table.New("File Path", "Actual Hash")
-				tbl.WithWriter(w)
-				tbl.WithHeaderSeparatorRow('_')
-				tbl.WithPadding(10)
-				for _, file := range result.CorruptedFileList {
-					tbl.AddRow(file.FilePath, file.ActualHash)
-				}
-			} else {
-				fmt.Println("None")
+			result.DropFileLists()
+		}
+	}
+	switch {
+	case opts.CSV:
+		printCSV(w, results)
+		return
+	case opts.SARIF:
+		printSARIF(w, results)
+		return
+	case opts.JUnit:
+		printJUnit(w, results)
+		return
+	}
+	if opts.JSON || opts.CompactJSON || opts.CanonicalJSON {
+		var jsonData []byte
+		if opts.CanonicalJSON {
+			jsonData, _ = jcs.Marshal(results)
+		} else if opts.CompactJSON {
+			jsonData, _ = json.Marshal(results)
+		} else {
+			jsonData, _ = json.MarshalIndent(results, "", "  ")
+		}
+		fmt.Fprintln(w, string(jsonData))
+		return
+	}
+
+	if !opts.Compact {
+		// The tables are rendered first to find out whether they fit.
+		var buf bytes.Buffer
+		for _, result := range results {
+			printTables(&buf, result.FolderPath, summaryRows(result), resultSections(result))
+		}
+		if fitsTerminal(w, buf.Bytes()) {
+			w.Write(buf.Bytes())
+			return
+		}
+	}
+	for _, result := range results {
+		printCompact(w, result.FolderPath, summaryRows(result), resultSections(result))
+	}
+}
+
+// summaryRow is a row of the table of counts.
+type summaryRow struct {
+	label string
+	value interface{}
+}
+
+// section is a list of files printed below the counts.
+type section struct {
+	title   string
+	headers []interface{}
+	rows    [][]interface{}
+	// separator is the character of the line below the headers.
+	separator rune
+	// always prints the section with None when it has no rows, rather than
+	// leaving it out.
+	always bool
+}
+
+func summaryRows(result *validator.Result) []summaryRow {
+	rows := []summaryRow{
+		{"Total Files", result.TotalFiles},
+		{"Intact Files", result.IntactFiles},
+		{"Corrupted Files", result.CorruptedFiles},
+		{"Invalid Files", result.InvalidFiles},
+		{"Intact Bytes", formatBytes(result.IntactBytes)},
+		{"Corrupted Bytes", formatBytes(result.CorruptedBytes)},
+		{"Corruption Rate", fmt.Sprintf("%.2f%%", result.CorruptionRate*100)},
+		{"Invalid Rate", fmt.Sprintf("%.2f%%", result.InvalidRate*100)},
+	}
+	if result.TrustedFiles > 0 {
+		rows = append(rows, summaryRow{"Trusted Files", result.TrustedFiles})
+	}
+	if result.ContentCacheHits > 0 {
+		rows = append(rows, summaryRow{"Content Cache Hits", result.ContentCacheHits})
+	}
+	if result.BudgetCoverage > 0 || result.SkippedByBudget > 0 {
+		rows = append(rows, summaryRow{"Budget Coverage", fmt.Sprintf("%.2f%% of bytes", result.BudgetCoverage*100)})
+	}
+	if result.TotalSizeMismatch {
+		rows = append(rows, summaryRow{"Total Size Mismatch", fmt.Sprintf("expected %s, found %s", formatBytes(result.ExpectedTotalBytes), formatBytes(result.PresentBytes))})
+	}
+	if result.ReportedUnder != "" {
+		rows = append(rows, summaryRow{"Files Listed Under", result.ReportedUnder})
+	}
+	if result.TreeHash != "" {
+		rows = append(rows, summaryRow{"Tree Hash", result.TreeHash})
+	}
+	if result.TreeHashMismatch {
+		rows = append(rows, summaryRow{"Tree Hash Mismatch", "expected " + result.ExpectedTreeHash})
+	}
+	if result.DeadlineExceeded {
+		rows = append(rows, summaryRow{"Stopped Early", "deadline exceeded"})
+	} else if result.StoppedEarly {
+		rows = append(rows, summaryRow{"Stopped Early", "yes"})
+	}
+	for _, count := range []summaryRow{
+		{"Skipped Special Files", result.SkippedSpecial},
+		{"Broken Symlinks", result.BrokenSymlinks},
+		{"Skipped By Size", result.SkippedSize},
+		{"Skipped By Type", result.SkippedByType},
+		{"Skipped Too New", result.SkippedTooNew},
+		{"Skipped By Budget", result.SkippedByBudget},
+		{"Path Errors", result.PathErrors},
+		{"Modified Files", result.ModifiedFiles},
+		{"Errored Files", result.ErroredFiles},
+		{"Decrypt Errors", result.DecryptErrors},
+		{"Algorithm Mismatches", result.AlgorithmMismatches},
+		{"Alternate Matches", result.AlternateMatches},
+		{"Ignored Files", result.IgnoredFiles},
+		{"Missing Files", result.MissingFiles},
+		{"Not Indexed", result.NotIndexedFiles},
+	} {
+		if count.value.(int) > 0 {
+			rows = append(rows, count)
+		}
+	}
+	return rows
+}
+
+func resultSections(result *validator.Result) []section {
+	var sections []section
+	if len(result.SizeHistogram) > 0 {
+		sizes := section{title: "File Sizes", headers: []interface{}{"Size", "Files"}, separator: '-'}
+		for _, bucket := range result.SizeHistogram {
+			sizes.rows = append(sizes.rows, []interface{}{bucket.Range, bucket.Count})
+		}
+		sections = append(sections, sizes)
+	}
+	if len(result.WorkerStats) > 0 {
+		var total int64
+		for _, stat := range result.WorkerStats {
+			total += stat.Bytes
+		}
+		workers := section{title: "Worker Stats", headers: []interface{}{"Worker", "Files", "Bytes", "Share"}, separator: '-'}
+		for _, stat := range result.WorkerStats {
+			share := 0.0
+			if total > 0 {
+				share = float64(stat.Bytes) / float64(total)
 			}
-			fmt.Println("")
-			fmt.Println("\nInvalid File Names:")
-			if len(result.InvalidFileList) > 0 {
-				tbl = table.New("File Path")
-				tbl.WithWriter(w)
-				tbl.WithHeaderSeparatorRow('-')
-				tbl.WithPadding(10)
-				for _, file := range result.InvalidFileList {
-					tbl.AddRow(file)
-				}
+			workers.rows = append(workers.rows, []interface{}{stat.Worker, stat.Files, formatBytes(stat.Bytes), fmt.Sprintf("%.2f%%", share*100)})
+		}
+		sections = append(sections, workers)
+	}
+	corrupted := corruptedSection("Corrupted Files", result.CorruptedFileList)
+	corrupted.always = true
+	sections = append(sections,
+		corrupted,
+		corruptedSection("Ignored Files", result.IgnoredFileList),
+		pathSection("Missing Files", "File Path", result.MissingFileList),
+		pathSection("Not Indexed", "File Path", result.NotIndexed),
+		pathSection("Empty Directories", "Directory", result.EmptyDirs),
+		pathSection("Broken Symlinks", "File Path", result.BrokenSymlinkList),
+		erroredSection("Path Errors", result.PathErrorList),
+		pathSection("Modified Files", "File Path", result.ModifiedFileList),
+		erroredSection("Errored Files", result.ErroredFileList),
+		erroredSection("Decrypt Errors", result.DecryptErrorList),
+	)
+	mismatches := section{title: "Algorithm Mismatches", headers: []interface{}{"File Path", "Algorithm"}, separator: '-'}
+	for _, file := range result.AlgorithmMismatchList {
+		mismatches.rows = append(mismatches.rows, []interface{}{file.FilePath, file.Algorithm})
+	}
+	alternates := section{title: "Alternate Matches", headers: []interface{}{"File Path", "Algorithm"}, separator: '-'}
+	for _, file := range result.AlternateMatchList {
+		alternates.rows = append(alternates.rows, []interface{}{file.FilePath, file.Algorithm})
+	}
+	invalid := pathSection("Invalid File Names", "File Path", result.InvalidFileList)
+	invalid.always = true
+	// A folder of files that were never named by hash would list every file.
+	if result.MostlyInvalid() && len(invalid.rows) > maxMostlyInvalid {
+		invalid.rows = append(invalid.rows[:maxMostlyInvalid:maxMostlyInvalid],
+			[]interface{}{fmt.Sprintf("... and %d more, see --json for the full list", len(result.InvalidFileList)-maxMostlyInvalid)})
+	}
+	return append(sections, mismatches, alternates, invalid)
+}
+
+// maxMostlyInvalid is the number of invalid files listed for a result that
+// is mostly invalid.
+const maxMostlyInvalid = 20
+
+func pathSection(title, header string, paths []string) section {
+	s := section{title: title, headers: []interface{}{header}, separator: '-'}
+	for _, path := range paths {
+		s.rows = append(s.rows, []interface{}{path})
+	}
+	return s
+}
+
+func erroredSection(title string, files []validator.ErroredFile) section {
+	s := section{title: title, headers: []interface{}{"File Path", "Error"}, separator: '-'}
+	for _, file := range files {
+		s.rows = append(s.rows, []interface{}{file.FilePath, file.Error})
+	}
+	return s
+}
+
+// corruptedSection lists the files with their size and actual hash, and their content
+// type and the reason they are corrupted when these are known.
+func corruptedSection(title string, files []validator.CorruptedFile) section {
+	withType, withReason := false, false
+	for _, file := range files {
+		withType = withType || file.ContentType != ""
+		withReason = withReason || file.Reason != ""
+	}
+
+	s := section{title: title, headers: []interface{}{"File Path", "Size", "Actual Hash"}, separator: '_'}
+	if withType {
+		s.headers = append(s.headers, "Content Type")
+	}
+	if withReason {
+		s.headers = append(s.headers, "Reason")
+	}
+	for _, file := range files {
+		row := []interface{}{file.FilePath, formatBytes(file.Size), file.ActualHash}
+		if withType {
+			row = append(row, file.ContentType)
+		}
+		if withReason {
+			row = append(row, file.Reason)
+		}
+		s.rows = append(s.rows, row)
+	}
+	return s
+}
+
+func printTables(w io.Writer, folderPath string, rows []summaryRow, sections []section) {
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "-------------------")
+	fmt.Fprintln(w, "Folder Path:", folderPath)
+	fmt.Fprintln(w, "")
+	tbl := table.New("Result", "Value")
+	tbl.WithHeaderSeparatorRow('-')
+	tbl.WithPadding(10)
+	tbl.WithWriter(w)
+	for _, row := range rows {
+		tbl.AddRow(row.label, row.value)
+	}
+	tbl.Print()
 
-				tbl.Print()
-			} else {
-				fmt.Println("None")
+	for _, s := range sections {
+		if len(s.rows) == 0 && !s.always {
+			continue
+		}
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "\n"+s.title+":")
+		if len(s.rows) == 0 {
+			fmt.Fprintln(w, "None")
+			continue
+		}
+		tbl = table.New(s.headers...)
+		tbl.WithWriter(w)
+		tbl.WithHeaderSeparatorRow(s.separator)
+		tbl.WithPadding(10)
+		for _, row := range s.rows {
+			tbl.AddRow(row...)
+		}
+		tbl.Print()
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "-------------------")
+	fmt.Fprintln(w, "")
+}
+
+// printCompact prints the same as printTables as "key: value" lines, which
+// do not wrap into a mess on narrow terminals. Every listed file starts a
+// line, followed by its other columns indented below it.
+func printCompact(w io.Writer, folderPath string, rows []summaryRow, sections []section) {
+	fmt.Fprintln(w, "Folder Path:", folderPath)
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s: %v\n", row.label, row.value)
+	}
+	for _, s := range sections {
+		if len(s.rows) == 0 && !s.always {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", s.title)
+		if len(s.rows) == 0 {
+			fmt.Fprintln(w, "  None")
+			continue
+		}
+		for _, row := range s.rows {
+			fmt.Fprintf(w, "  %v\n", row[0])
+			for i := 1; i < len(row); i++ {
+				if value := fmt.Sprint(row[i]); value != "" {
+					fmt.Fprintf(w, "    %v: %s\n", s.headers[i], value)
+				}
 			}
-			fmt.Println("")
-			fmt.Println("-------------------")
-			fmt.Println("")
 		}
 	}
+	fmt.Fprintln(w, "")
+}
+
+// fitsTerminal reports whether the lines of out are no wider than the
+// terminal w writes to. Output that does not go to a terminal always fits.
+func fitsTerminal(w io.Writer, out []byte) bool {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return true
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 {
+		return true
+	}
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if utf8.RuneCount(line) > width {
+			return false
+		}
+	}
+	return true
+}
+
+// formatBytes formats a byte count with a binary unit, keeping the exact count
+// for amounts above a KiB.
+func formatBytes(n int64) string {
+	const unit = 1 << 10
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB (%d bytes)", float64(n)/float64(div), "KMGTPE"[exp], n)
 }
@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/mattn/go-isatty"
+)
+
+// etaSmoothing is the weight of the latest throughput sample in the moving
+// average the ETA is computed from.
+const etaSmoothing = 0.2
+
+// etaEstimator estimates the remaining time from an exponential moving average
+// of the throughput in bytes per second.
+type etaEstimator struct {
+	rate      float64
+	lastBytes int64
+	lastTime  time.Time
+}
+
+// update adds a sample and returns the estimated time until all bytes are
+// checked. ok is false until the totals are known and a rate was measured.
+func (e *etaEstimator) update(s validator.ProgressSnapshot, now time.Time) (time.Duration, bool) {
+	if s.DoneBytes < e.lastBytes {
+		// The counts were reset for the next folder.
+		*e = etaEstimator{}
+	}
+	if !e.lastTime.IsZero() {
+		if elapsed := now.Sub(e.lastTime).Seconds(); elapsed > 0 {
+			sample := float64(s.DoneBytes-e.lastBytes) / elapsed
+			if e.rate == 0 {
+				e.rate = sample
+			} else {
+				e.rate = etaSmoothing*sample + (1-etaSmoothing)*e.rate
+			}
+		}
+	}
+	e.lastBytes, e.lastTime = s.DoneBytes, now
+	if !s.Walked || e.rate <= 0 {
+		return 0, false
+	}
+	remaining := float64(s.Bytes - s.DoneBytes)
+	return time.Duration(remaining / e.rate * float64(time.Second)), true
+}
+
+// formatETA formats the duration as hours, minutes and seconds, such as 00:12:34.
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+// progressLine describes the snapshot. The percentage and ETA are only shown
+// once the walk has finished and the totals are known.
+func progressLine(s validator.ProgressSnapshot, eta time.Duration, etaOK bool) string {
+	if !s.Walked {
+		return fmt.Sprintf("%s: %d files checked, %d found so far", s.Folder, s.DoneFiles, s.Files)
+	}
+	percent := 100.0
+	if s.Bytes > 0 {
+		percent = float64(s.DoneBytes) / float64(s.Bytes) * 100
+	}
+	line := fmt.Sprintf("%s: %d/%d files, %.1f%%", s.Folder, s.DoneFiles, s.Files, percent)
+	if etaOK {
+		line += " ETA " + formatETA(eta)
+	}
+	return line
+}
+
+// ShowProgress writes the progress of the run to w every interval until the
+// returned function is called. On a terminal the line is redrawn in place.
+func ShowProgress(w io.Writer, progress *validator.Progress, interval time.Duration) (stop func()) {
+	terminal := false
+	if f, ok := w.(*os.File); ok {
+		terminal = isatty.IsTerminal(f.Fd())
+	}
+
+	var estimator etaEstimator
+	var width int
+	return every(interval, func(now time.Time) {
+		s := progress.Snapshot()
+		if s.Folder == "" {
+			return
+		}
+		eta, ok := estimator.update(s, now)
+		line := progressLine(s, eta, ok)
+		if terminal {
+			fmt.Fprint(w, "\r"+line+strings.Repeat(" ", max(width-len(line), 0)))
+			width = len(line)
+		} else {
+			fmt.Fprintln(w, line)
+		}
+	}, func() {
+		if terminal && width > 0 {
+			fmt.Fprint(w, "\r"+strings.Repeat(" ", width)+"\r")
+		}
+	})
+}
+
+// statusLine describes the snapshot for a log, with the time since the run
+// started. The total is only shown once the walk has finished.
+func statusLine(s validator.ProgressSnapshot, elapsed time.Duration) string {
+	total := "?"
+	if s.Walked {
+		total = strconv.FormatInt(s.Files, 10)
+	}
+	return fmt.Sprintf("%s: processed %d/%s, corrupted %d, elapsed %s", s.Folder, s.DoneFiles, total, s.Corrupted, formatETA(elapsed))
+}
+
+// LogProgress writes a status line with the files processed and corrupted
+// so far to w every interval until the returned function is called, for logs
+// of runs without a terminal. Lines are never redrawn in place.
+func LogProgress(w io.Writer, progress *validator.Progress, interval time.Duration) (stop func()) {
+	started := time.Now()
+	return every(interval, func(now time.Time) {
+		s := progress.Snapshot()
+		if s.Folder == "" {
+			return
+		}
+		fmt.Fprintln(w, statusLine(s, now.Sub(started)))
+	}, func() {})
+}
+
+// progressEvent is a line written by StreamProgress.
+type progressEvent struct {
+	Folder     string `json:"folder"`
+	Processed  int64  `json:"processed"`
+	Total      int64  `json:"total"`
+	Bytes      int64  `json:"bytes"`
+	TotalBytes int64  `json:"total_bytes"`
+	// TotalKnown is set once all files have been found and the totals are final.
+	TotalKnown bool `json:"total_known"`
+	Final      bool `json:"final"`
+}
+
+// StreamProgress writes the progress of the run to w every interval as a
+// JSON object on its own line, for programs that wrap verifydata, until the
+// returned function is called. A last event with final set is written then.
+func StreamProgress(w io.Writer, progress *validator.Progress, interval time.Duration) (stop func()) {
+	encoder := json.NewEncoder(w)
+	emit := func(final bool) {
+		s := progress.Snapshot()
+		if s.Folder == "" && !final {
+			return
+		}
+		encoder.Encode(progressEvent{
+			Folder:     s.Folder,
+			Processed:  s.DoneFiles,
+			Total:      s.Files,
+			Bytes:      s.DoneBytes,
+			TotalBytes: s.Bytes,
+			TotalKnown: s.Walked,
+			Final:      final,
+		})
+	}
+	return every(interval, func(time.Time) { emit(false) }, func() { emit(true) })
+}
+
+// every calls tick at every interval until the returned function is called,
+// which then calls last and returns once tick is no longer running.
+func every(interval time.Duration, tick func(now time.Time), last func()) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				last()
+				return
+			case now := <-ticker.C:
+				tick(now)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// streamedList is the list written to the output as its entries come in.
+// The others are spooled to temporary files and copied once the result is
+// complete, since a JSON object can only have one array open at a time.
+const streamedList = "corrupted_file_list"
+
+// JSONStream writes results as a JSON array without holding the lists of
+// files in memory. The corrupted files are written as the workers report
+// them, the other lists once the result is complete, followed by the counts.
+// It is safe for concurrent use.
+type JSONStream struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	results int
+	// open is set while the corrupted files of a result are being written.
+	open    bool
+	entries int
+	spools  map[string]*spool
+	order   []string
+	err     error
+}
+
+// spool keeps the encoded entries of a list in a temporary file.
+type spool struct {
+	file    *os.File
+	w       *bufio.Writer
+	entries int
+}
+
+// NewJSONStream returns a stream writing to w.
+func NewJSONStream(w io.Writer) *JSONStream {
+	return &JSONStream{w: bufio.NewWriter(w), spools: make(map[string]*spool)}
+}
+
+// Add adds the entry to the list of the result being checked.
+func (s *JSONStream) Add(list string, entry interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.begin()
+	if list == streamedList {
+		s.writeEntry(s.w, &s.entries, data)
+		// Flushing every entry lets a reader of the output follow along.
+		s.setErr(s.w.Flush())
+		return
+	}
+	sp, ok := s.spools[list]
+	if !ok {
+		file, err := os.CreateTemp("", "verifydata-"+list+"-*")
+		if err != nil {
+			s.err = err
+			return
+		}
+		sp = &spool{file: file, w: bufio.NewWriter(file)}
+		s.spools[list] = sp
+		s.order = append(s.order, list)
+	}
+	s.writeEntry(sp.w, &sp.entries, data)
+}
+
+// End completes the result being checked with the spooled lists and the
+// counts of result, whose own lists are written as they are.
+func (s *JSONStream) End(result *validator.Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	summary, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	s.begin()
+	s.write("\n]")
+	for _, list := range s.order {
+		s.copySpool(list, s.spools[list])
+	}
+	// The summary has at least the folder path, so it is never "{}".
+	s.write(",")
+	s.write(string(summary[1:]))
+	s.open, s.entries = false, 0
+	s.spools, s.order = make(map[string]*spool), nil
+	s.setErr(s.w.Flush())
+	return s.err
+}
+
+// Close ends the array of results. A result that was not ended, because its
+// run failed, is closed with the entries written so far.
+func (s *JSONStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open {
+		s.write("\n]}")
+		for list, sp := range s.spools {
+			sp.file.Close()
+			os.Remove(sp.file.Name())
+			delete(s.spools, list)
+		}
+	}
+	if s.results == 0 {
+		s.write("[")
+	}
+	s.write("]\n")
+	s.setErr(s.w.Flush())
+	return s.err
+}
+
+// begin opens the array of corrupted files of a new result unless one is
+// open already.
+func (s *JSONStream) begin() {
+	if s.open {
+		return
+	}
+	if s.results == 0 {
+		s.write("[")
+	} else {
+		s.write(",\n")
+	}
+	s.results++
+	s.open = true
+	s.write(`{"` + streamedList + `":[`)
+}
+
+func (s *JSONStream) writeEntry(w *bufio.Writer, entries *int, data []byte) {
+	if *entries > 0 {
+		w.WriteByte(',')
+	}
+	w.WriteByte('\n')
+	_, err := w.Write(data)
+	s.setErr(err)
+	*entries++
+}
+
+func (s *JSONStream) copySpool(list string, sp *spool) {
+	defer os.Remove(sp.file.Name())
+	defer sp.file.Close()
+	if err := sp.w.Flush(); err != nil {
+		s.setErr(err)
+		return
+	}
+	if _, err := sp.file.Seek(0, io.SeekStart); err != nil {
+		s.setErr(err)
+		return
+	}
+	s.write(fmt.Sprintf(",\n%q:[", list))
+	_, err := io.Copy(s.w, sp.file)
+	s.setErr(err)
+	s.write("\n]")
+}
+
+func (s *JSONStream) write(str string) {
+	_, err := s.w.WriteString(str)
+	s.setErr(err)
+}
+
+func (s *JSONStream) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// decodeStream checks that the output of a stream is valid JSON and decodes
+// its results.
+func decodeStream(t *testing.T, out []byte) []*validator.Result {
+	t.Helper()
+	if !json.Valid(out) {
+		t.Fatalf("Expected valid JSON, got %s", out)
+	}
+	var results []*validator.Result
+	if err := json.Unmarshal(out, &results); err != nil {
+		t.Fatalf("Failed to decode %s: %v", out, err)
+	}
+	return results
+}
+
+func TestJSONStream(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		strings.Repeat("0", 64),
+		"data.bin",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	stream := NewJSONStream(&out)
+	// The second run of the folder spools its invalid files like the first.
+	for i := 0; i < 2; i++ {
+		result, err := validator.ProcessFolder(dir, validator.Options{Workers: 2, Lists: stream})
+		if err != nil {
+			t.Fatalf("ProcessFolder failed: %v", err)
+		}
+		if err := stream.End(result); err != nil {
+			t.Fatalf("End failed: %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	results := decodeStream(t, out.Bytes())
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.IntactFiles != 1 || len(result.CorruptedFileList) != 1 || filepath.Base(result.CorruptedFileList[0].FilePath) != strings.Repeat("0", 64) {
+			t.Errorf("Expected 1 intact and 1 corrupted file, got %d and %v", result.IntactFiles, result.CorruptedFileList)
+		}
+		if len(result.InvalidFileList) != 1 || filepath.Base(result.InvalidFileList[0]) != "data.bin" {
+			t.Errorf("Expected the spooled invalid file, got %v", result.InvalidFileList)
+		}
+	}
+}
+
+func TestJSONStreamInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 10; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%064d", i)), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	stream := NewJSONStream(&out)
+	result, err := validator.ProcessFolder(dir, validator.Options{Workers: 2, Lists: stream, Context: ctx})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if err := stream.End(result); err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if results := decodeStream(t, out.Bytes()); len(results) != 1 || !results[0].StoppedEarly {
+		t.Errorf("Expected a result stopped early, got %+v", results)
+	}
+}
+
+func TestJSONStreamCloseWithoutEnd(t *testing.T) {
+	var out bytes.Buffer
+	stream := NewJSONStream(&out)
+	stream.Add("corrupted_file_list", validator.CorruptedFile{FilePath: "store/bad"})
+	stream.Add("invalid_file_list", "store/name")
+	// The run failed before its result could be ended.
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	results := decodeStream(t, out.Bytes())
+	if len(results) != 1 || len(results[0].CorruptedFileList) != 1 || results[0].CorruptedFileList[0].FilePath != "store/bad" {
+		t.Errorf("Expected the corrupted files written so far, got %+v", results)
+	}
+}
+
+func TestJSONStreamEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewJSONStream(&out).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if results := decodeStream(t, out.Bytes()); len(results) != 0 {
+		t.Errorf("Expected no results, got %+v", results)
+	}
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	numaOnce  sync.Once
+	numaNodes [][]int
+)
+
+// pinHashWorker locks the calling goroutine to its OS thread and restricts the
+// thread to the CPUs of one NUMA node, spreading the workers over the nodes
+// round-robin. The thread is discarded when the goroutine exits. Errors are
+// ignored, since affinity only affects performance.
+func pinHashWorker(worker int) {
+	numaOnce.Do(func() { numaNodes = readNUMANodes("/sys/devices/system/node") })
+	if len(numaNodes) == 0 {
+		return
+	}
+	var set unix.CPUSet
+	for _, cpu := range numaNodes[worker%len(numaNodes)] {
+		set.Set(cpu)
+	}
+	runtime.LockOSThread()
+	unix.SchedSetaffinity(0, &set)
+}
+
+// readNUMANodes returns the CPUs of every NUMA node listed in dir, in node order.
+func readNUMANodes(dir string) [][]int {
+	paths, _ := filepath.Glob(filepath.Join(dir, "node[0-9]*", "cpulist"))
+	sort.Slice(paths, func(i, j int) bool {
+		return nodeNumber(paths[i]) < nodeNumber(paths[j])
+	})
+	var nodes [][]int
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if cpus := parseCPUList(strings.TrimSpace(string(data))); len(cpus) > 0 {
+			nodes = append(nodes, cpus)
+		}
+	}
+	return nodes
+}
+
+func nodeNumber(cpulistPath string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(cpulistPath)), "node"))
+	return n
+}
+
+// parseCPUList parses a kernel CPU list such as "0-3,8,10-11".
+func parseCPUList(list string) []int {
+	var cpus []int
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(last); err != nil {
+				continue
+			}
+		}
+		for cpu := lo; cpu <= hi; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCPUList(t *testing.T) {
+	if got := parseCPUList("0-3,8,10-11"); !reflect.DeepEqual(got, []int{0, 1, 2, 3, 8, 10, 11}) {
+		t.Errorf("Unexpected CPUs %v", got)
+	}
+	if got := parseCPUList(""); len(got) != 0 {
+		t.Errorf("Expected no CPUs for an empty list, got %v", got)
+	}
+}
+
+func TestReadNUMANodes(t *testing.T) {
+	dir := t.TempDir()
+	for node, list := range map[string]string{"node0": "0-1\n", "node1": "2-3\n", "node10": "4\n", "node2": "\n"} {
+		if err := os.MkdirAll(filepath.Join(dir, node), 0o755); err != nil {
+			t.Fatalf("Failed to create node directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, node, "cpulist"), []byte(list), 0o644); err != nil {
+			t.Fatalf("Failed to write cpulist: %v", err)
+		}
+	}
+	want := [][]int{{0, 1}, {2, 3}, {4}}
+	if got := readNUMANodes(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected nodes %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package validator
+
+// pinHashWorker does nothing on platforms without CPU affinity support.
+func pinHashWorker(worker int) {}
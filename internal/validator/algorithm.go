@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// AlgorithmMismatch is a file whose name looks like the hash of another
+// algorithm than SHA256, judging by its length.
+type AlgorithmMismatch struct {
+	FilePath  string `json:"file_path"`
+	Algorithm string `json:"algorithm"`
+}
+
+// hexAlgorithms maps the lengths of hex encoded hashes other than SHA256 to
+// their most common algorithm.
+var hexAlgorithms = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	56:  "sha224",
+	96:  "sha384",
+	128: "sha512",
+}
+
+var hexPattern = regexp.MustCompile(`^[a-f0-9]+$`)
+
+// otherAlgorithm returns the algorithm the expected hash was likely computed
+// with, if it is a hex encoded hash but too short or long for SHA256.
+func otherAlgorithm(expectedHash string) (string, bool) {
+	algorithm, ok := hexAlgorithms[len(expectedHash)]
+	if !ok || !hexPattern.MatchString(expectedHash) {
+		return "", false
+	}
+	return algorithm, true
+}
+
+// alternateAlgorithms are the algorithms whose hashes are as long as SHA256
+// ones, which the length of a name cannot tell apart from SHA256.
+var alternateAlgorithms = map[string]func() hash.Hash{
+	"sha3-256":   sha3.New256,
+	"sha512-256": sha512.New512_256,
+	"blake2b-256": func() hash.Hash {
+		h, _ := blake2b.New256(nil)
+		return h
+	},
+}
+
+// AlternateAlgorithms returns the names of the algorithms Options.TryAlgorithms
+// supports.
+func AlternateAlgorithms() []string {
+	names := make([]string, 0, len(alternateAlgorithms))
+	for name := range alternateAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tryAlgorithms reports whether the file is to be hashed with
+// opts.TryAlgorithms: when its name is a full hash that the SHA256 hash of
+// its content does not match.
+func (opts Options) tryAlgorithms(expectedHash, actualHash string) bool {
+	return len(opts.TryAlgorithms) > 0 && len(expectedHash) == len(actualHash) &&
+		expectedHash != actualHash && !opts.IgnoreHashes[expectedHash]
+}
+
+// matchingAlgorithm hashes the file with every algorithm of
+// opts.TryAlgorithms in a single read and returns the first one whose hash is
+// the expected hash, or "" when there is none.
+func matchingAlgorithm(filePath, expectedHash string, opts Options) (string, error) {
+	var file io.ReadCloser
+	var err error
+	if opts.Source != nil {
+		file, err = opts.Source.Open(filePath)
+	} else if opts.LongPaths {
+		file, err = os.Open(longPath(filePath))
+	} else {
+		file, err = os.Open(filePath)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hashes := make([]hash.Hash, len(opts.TryAlgorithms))
+	writers := make([]io.Writer, len(opts.TryAlgorithms))
+	for i, algorithm := range opts.TryAlgorithms {
+		hashes[i] = alternateAlgorithms[algorithm]()
+		writers[i] = hashes[i]
+	}
+	r := withCancel(withDeadline(file, opts.Deadline), opts.Context)
+	if _, err := io.Copy(io.MultiWriter(writers...), limitReader(r, opts.Limiter)); err != nil {
+		return "", err
+	}
+	for i, h := range hashes {
+		if hex.EncodeToString(h.Sum(nil)) == expectedHash {
+			return opts.TryAlgorithms[i], nil
+		}
+	}
+	return "", nil
+}
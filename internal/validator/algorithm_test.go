@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+func TestProcessFolderAlgorithmMismatch(t *testing.T) {
+	root := t.TempDir()
+	content := []byte("test content")
+	md5Sum := md5.Sum(content)
+	sha1Sum := sha1.Sum(content)
+	sha512Sum := sha512.Sum512(content)
+	names := map[string]string{
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72": "",
+		hex.EncodeToString(md5Sum[:]):                                      "md5",
+		hex.EncodeToString(sha1Sum[:]):                                     "sha1",
+		hex.EncodeToString(sha512Sum[:]):                                   "sha512",
+		"not-a-hash":                                                       "",
+		// 40 characters, but not hex.
+		"zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz": "",
+	}
+	for name := range names {
+		if err := os.WriteFile(filepath.Join(root, name), content, 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(root, Options{Workers: 2, RecordFiles: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.CorruptedFiles != 0 || result.InvalidFiles != 2 {
+		t.Errorf("Expected 1 intact, 0 corrupted and 2 invalid files, got %d, %d and %d", result.IntactFiles, result.CorruptedFiles, result.InvalidFiles)
+	}
+	if result.AlgorithmMismatches != 3 || len(result.AlgorithmMismatchList) != 3 {
+		t.Fatalf("Expected 3 algorithm mismatches, got %d: %v", result.AlgorithmMismatches, result.AlgorithmMismatchList)
+	}
+	for _, mismatch := range result.AlgorithmMismatchList {
+		if want := names[filepath.Base(mismatch.FilePath)]; mismatch.Algorithm != want {
+			t.Errorf("Expected %s to be reported as %s, got %s", mismatch.FilePath, want, mismatch.Algorithm)
+		}
+	}
+	mismatched := 0
+	for _, file := range result.Files {
+		if file.Status == StatusAlgorithmMismatch {
+			mismatched++
+		}
+	}
+	if mismatched != 3 {
+		t.Errorf("Expected 3 file records with status %s, got %d", StatusAlgorithmMismatch, mismatched)
+	}
+}
+
+func TestProcessFolderTryAlgorithms(t *testing.T) {
+	root := t.TempDir()
+	sha3Sum := sha3.Sum256([]byte("sha3 content"))
+	sha512Sum := sha512.Sum512_256([]byte("sha512 content"))
+	files := map[string]string{
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72": "test content",
+		hex.EncodeToString(sha3Sum[:]):                                     "sha3 content",
+		hex.EncodeToString(sha512Sum[:]):                                   "sha512 content",
+		// Matches none of the algorithms.
+		"0000000000000000000000000000000000000000000000000000000000000000": "corrupted content",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(root, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.CorruptedFiles != 3 || result.AlternateMatches != 0 {
+		t.Errorf("Expected 1 intact and 3 corrupted files without alternate matches, got %d and %d: %v", result.IntactFiles, result.CorruptedFiles, result.AlternateMatchList)
+	}
+
+	result, err = ProcessFolder(root, Options{Workers: 2, TryAlgorithms: []string{"sha3-256", "sha512-256"}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 3 || result.CorruptedFiles != 1 {
+		t.Errorf("Expected 3 intact and 1 corrupted files, got %d and %d", result.IntactFiles, result.CorruptedFiles)
+	}
+	want := []AlgorithmMismatch{
+		{FilePath: filepath.Join(root, hex.EncodeToString(sha3Sum[:])), Algorithm: "sha3-256"},
+		{FilePath: filepath.Join(root, hex.EncodeToString(sha512Sum[:])), Algorithm: "sha512-256"},
+	}
+	if want[0].FilePath > want[1].FilePath {
+		want[0], want[1] = want[1], want[0]
+	}
+	if len(result.AlternateMatchList) != 2 || result.AlternateMatchList[0] != want[0] || result.AlternateMatchList[1] != want[1] {
+		t.Errorf("Expected alternate matches %v, got %v", want, result.AlternateMatchList)
+	}
+}
@@ -0,0 +1,264 @@
+package validator
+
+import (
+	"encoding/binary"
+	"hash"
+	"io"
+	"math/bits"
+	"sync"
+)
+
+// BLAKE3 hashes its input as a binary tree of 1 KiB chunks, so the hashes of
+// separate parts of a file can be combined into the hash of the whole file.
+// That lets a large file be hashed by several goroutines, which SHA256 and
+// the other algorithms of VerifyFile do not allow. Only the default hash
+// mode with a 32 byte output is implemented.
+const (
+	blake3ChunkLen = 1024
+	blake3BlockLen = 64
+
+	blake3ChunkStart = 1 << 0
+	blake3ChunkEnd   = 1 << 1
+	blake3Parent     = 1 << 2
+	blake3Root       = 1 << 3
+)
+
+var blake3IV = [8]uint32{0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19}
+
+var blake3Permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func blake3G(s *[16]uint32, a, b, c, d int, x, y uint32) {
+	s[a] += s[b] + x
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] += s[b] + y
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+// blake3Compress returns the first 8 words of the compression of the block,
+// which are the chaining value of a chunk or parent node.
+func blake3Compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen, flags uint32) [8]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake3IV[0], blake3IV[1], blake3IV[2], blake3IV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for round := 0; round < 7; round++ {
+		blake3G(&s, 0, 4, 8, 12, m[0], m[1])
+		blake3G(&s, 1, 5, 9, 13, m[2], m[3])
+		blake3G(&s, 2, 6, 10, 14, m[4], m[5])
+		blake3G(&s, 3, 7, 11, 15, m[6], m[7])
+		blake3G(&s, 0, 5, 10, 15, m[8], m[9])
+		blake3G(&s, 1, 6, 11, 12, m[10], m[11])
+		blake3G(&s, 2, 7, 8, 13, m[12], m[13])
+		blake3G(&s, 3, 4, 9, 14, m[14], m[15])
+		var permuted [16]uint32
+		for i, j := range blake3Permutation {
+			permuted[i] = m[j]
+		}
+		m = permuted
+	}
+	var out [8]uint32
+	for i := range out {
+		out[i] = s[i] ^ s[i+8]
+	}
+	return out
+}
+
+// blake3Output is a chunk or parent node before its last compression, which
+// gives either its chaining value or, with the root flag, the hash.
+type blake3Output struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o blake3Output) chainingValue() [8]uint32 {
+	return blake3Compress(&o.cv, &o.block, o.counter, o.blockLen, o.flags)
+}
+
+func (o blake3Output) sum(b []byte) []byte {
+	words := blake3Compress(&o.cv, &o.block, 0, o.blockLen, o.flags|blake3Root)
+	for _, w := range words {
+		b = binary.LittleEndian.AppendUint32(b, w)
+	}
+	return b
+}
+
+func blake3ParentOutput(left, right [8]uint32) blake3Output {
+	o := blake3Output{cv: blake3IV, blockLen: blake3BlockLen, flags: blake3Parent}
+	copy(o.block[:8], left[:])
+	copy(o.block[8:], right[:])
+	return o
+}
+
+// blake3Chunk hashes the blocks of one chunk.
+type blake3Chunk struct {
+	cv      [8]uint32
+	counter uint64
+	buf     [blake3BlockLen]byte
+	bufLen  int
+	blocks  int
+}
+
+func newBlake3Chunk(counter uint64) blake3Chunk {
+	return blake3Chunk{cv: blake3IV, counter: counter}
+}
+
+func (c *blake3Chunk) len() int {
+	return c.blocks*blake3BlockLen + c.bufLen
+}
+
+func (c *blake3Chunk) startFlag() uint32 {
+	if c.blocks == 0 {
+		return blake3ChunkStart
+	}
+	return 0
+}
+
+func (c *blake3Chunk) write(p []byte) {
+	for len(p) > 0 {
+		// The last block is only compressed once it is known to be the last.
+		if c.bufLen == blake3BlockLen {
+			block := blake3Words(&c.buf)
+			c.cv = blake3Compress(&c.cv, &block, c.counter, blake3BlockLen, c.startFlag())
+			c.blocks++
+			c.bufLen = 0
+		}
+		n := copy(c.buf[c.bufLen:], p)
+		c.bufLen += n
+		p = p[n:]
+	}
+}
+
+func (c *blake3Chunk) output() blake3Output {
+	var buf [blake3BlockLen]byte
+	copy(buf[:], c.buf[:c.bufLen])
+	return blake3Output{cv: c.cv, block: blake3Words(&buf), counter: c.counter, blockLen: uint32(c.bufLen), flags: c.startFlag() | blake3ChunkEnd}
+}
+
+func blake3Words(b *[blake3BlockLen]byte) [16]uint32 {
+	var words [16]uint32
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return words
+}
+
+// blake3Hasher hashes a stream of bytes, starting at the chunk with the
+// given counter, so that it can hash a subtree of a larger input as well as
+// a whole input.
+type blake3Hasher struct {
+	chunk blake3Chunk
+	start uint64
+	// stack holds the chaining values of the completed subtrees not yet
+	// merged with their right siblings.
+	stack [][8]uint32
+}
+
+func newBlake3() hash.Hash {
+	return newBlake3At(0)
+}
+
+func newBlake3At(counter uint64) *blake3Hasher {
+	return &blake3Hasher{chunk: newBlake3Chunk(counter), start: counter}
+}
+
+func (h *blake3Hasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if h.chunk.len() == blake3ChunkLen {
+			cv := h.chunk.output().chainingValue()
+			// Every chunk completed merges the subtrees it completes.
+			for done := h.chunk.counter - h.start + 1; done&1 == 0; done >>= 1 {
+				cv = blake3ParentOutput(h.stack[len(h.stack)-1], cv).chainingValue()
+				h.stack = h.stack[:len(h.stack)-1]
+			}
+			h.stack = append(h.stack, cv)
+			h.chunk = newBlake3Chunk(h.chunk.counter + 1)
+		}
+		take := min(blake3ChunkLen-h.chunk.len(), len(p))
+		h.chunk.write(p[:take])
+		p = p[take:]
+	}
+	return n, nil
+}
+
+// output returns the root node of what was written.
+func (h *blake3Hasher) output() blake3Output {
+	o := h.chunk.output()
+	for i := len(h.stack) - 1; i >= 0; i-- {
+		o = blake3ParentOutput(h.stack[i], o.chainingValue())
+	}
+	return o
+}
+
+func (h *blake3Hasher) Sum(b []byte) []byte {
+	return h.output().sum(b)
+}
+
+func (h *blake3Hasher) Reset() {
+	*h = *newBlake3At(h.start)
+}
+
+func (h *blake3Hasher) Size() int      { return 32 }
+func (h *blake3Hasher) BlockSize() int { return blake3BlockLen }
+
+// parallelSegment is the smallest part of a file hashed on its own by
+// blake3Parallel.
+var parallelSegment int64 = 1 << 20
+
+// blake3Parallel hashes the first size bytes of r with up to workers
+// goroutines, splitting it into subtrees of at least parallelSegment bytes
+// that are hashed at the same time and combined, and returns the hash. Every
+// part is read through wrap, which applies the rate limit.
+func blake3Parallel(r io.ReaderAt, size int64, workers int, wrap func(io.Reader) io.Reader) ([]byte, error) {
+	// A few segments per worker keep them busy until the end.
+	segment := max(parallelSegment, size/int64(4*max(workers, 1)))
+	slots := make(chan struct{}, max(workers, 1))
+	o, err := blake3Subtree(r, 0, size, segment, slots, wrap)
+	if err != nil {
+		return nil, err
+	}
+	return o.sum(nil), nil
+}
+
+// blake3Subtree returns the root node of the subtree of the size bytes at
+// offset, which starts at a chunk boundary. Its chunks are split the way
+// BLAKE3 does, with the largest power of two number of chunks that leaves
+// at least one byte on the right going left.
+func blake3Subtree(r io.ReaderAt, offset, size, segment int64, slots chan struct{}, wrap func(io.Reader) io.Reader) (blake3Output, error) {
+	if size <= segment || size <= blake3ChunkLen {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		h := newBlake3At(uint64(offset / blake3ChunkLen))
+		if _, err := io.Copy(h, wrap(io.NewSectionReader(r, offset, size))); err != nil {
+			return blake3Output{}, err
+		}
+		return h.output(), nil
+	}
+	left := int64(blake3ChunkLen) << (bits.Len64(uint64((size-1)/blake3ChunkLen)) - 1)
+	var wg sync.WaitGroup
+	var leftOut blake3Output
+	var leftErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		leftOut, leftErr = blake3Subtree(r, offset, left, segment, slots, wrap)
+	}()
+	rightOut, err := blake3Subtree(r, offset+left, size-left, segment, slots, wrap)
+	wg.Wait()
+	if leftErr != nil {
+		return blake3Output{}, leftErr
+	}
+	if err != nil {
+		return blake3Output{}, err
+	}
+	return blake3ParentOutput(leftOut.chainingValue(), rightOut.chainingValue()), nil
+}
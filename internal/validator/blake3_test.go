@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// blake3Vectors are the hashes of inputs of bytes counting up modulo 251, as
+// in the official test vectors.
+var blake3Vectors = []struct {
+	size int
+	hash string
+}{
+	{0, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+	{1, "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213"},
+	{63, "e9bc37a594daad83be9470df7f7b3798297c3d834ce80ba85d6e207627b7db7b"},
+	{64, "4eed7141ea4a5cd4b788606bd23f46e212af9cacebacdc7d1f4c6dc7f2511b98"},
+	{65, "de1e5fa0be70df6d2be8fffd0e99ceaa8eb6e8c93a63f2d8d1c30ecb6b263dee"},
+	{1023, "10108970eeda3eb932baac1428c7a2163b0e924c9a9e25b35bba72b28f70bd11"},
+	{1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+	{1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+	{2048, "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a"},
+	{2049, "5f4d72f40d7a5f82b15ca2b2e44b1de3c2ef86c426c95c1af0b6879522563030"},
+	{3072, "b98cb0ff3623be03326b373de6b9095218513e64f1ee2edd2525c7ad1e5cffd2"},
+	{3073, "7124b49501012f81cc7f11ca069ec9226cecb8a2c850cfe644e327d22d3e1cd3"},
+	{4097, "9b4052b38f1c5fc8b1f9ff7ac7b27cd242487b3d890d15c96a1c25b8aa0fb995"},
+	{8193, "bab6c09cb8ce8cf459261398d2e7aef35700bf488116ceb94a36d0f5f1b7bc3b"},
+	{16384, "f875d6646de28985646f34ee13be9a576fd515f76b5b0a26bb324735041ddde4"},
+	{31744, "62b6960e1a44bcc1eb1a611a8d6235b6b4b78f32e7abc4fb4c6cdcce94895c47"},
+	{102400, "bc3e3d41a1146b069abffad3c0d44860cf664390afce4d9661f7902e7943e085"},
+	{5<<20 + 123, "a0dc8c48f59eb0ec7cda7bb828c5edc2bde44bb5c57cb58c25e209961cfc9948"},
+}
+
+func blake3Input(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestBlake3(t *testing.T) {
+	for _, v := range blake3Vectors {
+		data := blake3Input(v.size)
+		h := newBlake3()
+		// Odd writes cross the block and chunk boundaries.
+		for rest := data; len(rest) > 0; {
+			n := min(len(rest), 1000)
+			h.Write(rest[:n])
+			rest = rest[n:]
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != v.hash {
+			t.Errorf("BLAKE3 of %d bytes = %s, want %s", v.size, got, v.hash)
+		}
+	}
+}
+
+func TestBlake3Parallel(t *testing.T) {
+	defer func(segment int64) { parallelSegment = segment }(parallelSegment)
+	parallelSegment = 3 * blake3ChunkLen
+	for _, v := range blake3Vectors {
+		for _, workers := range []int{1, 3, 8} {
+			sum, err := blake3Parallel(bytes.NewReader(blake3Input(v.size)), int64(v.size), workers, func(r io.Reader) io.Reader { return r })
+			if err != nil {
+				t.Fatalf("blake3Parallel failed: %v", err)
+			}
+			if got := hex.EncodeToString(sum); got != v.hash {
+				t.Errorf("BLAKE3 of %d bytes with %d workers = %s, want %s", v.size, workers, got, v.hash)
+			}
+		}
+	}
+}
+
+// benchmarkLargeFileBlake3 hashes a single large file with BLAKE3, in
+// parallel from parallelSize bytes. Compare with BenchmarkLargeFileSerial for
+// the SHA256 hash of the same file.
+func benchmarkLargeFileBlake3(b *testing.B, parallelSize int64) {
+	const size = 256 << 20
+	filePath := writeTestData(b, size)
+	opts := Options{Workers: runtime.NumCPU(), ParallelSize: parallelSize}
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := VerifyFile(filePath, strings.Repeat("0", 64), "blake3", opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLargeFileBlake3Serial(b *testing.B) {
+	benchmarkLargeFileBlake3(b, 0)
+}
+
+func BenchmarkLargeFileBlake3Parallel(b *testing.B) {
+	benchmarkLargeFileBlake3(b, 1)
+}
@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"context"
+	"sort"
+)
+
+// budget holds back the files of a run with Options.ByteBudget until all of
+// them have been found, so that the smallest can be checked first.
+type budget struct {
+	entries []fileEntry
+}
+
+// newBudget returns the budget of the run, or nil without Options.ByteBudget.
+func (opts Options) newBudget() *budget {
+	if opts.ByteBudget <= 0 {
+		return nil
+	}
+	return &budget{}
+}
+
+func (b *budget) add(entry fileEntry) {
+	b.entries = append(b.entries, entry)
+}
+
+// send hands the files to the workers in ascending order of size for as long
+// as their sizes add up to no more than the budget. The files left over are
+// counted in Result.SkippedByBudget, marking the result as stopped early.
+// Excluded files are handed over as they are, to be skipped by the workers
+// without taking up the budget.
+func (b *budget) send(ctx context.Context, fileChan chan<- []fileEntry, result *Result, opts Options) {
+	sort.SliceStable(b.entries, func(i, j int) bool {
+		return b.entries[i].info.Size() < b.entries[j].info.Size()
+	})
+	var used, total int64
+	for _, entry := range b.entries {
+		if ctx.Err() != nil {
+			return
+		}
+		size := entry.info.Size()
+		if opts.Exclude != nil && opts.Exclude.MatchString(entry.path) {
+			fileChan <- []fileEntry{entry}
+			continue
+		}
+		total += size
+		if used+size > opts.ByteBudget {
+			result.mu.Lock()
+			result.SkippedByBudget++
+			result.SkippedBudgetBytes += size
+			result.StoppedEarly = true
+			result.mu.Unlock()
+			opts.Progress.addDone(size)
+			continue
+		}
+		used += size
+		fileChan <- []fileEntry{entry}
+	}
+	result.mu.Lock()
+	result.BudgetCoverage = 1
+	if total > 0 {
+		result.BudgetCoverage = float64(used) / float64(total)
+	}
+	result.mu.Unlock()
+}
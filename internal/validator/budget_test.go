@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestProcessFolderByteBudget(t *testing.T) {
+	dir := t.TempDir()
+	for _, size := range []int{400, 100, 300, 200} {
+		data := make([]byte, size)
+		sum := sha256.Sum256(data)
+		if err := os.WriteFile(filepath.Join(dir, hex.EncodeToString(sum[:])), data, 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "skip.me"), make([]byte, 50), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	opts := Options{Workers: 2, ByteBudget: 650, Exclude: regexp.MustCompile(`\.me$`)}
+	result, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	// 100, 200 and 300 bytes fit, the excluded file does not count.
+	if result.IntactFiles != 3 || result.IntactBytes != 600 || result.SkippedByBudget != 1 || result.SkippedBudgetBytes != 400 {
+		t.Errorf("Expected the 3 smallest files to be checked and 400 bytes skipped, got %d intact (%d bytes) and %d skipped (%d bytes)",
+			result.IntactFiles, result.IntactBytes, result.SkippedByBudget, result.SkippedBudgetBytes)
+	}
+	if !result.StoppedEarly || result.BudgetCoverage != 0.6 {
+		t.Errorf("Expected the result to be stopped early with a coverage of 0.6, got %v and %v", result.StoppedEarly, result.BudgetCoverage)
+	}
+
+	opts.ByteBudget = 1 << 20
+	result, err = ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 4 || result.StoppedEarly || result.BudgetCoverage != 1 {
+		t.Errorf("Expected every file to fit, got %d intact, stopped early %v and coverage %v", result.IntactFiles, result.StoppedEarly, result.BudgetCoverage)
+	}
+}
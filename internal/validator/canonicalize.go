@@ -0,0 +1,153 @@
+package validator
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Rename is a file that is, or would be, renamed to its content hash.
+type Rename struct {
+	FilePath string `json:"file_path"`
+	NewPath  string `json:"new_path"`
+}
+
+// CanonicalizeResult is the outcome of renaming the files of a folder to
+// their content hashes.
+type CanonicalizeResult struct {
+	FolderPath     string        `json:"folder_path"`
+	CanonicalFiles int           `json:"canonical_files"`
+	Renamed        []Rename      `json:"renamed"`
+	Collisions     []Rename      `json:"collisions"`
+	CorruptedFiles []string      `json:"corrupted_files"`
+	Errors         []ErroredFile `json:"errors"`
+	DryRun         bool          `json:"dry_run"`
+}
+
+// Canonicalize renames every file in the folder whose name is not a hash to
+// the SHA256 hash of its content, keeping the extension when keepExt is set.
+// Files already named after their hash are left alone, and so are files named
+// after a different hash: those are corrupted, and renaming them would hide
+// it. A rename whose target already exists, or which another file of the run
+// would also take, is reported as a collision and not performed. With dryRun,
+// the renames are reported but not performed.
+func Canonicalize(folderPath string, keepExt, dryRun bool, opts Options) (*CanonicalizeResult, error) {
+	result := &CanonicalizeResult{FolderPath: folderPath, DryRun: dryRun}
+	paths, err := renameCandidates(folderPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, errs := hashAll(paths, opts)
+	result.Errors = errs
+
+	planned := make(map[string]bool)
+	for _, path := range paths {
+		hash, ok := hashes[path]
+		if !ok {
+			continue
+		}
+		name := filepath.Base(path)
+		var ext string
+		if keepExt {
+			ext = filepath.Ext(name)
+			name = strings.TrimSuffix(name, ext)
+		}
+		if isValidSha256(strings.ToLower(name)) {
+			if strings.ToLower(name) == hash {
+				result.CanonicalFiles++
+			} else {
+				result.CorruptedFiles = append(result.CorruptedFiles, path)
+			}
+			continue
+		}
+
+		rename := Rename{FilePath: path, NewPath: filepath.Join(filepath.Dir(path), hash+ext)}
+		if _, err := os.Lstat(rename.NewPath); err == nil || planned[rename.NewPath] {
+			result.Collisions = append(result.Collisions, rename)
+			continue
+		}
+		planned[rename.NewPath] = true
+		if !dryRun {
+			if err := os.Rename(rename.FilePath, rename.NewPath); err != nil {
+				result.Errors = append(result.Errors, ErroredFile{FilePath: path, Error: err.Error()})
+				continue
+			}
+		}
+		result.Renamed = append(result.Renamed, rename)
+	}
+	return result, nil
+}
+
+// renameCandidates returns the regular files of the folder that are not
+// skipped or excluded, in the order of the walk.
+func renameCandidates(folderPath string, opts Options) ([]string, error) {
+	var paths []string
+	err := walkFolder(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := opts.skipHidden(folderPath, path, info); skip {
+			return err
+		}
+		if skip, err := opts.skipNested(folderPath, path, info); skip {
+			return err
+		}
+		if isLockFile(folderPath, path) {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if opts.Exclude != nil && opts.Exclude.MatchString(path) {
+			slog.Debug("skipping excluded file", "path", path)
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, walkError(folderPath, err)
+	}
+	return paths, nil
+}
+
+// hashAll hashes the files with opts.Workers workers. Files that cannot be
+// read are returned as errors, sorted by path, and left out of the map.
+func hashAll(paths []string, opts Options) (map[string]string, []ErroredFile) {
+	var mu sync.Mutex
+	hashes := make(map[string]string, len(paths))
+	var errs []ErroredFile
+
+	pathChan := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < max(opts.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range pathChan {
+				sum, err := hashFile(path, opts)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, ErroredFile{FilePath: path, Error: err.Error()})
+				} else {
+					hashes[path] = sum.hash
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range paths {
+		pathChan <- path
+	}
+	close(pathChan)
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].FilePath < errs[j].FilePath
+	})
+	return hashes, errs
+}
@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	otherHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	files := map[string]string{
+		"photo.jpg":          "test content",
+		"copy.jpg":           "test content",
+		hash + ".txt":        "test content",
+		otherHash + ".txt":   "test content",
+		otherHash[:10] + "x": "",
+	}
+	write := func(t *testing.T) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+		}
+		return dir
+	}
+
+	t.Run("KeepExt", func(t *testing.T) {
+		dir := write(t)
+		result, err := Canonicalize(dir, true, false, Options{Workers: 2})
+		if err != nil {
+			t.Fatalf("Canonicalize failed: %v", err)
+		}
+		if result.CanonicalFiles != 1 {
+			t.Errorf("Expected 1 canonical file, got %d", result.CanonicalFiles)
+		}
+		if len(result.CorruptedFiles) != 1 || result.CorruptedFiles[0] != filepath.Join(dir, otherHash+".txt") {
+			t.Errorf("Expected the file named after another hash to be corrupted, got %v", result.CorruptedFiles)
+		}
+		// copy.jpg is renamed first, so photo.jpg collides with it.
+		if len(result.Collisions) != 1 || result.Collisions[0].FilePath != filepath.Join(dir, "photo.jpg") {
+			t.Errorf("Expected photo.jpg to collide, got %v", result.Collisions)
+		}
+		if _, err := os.Stat(filepath.Join(dir, hash+".jpg")); err != nil {
+			t.Errorf("Expected copy.jpg to be renamed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "photo.jpg")); err != nil {
+			t.Errorf("Expected photo.jpg to be kept: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, otherHash)); err != nil {
+			t.Errorf("Expected the empty file to be renamed to the empty hash: %v", err)
+		}
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		dir := write(t)
+		result, err := Canonicalize(dir, false, true, Options{Workers: 2})
+		if err != nil {
+			t.Fatalf("Canonicalize failed: %v", err)
+		}
+		if len(result.Renamed) == 0 {
+			t.Errorf("Expected renames to be reported")
+		}
+		for _, rename := range result.Renamed {
+			if _, err := os.Stat(rename.FilePath); err != nil {
+				t.Errorf("Expected %s not to be renamed in a dry run: %v", rename.FilePath, err)
+			}
+		}
+	})
+}
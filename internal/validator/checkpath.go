@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Statuses of a PathCheck besides the file statuses.
+const (
+	StatusMissing    = "missing"
+	StatusErrored    = "errored"
+	StatusNotIndexed = "not_indexed"
+)
+
+// PathCheck is the outcome of checking a single file with CheckPath.
+type PathCheck struct {
+	FilePath string `json:"file_path"`
+	// Status is one of the file statuses, or StatusMissing, StatusErrored or
+	// StatusNotIndexed.
+	Status string `json:"status"`
+	// ActualHash is set for corrupted and ignored files.
+	ActualHash string `json:"actual_hash,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckPath checks a single file the way ProcessFolder checks the files of a
+// folder, against expectedHash, or when it is empty, against the hash given by
+// opts.HashSource or the file name. The hash source is asked for the file
+// relative to its own directory. Decrypt errors are reported as corrupted.
+func CheckPath(filePath, expectedHash string, opts Options) *PathCheck {
+	check := &PathCheck{FilePath: filePath}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		check.Status, check.Error = StatusErrored, err.Error()
+		if os.IsNotExist(err) {
+			check.Status = StatusMissing
+		}
+		return check
+	}
+	if !info.Mode().IsRegular() {
+		check.Status, check.Error = StatusErrored, "not a regular file"
+		return check
+	}
+
+	opts.RecordFiles, opts.Lists, opts.SizeHistogram = true, nil, false
+	opts.folder = filepath.Dir(filePath)
+	result := &Result{FolderPath: opts.folder}
+	if expectedHash != "" {
+		checkFile(filePath, strings.ToLower(expectedHash), info, result, opts)
+	} else {
+		validateFile(filePath, info, result, opts)
+	}
+
+	switch {
+	case len(result.ErroredFileList) > 0:
+		check.Status, check.Error = StatusErrored, result.ErroredFileList[0].Error
+	case len(result.PathErrorList) > 0:
+		check.Status, check.Error = StatusErrored, result.PathErrorList[0].Error
+	case len(result.DecryptErrorList) > 0:
+		check.Status, check.Error = StatusCorrupted, result.DecryptErrorList[0].Error
+	case result.NotIndexedFiles > 0:
+		check.Status = StatusNotIndexed
+	case len(result.Files) > 0:
+		check.Status = result.Files[0].Status
+	default:
+		// The deadline passed while the file was hashed.
+		check.Status, check.Error = StatusErrored, "deadline exceeded"
+	}
+	if len(result.CorruptedFileList) > 0 {
+		check.ActualHash = result.CorruptedFileList[0].ActualHash
+	} else if len(result.IgnoredFileList) > 0 {
+		check.ActualHash = result.IgnoredFileList[0].ActualHash
+	}
+	return check
+}
@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Chunk is a byte range of a file with the expected SHA256 hash of its content.
+type Chunk struct {
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+	Hash   string `json:"hash"`
+}
+
+// CorruptedChunk is a byte range whose content does not match its hash.
+type CorruptedChunk struct {
+	Offset       int64  `json:"offset"`
+	Length       int64  `json:"length"`
+	ExpectedHash string `json:"expected_hash"`
+	ActualHash   string `json:"actual_hash"`
+	// Reason is set when the range could not be read in full.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ChunkResult is the outcome of verifying the chunks of a file.
+type ChunkResult struct {
+	FilePath        string           `json:"file_path"`
+	TotalChunks     int              `json:"total_chunks"`
+	IntactChunks    int              `json:"intact_chunks"`
+	CorruptedChunks []CorruptedChunk `json:"corrupted_chunks"`
+}
+
+// VerifyChunks hashes every chunk of the file independently and reports the
+// ranges whose content does not match. The chunks are sorted by offset and
+// the file is read once from start to end, skipping the bytes between chunks.
+// Chunks must not overlap. A chunk that extends beyond the end of the file is
+// reported as corrupted.
+func VerifyChunks(filePath string, chunks []Chunk, opts Options) (*ChunkResult, error) {
+	chunks = append([]Chunk(nil), chunks...)
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Offset < chunks[j].Offset })
+	for i := 1; i < len(chunks); i++ {
+		if prev := chunks[i-1]; prev.Offset+prev.Length > chunks[i].Offset {
+			return nil, fmt.Errorf("chunks at offsets %d and %d overlap", prev.Offset, chunks[i].Offset)
+		}
+	}
+
+	var file io.ReadCloser
+	var err error
+	if opts.Source != nil {
+		file, err = opts.Source.Open(filePath)
+	} else if opts.LongPaths {
+		file, err = os.Open(longPath(filePath))
+	} else {
+		file, err = os.Open(filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Content types are not detected for parts of a file.
+	opts.DetectType = false
+	result := &ChunkResult{FilePath: filePath, TotalChunks: len(chunks)}
+	var pos int64
+	for _, chunk := range chunks {
+		if gap := chunk.Offset - pos; gap > 0 {
+			n, err := io.CopyN(io.Discard, file, gap)
+			pos += n
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+		}
+		// When the file ends before the chunk starts, nothing is left to hash.
+		var sum fileSum
+		if pos == chunk.Offset {
+			if sum, err = digest(io.LimitReader(file, chunk.Length), opts); err != nil {
+				return nil, err
+			}
+			pos += sum.size
+		}
+		switch {
+		case sum.size < chunk.Length:
+			result.CorruptedChunks = append(result.CorruptedChunks, CorruptedChunk{
+				Offset:       chunk.Offset,
+				Length:       chunk.Length,
+				ExpectedHash: chunk.Hash,
+				ActualHash:   sum.hash,
+				Reason:       fmt.Sprintf("file ends at byte %d", pos),
+			})
+		case sum.hash != chunk.Hash:
+			result.CorruptedChunks = append(result.CorruptedChunks, CorruptedChunk{
+				Offset:       chunk.Offset,
+				Length:       chunk.Length,
+				ExpectedHash: chunk.Hash,
+				ActualHash:   sum.hash,
+			})
+		default:
+			result.IntactChunks++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifyChunks(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	filePath := filepath.Join(t.TempDir(), "large")
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	chunks := []Chunk{
+		{Offset: 10, Length: 5, Hash: sha256Hex([]byte("XXXXX"))},
+		{Offset: 0, Length: 5, Hash: sha256Hex(data[0:5])},
+		{Offset: 5, Length: 5, Hash: sha256Hex(data[5:10])},
+		{Offset: 18, Length: 4, Hash: sha256Hex([]byte("ijkl"))},
+		{Offset: 30, Length: 1, Hash: sha256Hex([]byte("z"))},
+	}
+	result, err := VerifyChunks(filePath, chunks, Options{})
+	if err != nil {
+		t.Fatalf("VerifyChunks failed: %v", err)
+	}
+	if result.TotalChunks != 5 || result.IntactChunks != 2 {
+		t.Errorf("Expected 2 of 5 chunks to be intact, got %d of %d", result.IntactChunks, result.TotalChunks)
+	}
+	if len(result.CorruptedChunks) != 3 {
+		t.Fatalf("Expected 3 corrupted chunks, got %v", result.CorruptedChunks)
+	}
+	if got := result.CorruptedChunks[0]; got.Offset != 10 || got.ActualHash != sha256Hex(data[10:15]) || got.Reason != "" {
+		t.Errorf("Unexpected corrupted chunk %+v", got)
+	}
+	if got := result.CorruptedChunks[1]; got.Offset != 18 || got.ActualHash != sha256Hex(data[18:]) || got.Reason == "" {
+		t.Errorf("Expected the chunk at 18 to be reported as cut short, got %+v", got)
+	}
+	if got := result.CorruptedChunks[2]; got.Offset != 30 || got.Reason == "" {
+		t.Errorf("Expected the chunk past the end of the file to be reported, got %+v", got)
+	}
+
+	overlapping := []Chunk{{Offset: 0, Length: 6}, {Offset: 5, Length: 5}}
+	if _, err := VerifyChunks(filePath, overlapping, Options{}); err == nil {
+		t.Error("Expected an error for overlapping chunks")
+	}
+}
+
+func TestVerifyPacks(t *testing.T) {
+	dir := t.TempDir()
+	intact := filepath.Join(dir, "intact")
+	corrupted := filepath.Join(dir, "corrupted")
+	overlapping := filepath.Join(dir, "overlapping")
+	for _, path := range []string{intact, corrupted, overlapping} {
+		if err := os.WriteFile(path, []byte("aaaabbbb"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	a, b := sha256Hex([]byte("aaaa")), sha256Hex([]byte("bbbb"))
+
+	result := VerifyPacks(map[string][]Chunk{
+		intact:                        {{Offset: 0, Length: 4, Hash: a}, {Offset: 4, Length: 4, Hash: b}},
+		corrupted:                     {{Offset: 0, Length: 4, Hash: a}, {Offset: 4, Length: 4, Hash: a}},
+		overlapping:                   {{Offset: 0, Length: 5, Hash: a}, {Offset: 4, Length: 4, Hash: b}},
+		filepath.Join(dir, "missing"): {{Offset: 0, Length: 4, Hash: a}},
+	}, Options{Workers: 2})
+	if result.TotalPacks != 4 || result.IntactPacks != 1 || result.TotalChunks != 7 || result.IntactChunks != 3 {
+		t.Errorf("Unexpected counts %+v", result)
+	}
+	if len(result.CorruptedPacks) != 1 || result.CorruptedPacks[0].FilePath != corrupted || result.CorruptedPacks[0].CorruptedChunks[0].Offset != 4 {
+		t.Errorf("Expected the second chunk of %s to be corrupted, got %v", corrupted, result.CorruptedPacks)
+	}
+	if len(result.MissingPacks) != 1 || len(result.Errors) != 1 || result.Errors[0].FilePath != overlapping {
+		t.Errorf("Expected a missing pack and an error for %s, got %v and %v", overlapping, result.MissingPacks, result.Errors)
+	}
+}
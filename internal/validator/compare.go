@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// CompareResult lists how the files of two folders differ by content. Paths
+// are relative to the folders.
+type CompareResult struct {
+	FolderA        string        `json:"folder_a"`
+	FolderB        string        `json:"folder_b"`
+	IdenticalFiles int           `json:"identical_files"`
+	DifferentFiles []string      `json:"different_files"`
+	OnlyInA        []string      `json:"only_in_a"`
+	OnlyInB        []string      `json:"only_in_b"`
+	Errors         []ErroredFile `json:"errors"`
+}
+
+// Identical reports whether both folders hold the same files with the same content.
+func (r *CompareResult) Identical() bool {
+	return len(r.DifferentFiles) == 0 && len(r.OnlyInA) == 0 && len(r.OnlyInB) == 0 && len(r.Errors) == 0
+}
+
+// Compare checks whether folderB mirrors folderA. Files present in both are
+// different when their sizes differ, and are hashed otherwise, with the files
+// of both folders shared among opts.Workers workers.
+func Compare(folderA, folderB string, opts Options) (*CompareResult, error) {
+	result := &CompareResult{FolderA: folderA, FolderB: folderB}
+
+	filesA, err := listFiles(folderA, opts)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := listFiles(folderB, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var common []string
+	var toHash []string
+	for rel, infoA := range filesA {
+		infoB, ok := filesB[rel]
+		if !ok {
+			result.OnlyInA = append(result.OnlyInA, rel)
+			continue
+		}
+		if infoA.Size() != infoB.Size() {
+			result.DifferentFiles = append(result.DifferentFiles, rel)
+			continue
+		}
+		common = append(common, rel)
+		toHash = append(toHash, filepath.Join(folderA, rel), filepath.Join(folderB, rel))
+	}
+	for rel := range filesB {
+		if _, ok := filesA[rel]; !ok {
+			result.OnlyInB = append(result.OnlyInB, rel)
+		}
+	}
+
+	hashes, errs := hashAll(toHash, opts)
+	result.Errors = errs
+	for _, rel := range common {
+		hashA, okA := hashes[filepath.Join(folderA, rel)]
+		hashB, okB := hashes[filepath.Join(folderB, rel)]
+		if !okA || !okB {
+			continue
+		}
+		if hashA == hashB {
+			result.IdenticalFiles++
+		} else {
+			result.DifferentFiles = append(result.DifferentFiles, rel)
+		}
+	}
+
+	sort.Strings(result.DifferentFiles)
+	sort.Strings(result.OnlyInA)
+	sort.Strings(result.OnlyInB)
+	return result, nil
+}
+
+// listFiles returns the regular files of the folder that are not excluded,
+// keyed by their path relative to the folder.
+func listFiles(folderPath string, opts Options) (map[string]os.FileInfo, error) {
+	files := make(map[string]os.FileInfo)
+	err := walkFolder(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := opts.skipHidden(folderPath, path, info); skip {
+			return err
+		}
+		if skip, err := opts.skipNested(folderPath, path, info); skip {
+			return err
+		}
+		if isLockFile(folderPath, path) {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if opts.Exclude != nil && opts.Exclude.MatchString(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(folderPath, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = info
+		return nil
+	})
+	if err != nil {
+		return nil, walkError(folderPath, err)
+	}
+	return files, nil
+}
@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	write(a, "same/file", "content")
+	write(b, "same/file", "content")
+	write(a, "changed", "content")
+	write(b, "changed", "CONTENT")
+	write(a, "resized", "content")
+	write(b, "resized", "more content")
+	write(a, "only-a", "")
+	write(b, "only-b", "")
+
+	result, err := Compare(a, b, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if result.IdenticalFiles != 1 {
+		t.Errorf("Expected 1 identical file, got %d", result.IdenticalFiles)
+	}
+	if want := []string{"changed", "resized"}; !reflect.DeepEqual(result.DifferentFiles, want) {
+		t.Errorf("Expected different files %v, got %v", want, result.DifferentFiles)
+	}
+	if !reflect.DeepEqual(result.OnlyInA, []string{"only-a"}) || !reflect.DeepEqual(result.OnlyInB, []string{"only-b"}) {
+		t.Errorf("Unexpected files only in one folder: %v and %v", result.OnlyInA, result.OnlyInB)
+	}
+	if result.Identical() {
+		t.Error("Expected the folders not to be identical")
+	}
+}
@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+	"sync"
+)
+
+// fingerprintLen is the number of bytes at the start and at the end of a file
+// that its fingerprint is taken from.
+const fingerprintLen = 1 << 10
+
+// contentKey is the fingerprint of a file: its size and the hash of its first
+// and last fingerprintLen bytes.
+type contentKey struct {
+	size int64
+	ends [sha256.Size]byte
+}
+
+type contentEntry struct {
+	done chan struct{}
+	sum  fileSum
+	err  error
+}
+
+// contentCache shares the hash of a file with the later files of the same
+// fingerprint, which are taken to have the same content without being read
+// in full. Files that differ only between their first and last
+// fingerprintLen bytes are wrongly taken to be identical.
+type contentCache struct {
+	mu      sync.Mutex
+	entries map[contentKey]*contentEntry
+}
+
+func newContentCache() *contentCache {
+	return &contentCache{entries: make(map[contentKey]*contentEntry)}
+}
+
+// hash returns the hash of the file, computed with hashFn unless a file of
+// the same fingerprint has been hashed before, which is reported as cached.
+// Files no larger than their fingerprint and remote files are always hashed.
+func (c *contentCache) hash(filePath string, info os.FileInfo, opts Options, hashFn func() (fileSum, error)) (sum fileSum, cached bool, err error) {
+	if info == nil || info.Size() <= 2*fingerprintLen || opts.Source != nil {
+		sum, err = hashFn()
+		return sum, false, err
+	}
+	key, err := fingerprint(filePath, info.Size(), opts)
+	if err != nil {
+		return fileSum{}, false, err
+	}
+
+	c.mu.Lock()
+	entry, seen := c.entries[key]
+	if !seen {
+		entry = &contentEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if seen {
+		<-entry.done
+		// A file that could not be hashed says nothing about this one.
+		if entry.err != nil {
+			sum, err = hashFn()
+			return sum, false, err
+		}
+		sum = entry.sum
+		sum.size = 0
+		return sum, true, nil
+	}
+	entry.sum, entry.err = hashFn()
+	if entry.err != nil {
+		// Later files of the fingerprint are hashed themselves.
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.sum, false, entry.err
+}
+
+// fingerprint reads the first and last fingerprintLen bytes of the file.
+func fingerprint(filePath string, size int64, opts Options) (contentKey, error) {
+	if opts.LongPaths {
+		filePath = longPath(filePath)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return contentKey{}, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 2*fingerprintLen)
+	if _, err := io.ReadFull(file, buf[:fingerprintLen]); err != nil {
+		return contentKey{}, err
+	}
+	if _, err := file.ReadAt(buf[fingerprintLen:], size-fingerprintLen); err != nil {
+		return contentKey{}, err
+	}
+	return contentKey{size: size, ends: sha256.Sum256(buf)}, nil
+}
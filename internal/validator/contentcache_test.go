@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderContentCache(t *testing.T) {
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("test content "), 1000)
+	// The same ends with another middle have the same fingerprint.
+	changed := bytes.Clone(content)
+	changed[len(changed)/2] ^= 1
+	hashOf := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+	for path, data := range map[string][]byte{
+		filepath.Join("a", hashOf(content)):                                content,
+		filepath.Join("b", hashOf(content)):                                content,
+		filepath.Join("c", hashOf(changed)):                                changed,
+		filepath.Join("d", hashOf(content)):                                changed,
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72": []byte("test content"),
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 1, ContentCache: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.ContentCacheHits != 3 || result.ContentCacheBytes != 3*int64(len(content)) {
+		t.Errorf("Expected 3 cache hits of %d bytes, got %d of %d bytes", 3*len(content), result.ContentCacheHits, result.ContentCacheBytes)
+	}
+	if result.HashedBytes != int64(len(content)+len("test content")) {
+		t.Errorf("Expected the content to be hashed once, got %d bytes hashed", result.HashedBytes)
+	}
+	// This is the risk of the cache, both ways: the files changed in the
+	// middle are taken for the first one, so the intact one in c is reported
+	// corrupted and the damaged copy in d is reported intact.
+	if result.IntactFiles != 4 || result.CorruptedFiles != 1 || filepath.Base(filepath.Dir(result.CorruptedFileList[0].FilePath)) != "c" {
+		t.Errorf("Expected the copies to be intact and the changed file to be taken for them, got %+v", result)
+	}
+
+	result, err = ProcessFolder(dir, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.ContentCacheHits != 0 || result.IntactFiles != 4 || result.CorruptedFiles != 1 || filepath.Base(filepath.Dir(result.CorruptedFileList[0].FilePath)) != "d" {
+		t.Errorf("Expected every file to be hashed without the cache, got %+v", result)
+	}
+}
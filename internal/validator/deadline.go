@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// deadlineReader fails reads once the deadline has passed, so that a large
+// file being hashed does not hold the run past it.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time
+}
+
+func (dr *deadlineReader) Read(p []byte) (int, error) {
+	if time.Now().After(dr.deadline) {
+		return 0, context.DeadlineExceeded
+	}
+	return dr.r.Read(p)
+}
+
+// withDeadline wraps r so that reads fail after the deadline, if there is one.
+func withDeadline(r io.Reader, deadline time.Time) io.Reader {
+	if deadline.IsZero() {
+		return r
+	}
+	return &deadlineReader{r: r, deadline: deadline}
+}
+
+// cancelReader fails reads once its context is done, so that a large file
+// being hashed does not hold up an interrupted run.
+type cancelReader struct {
+	r   io.Reader
+	ctx context.Context
+}
+
+func (cr *cancelReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// withCancel wraps r so that reads fail once ctx is done, if there is a ctx.
+func withCancel(r io.Reader, ctx context.Context) io.Reader {
+	if ctx == nil {
+		return r
+	}
+	return &cancelReader{r: r, ctx: ctx}
+}
+
+// runContext returns the context of a run, which is done once opts.Deadline
+// has passed, opts.Context is done or the returned cancel function is called.
+func runContext(opts Options) (context.Context, context.CancelFunc) {
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	if opts.Deadline.IsZero() {
+		return context.WithCancel(parent)
+	}
+	return context.WithDeadline(parent, opts.Deadline)
+}
+
+// finishRun marks the result as stopped early when the deadline of ctx
+// passed, or the run was interrupted, before all files were checked.
+func (r *Result) finishRun(ctx context.Context) {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.StoppedEarly = true
+		r.DeadlineExceeded = true
+	case context.Canceled:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.StoppedEarly = true
+	}
+}
+
+// unstart takes back the counting of a file whose hashing was interrupted by
+// the deadline or by the end of opts.Context. The file is neither intact nor corrupted; it was not checked.
+func (r *Result) unstart(info os.FileInfo, opts Options) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.TotalFiles--
+	if opts.SizeHistogram && info != nil {
+		r.SizeHistogram[sizeBucketIndex(info.Size())].Count--
+	}
+}
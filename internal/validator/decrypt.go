@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ErrDecrypt is returned when a file fails authentication while it is
+// decrypted, because its content or the key is wrong.
+var ErrDecrypt = errors.New("decryption failed: message authentication failed")
+
+// ErrDecryptTooLarge is returned instead of reading an encrypted file larger
+// than the decrypt limit into memory. Such files count as decrypt errors.
+var ErrDecryptTooLarge = errors.New("file too large to decrypt")
+
+// DefaultDecryptLimit is the size of the largest encrypted file that is
+// decrypted when Options.DecryptLimit is not set. A file can only be
+// authenticated as a whole, so it is decrypted in memory, and every worker
+// may hold one file of up to this size.
+const DefaultDecryptLimit = 1 << 30
+
+// decryptLimit returns opts.DecryptLimit, or DefaultDecryptLimit when it is not set.
+func (opts Options) decryptLimit() int64 {
+	if opts.DecryptLimit > 0 {
+		return opts.DecryptLimit
+	}
+	return DefaultDecryptLimit
+}
+
+// checkDecryptSize returns ErrDecryptTooLarge when a file of the size is too
+// large to be decrypted.
+func checkDecryptSize(size int64, opts Options) error {
+	if size > opts.decryptLimit() {
+		return fmt.Errorf("%w: %d bytes, more than the limit of %d", ErrDecryptTooLarge, size, opts.decryptLimit())
+	}
+	return nil
+}
+
+// NewAEAD returns the cipher used to decrypt files with Options.Decrypt. The
+// name is aes-gcm, with a 16, 24 or 32 byte key, or chacha20poly1305, with a
+// 32 byte key.
+func NewAEAD(name string, key []byte) (cipher.AEAD, error) {
+	switch name {
+	case "aes-gcm":
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case "chacha20poly1305":
+		return chacha20poly1305.New(key)
+	default:
+		return nil, fmt.Errorf("%w %q, expected aes-gcm or chacha20poly1305", ErrUnsupportedAlgo, name)
+	}
+}
+
+// decryptDigest reads the whole encrypted file from r, decrypts it and
+// hashes the plaintext. The file is the nonce followed by the sealed
+// ciphertext, as written by aead.Seal(nonce, nonce, plaintext, nil). No
+// more than the decrypt limit is read into memory.
+func decryptDigest(r io.Reader, opts Options) (fileSum, error) {
+	limit := opts.decryptLimit()
+	sealed, err := io.ReadAll(io.LimitReader(withCancel(withDeadline(limitReader(r, opts.Limiter), opts.Deadline), opts.Context), limit+1))
+	if err != nil {
+		return fileSum{}, err
+	}
+	if err := checkDecryptSize(int64(len(sealed)), opts); err != nil {
+		return fileSum{}, err
+	}
+	aead := opts.Decrypt
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return fileSum{}, fmt.Errorf("%w: file is shorter than nonce and tag", ErrDecrypt)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(ciphertext[:0], nonce, ciphertext, nil)
+	if err != nil {
+		return fileSum{}, ErrDecrypt
+	}
+	// The encrypted file was already throttled as it was read.
+	opts.Limiter = nil
+	return digest(bytes.NewReader(plaintext), opts)
+}
+
+func (r *Result) addDecryptError(filePath string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.DecryptErrors++
+	addToList(r, &r.DecryptErrorList, "decrypt_error_list", ErroredFile{FilePath: filePath, Error: err.Error()})
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"os"
+	"sync"
+)
+
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+type inodeEntry struct {
+	done chan struct{}
+	path string
+	sum  fileSum
+	err  error
+}
+
+// inodeCache hashes each inode once and shares the hash with its other hard links.
+type inodeCache struct {
+	mu      sync.Mutex
+	entries map[inodeKey]*inodeEntry
+}
+
+func newInodeCache() *inodeCache {
+	return &inodeCache{entries: make(map[inodeKey]*inodeEntry)}
+}
+
+// hash returns the hash of the file, computed with hashFn unless another link
+// to the same inode has been hashed before. In that case the path of that
+// link is returned as original.
+func (c *inodeCache) hash(filePath string, info os.FileInfo, hashFn func() (fileSum, error)) (sum fileSum, original string, err error) {
+	key, ok := fileInode(info)
+	if !ok {
+		sum, err = hashFn()
+		return sum, "", err
+	}
+
+	c.mu.Lock()
+	entry, seen := c.entries[key]
+	if !seen {
+		entry = &inodeEntry{done: make(chan struct{}), path: filePath}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if seen {
+		<-entry.done
+		// Nothing was read for this link.
+		sum = entry.sum
+		sum.size = 0
+		return sum, entry.path, entry.err
+	}
+	entry.sum, entry.err = hashFn()
+	close(entry.done)
+	return entry.sum, "", entry.err
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package validator
+
+import "os"
+
+func fileInode(info os.FileInfo) (inodeKey, bool) {
+	return inodeKey{}, false
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderDedupInodes(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	original := filepath.Join(dir, "a", hash)
+	link := filepath.Join(dir, "b", hash)
+	for _, sub := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(original, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.Link(original, link); err != nil {
+		t.Skipf("Hard links are not supported: %v", err)
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2, DedupInodes: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 2 {
+		t.Errorf("Expected 2 intact files, got %d", result.IntactFiles)
+	}
+	if result.DedupedFiles != 1 || len(result.HardLinks) != 1 {
+		t.Fatalf("Expected 1 deduplicated file, got %d", result.DedupedFiles)
+	}
+	if result.HashedBytes != int64(len("test content")) {
+		t.Errorf("Expected the content to be hashed once, got %d bytes hashed", result.HashedBytes)
+	}
+}
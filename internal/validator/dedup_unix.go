@@ -0,0 +1,19 @@
+//go:build unix
+
+package validator
+
+import (
+	"os"
+	"syscall"
+)
+
+func fileInode(info os.FileInfo) (inodeKey, bool) {
+	if info == nil {
+		return inodeKey{}, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return inodeKey{}, false
+	}
+	return inodeKey{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// dirSlots limits the number of workers checking files below each of the
+// top-level directories of Options.DirWorkers.
+type dirSlots struct {
+	root  string
+	slots map[string]chan struct{}
+}
+
+// newDirSlots returns the limits for the folder, or nil without
+// Options.DirWorkers.
+func (opts Options) newDirSlots(root string) *dirSlots {
+	if len(opts.DirWorkers) == 0 {
+		return nil
+	}
+	d := &dirSlots{root: root, slots: make(map[string]chan struct{}, len(opts.DirWorkers))}
+	for dir, n := range opts.DirWorkers {
+		d.slots[filepath.Clean(dir)] = make(chan struct{}, max(n, 1))
+	}
+	return d
+}
+
+// acquire waits for a slot of the top-level directory the file is in, and
+// returns the function giving it back. Files of other directories, and files
+// directly in the root, take no slot.
+func (d *dirSlots) acquire(path string) (release func()) {
+	if d == nil {
+		return func() {}
+	}
+	rel, err := filepath.Rel(d.root, path)
+	if err != nil {
+		return func() {}
+	}
+	top, _, nested := strings.Cut(rel, string(filepath.Separator))
+	slots, ok := d.slots[top]
+	if !nested || !ok {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
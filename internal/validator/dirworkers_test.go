@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyVolume records the largest number of files of each directory
+// open at the same time.
+type concurrencyVolume struct {
+	mu      sync.Mutex
+	open    map[string]int
+	maxOpen map[string]int
+}
+
+func (v *concurrencyVolume) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (v *concurrencyVolume) Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Base(filepath.Dir(path))
+	v.mu.Lock()
+	v.open[dir]++
+	v.maxOpen[dir] = max(v.maxOpen[dir], v.open[dir])
+	v.mu.Unlock()
+	// Holding the file open lets the other workers pile up.
+	time.Sleep(2 * time.Millisecond)
+	return &concurrencyFile{File: file, close: func() {
+		v.mu.Lock()
+		v.open[dir]--
+		v.mu.Unlock()
+	}}, nil
+}
+
+type concurrencyFile struct {
+	*os.File
+	close func()
+}
+
+func (f *concurrencyFile) Close() error {
+	f.close()
+	return f.File.Close()
+}
+
+func TestProcessFolderDirWorkers(t *testing.T) {
+	dir := writeLocalityTree(t)
+	volume := &concurrencyVolume{open: make(map[string]int), maxOpen: make(map[string]int)}
+	result, err := ProcessFolder(dir, Options{Workers: 8, DirWorkers: map[string]int{"dir0": 1, "dir1": 2}, Source: volume})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 32 {
+		t.Errorf("Expected 32 intact files, got %d", result.IntactFiles)
+	}
+	if volume.maxOpen["dir0"] != 1 || volume.maxOpen["dir1"] > 2 {
+		t.Errorf("Expected at most 1 and 2 files open in dir0 and dir1, got %d and %d", volume.maxOpen["dir0"], volume.maxOpen["dir1"])
+	}
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// emptyDirTracker finds the directories of a walk that contain no files,
+// directly or below them. It relies on the walk visiting a directory before
+// its contents and finishing them before its next sibling.
+type emptyDirTracker struct {
+	stack []dirCount
+	empty []string
+}
+
+type dirCount struct {
+	path     string
+	files    int
+	excluded bool
+}
+
+// visit records that the walk reached path, leaving all directories that do
+// not contain it.
+func (t *emptyDirTracker) visit(path string, isDir, excluded bool) {
+	for len(t.stack) > 0 && !within(t.stack[len(t.stack)-1].path, path) {
+		t.pop()
+	}
+	if isDir {
+		t.stack = append(t.stack, dirCount{path: path, excluded: excluded})
+	}
+}
+
+// addFile counts a file in the directory visited last.
+func (t *emptyDirTracker) addFile() {
+	if len(t.stack) > 0 {
+		t.stack[len(t.stack)-1].files++
+	}
+}
+
+// finish leaves the remaining directories and returns the empty ones in sorted order.
+func (t *emptyDirTracker) finish() []string {
+	for len(t.stack) > 0 {
+		t.pop()
+	}
+	sort.Strings(t.empty)
+	return t.empty
+}
+
+func (t *emptyDirTracker) pop() {
+	dir := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	if dir.files == 0 {
+		if !dir.excluded {
+			t.empty = append(t.empty, dir.path)
+		}
+	} else if len(t.stack) > 0 {
+		t.stack[len(t.stack)-1].files += dir.files
+	}
+}
+
+// within reports whether path is dir or lies below it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Errors returned, wrapped with details, by the functions of this package,
+// for callers to tell them apart with errors.Is. ErrDecrypt,
+// ErrDecryptTooLarge and ErrLocked are defined next to the code returning
+// them.
+var (
+	// ErrWalk is returned when the walk of a folder fails, for example
+	// because the folder does not exist. The error of the walk is wrapped
+	// too, so errors.Is(err, fs.ErrNotExist) works as well.
+	ErrWalk = errors.New("cannot walk folder")
+	// ErrInvalidPattern is returned for a regular expression that does not
+	// compile, or a name pattern without a group named "hash".
+	ErrInvalidPattern = errors.New("invalid pattern")
+	// ErrUnsupportedAlgo is returned for a hash algorithm or cipher that is
+	// not supported.
+	ErrUnsupportedAlgo = errors.New("unsupported algorithm")
+)
+
+// walkError wraps the error of the walk of the folder.
+func walkError(folderPath string, err error) error {
+	return fmt.Errorf("%w %s: %w", ErrWalk, folderPath, err)
+}
+
+// CompileNamePattern compiles a pattern for Options.NamePattern, which must
+// have a group named "hash".
+func CompileNamePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidPattern, pattern, err)
+	}
+	if re.SubexpIndex("hash") < 0 {
+		return nil, fmt.Errorf("%w %q: no named group \"hash\"", ErrInvalidPattern, pattern)
+	}
+	return re, nil
+}
+
+// CompileExcludes compiles the patterns into one Options.Exclude expression
+// matching any of them, or returns nil when there are none. Each pattern is
+// compiled on its own first, so that the error names the invalid one.
+func CompileExcludes(patterns []string) (*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidPattern, pattern, err)
+		}
+	}
+	return regexp.Compile("(" + strings.Join(patterns, ")|(") + ")")
+}
@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err := ProcessFolder(missing, Options{Workers: 1}); !errors.Is(err, ErrWalk) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected ErrWalk wrapping fs.ErrNotExist from ProcessFolder, got %v", err)
+	}
+	if _, err := Canonicalize(missing, false, true, Options{Workers: 1}); !errors.Is(err, ErrWalk) {
+		t.Errorf("Expected ErrWalk from Canonicalize, got %v", err)
+	}
+	if _, err := Compare(missing, missing, Options{Workers: 1}); !errors.Is(err, ErrWalk) {
+		t.Errorf("Expected ErrWalk from Compare, got %v", err)
+	}
+	if err := GenerateManifest(missing, nil, Options{Workers: 1}, func(ManifestEntry) error { return nil }); !errors.Is(err, ErrWalk) {
+		t.Errorf("Expected ErrWalk from GenerateManifest, got %v", err)
+	}
+
+	for _, pattern := range []string{"(", `^(?P<name>[0-9a-f]{64})$`} {
+		if _, err := CompileNamePattern(pattern); !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("Expected ErrInvalidPattern for the name pattern %q, got %v", pattern, err)
+		}
+	}
+	if re, err := CompileNamePattern(`^(?P<hash>[0-9a-f]{64})\.bin$`); err != nil || re == nil {
+		t.Errorf("CompileNamePattern failed: %v", err)
+	}
+	if _, err := CompileExcludes([]string{`\.tmp$`, "[a-"}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Expected ErrInvalidPattern for an exclude pattern, got %v", err)
+	}
+	if re, err := CompileExcludes([]string{`\.tmp$`, `^lost\+found`}); err != nil || !re.MatchString("lost+found") {
+		t.Errorf("Expected the excludes to match any of the patterns, got %v", err)
+	}
+	if re, err := CompileExcludes(nil); re != nil || err != nil {
+		t.Errorf("Expected nil for no excludes, got %v and %v", re, err)
+	}
+
+	if _, err := VerifyFile(missing, "00", "crc32", Options{}); !errors.Is(err, ErrUnsupportedAlgo) {
+		t.Errorf("Expected ErrUnsupportedAlgo from VerifyFile, got %v", err)
+	}
+	if _, err := NewAEAD("rot13", nil); !errors.Is(err, ErrUnsupportedAlgo) {
+		t.Errorf("Expected ErrUnsupportedAlgo from NewAEAD, got %v", err)
+	}
+}
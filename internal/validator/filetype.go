@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// sniffType detects the content type from the first bytes of a file. On top
+// of http.DetectContentType, text starting with { or [ is taken for JSON, so
+// that metadata blobs can be told apart from other text.
+func sniffType(head []byte) string {
+	contentType := http.DetectContentType(head)
+	if strings.HasPrefix(contentType, "text/plain") {
+		if trimmed := bytes.TrimLeft(head, " \t\r\n"); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			return "application/json"
+		}
+	}
+	return contentType
+}
+
+// typeExcluded reports whether the content type of the file is one of
+// opts.ExcludeTypes, returning the detected type. Files that cannot be opened
+// are not excluded, so that checking them reports the error.
+func (opts Options) typeExcluded(filePath string) (bool, string) {
+	if len(opts.ExcludeTypes) == 0 {
+		return false, ""
+	}
+	var file io.ReadCloser
+	var err error
+	if opts.Source != nil {
+		file, err = opts.Source.Open(filePath)
+	} else if opts.LongPaths {
+		file, err = os.Open(longPath(filePath))
+	} else {
+		file, err = os.Open(filePath)
+	}
+	if err != nil {
+		return false, ""
+	}
+	defer file.Close()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, ""
+	}
+	contentType := sniffType(head[:n])
+	return matchesType(opts.ExcludeTypes, contentType), contentType
+}
+
+// matchesType reports whether the media type of contentType, without its
+// parameters, is one of types. A type such as image/* matches all subtypes.
+func matchesType(types []string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipType records a file left out because of its content type.
+func (r *Result) skipType(path, contentType string) {
+	slog.Debug("skipping file by content type", "path", path, "content_type", contentType)
+	r.mu.Lock()
+	r.SkippedByType++
+	r.mu.Unlock()
+}
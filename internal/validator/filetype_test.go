@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniffType(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{`{"kind": "metadata"}`, "application/json"},
+		{"\n  [1, 2]", "application/json"},
+		{"plain text", "text/plain; charset=utf-8"},
+		{"\x89PNG\r\n\x1a\n", "image/png"},
+	}
+	for _, test := range tests {
+		if got := sniffType([]byte(test.head)); got != test.want {
+			t.Errorf("sniffType(%q) = %q, want %q", test.head, got, test.want)
+		}
+	}
+}
+
+func TestProcessFolderExcludeTypes(t *testing.T) {
+	dir := t.TempDir()
+	for _, content := range []string{`{"kind": "metadata"}`, "\x89PNG\r\n\x1a\n", "data"} {
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+		if err := os.WriteFile(filepath.Join(dir, hash), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2, ExcludeTypes: []string{"application/json", "image/*"}, LimitFiles: 1})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 || result.SkippedByType != 2 {
+		t.Errorf("Expected 1 intact file and 2 skipped by type, got %d intact of %d and %d skipped", result.IntactFiles, result.TotalFiles, result.SkippedByType)
+	}
+	if result.StoppedEarly {
+		t.Error("Expected files skipped by type not to count against the file limit")
+	}
+}
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// canonicalName returns the lowercase hash a file name is made of, with any
+// extension removed, and whether the name is a SHA256 hash at all.
+func canonicalName(name string) (string, bool) {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToLower(name)
+	return name, isValidSha256(name)
+}
+
+// FixNames renames every file of the folder whose name is a SHA256 hash in
+// upper- or mixed-case hex, or followed by an extension, to the lowercase
+// hash alone. Only files whose content has that hash are renamed: the others
+// are corrupted, and renaming them would hide it. Files already named in the
+// canonical form are not hashed, and files whose name is not a hash are left
+// to Canonicalize. Collisions and dryRun are handled as by Canonicalize.
+func FixNames(folderPath string, dryRun bool, opts Options) (*CanonicalizeResult, error) {
+	result := &CanonicalizeResult{FolderPath: folderPath, DryRun: dryRun}
+	candidates, err := renameCandidates(folderPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, path := range candidates {
+		name := filepath.Base(path)
+		if hash, ok := canonicalName(name); ok && hash == name {
+			result.CanonicalFiles++
+		} else if ok {
+			paths = append(paths, path)
+		}
+	}
+	hashes, errs := hashAll(paths, opts)
+	result.Errors = errs
+
+	planned := make(map[string]bool)
+	for _, path := range paths {
+		hash, ok := hashes[path]
+		if !ok {
+			continue
+		}
+		if name, _ := canonicalName(filepath.Base(path)); name != hash {
+			result.CorruptedFiles = append(result.CorruptedFiles, path)
+			continue
+		}
+
+		rename := Rename{FilePath: path, NewPath: filepath.Join(filepath.Dir(path), hash)}
+		if existsApart(rename.FilePath, rename.NewPath) || planned[rename.NewPath] {
+			result.Collisions = append(result.Collisions, rename)
+			continue
+		}
+		planned[rename.NewPath] = true
+		if !dryRun {
+			if err := os.Rename(rename.FilePath, rename.NewPath); err != nil {
+				result.Errors = append(result.Errors, ErroredFile{FilePath: path, Error: err.Error()})
+				continue
+			}
+		}
+		result.Renamed = append(result.Renamed, rename)
+	}
+	return result, nil
+}
+
+// existsApart reports whether newPath exists as another file than path. On
+// case-insensitive file systems, a name differing only in case is the same
+// file, which can be renamed.
+func existsApart(path, newPath string) bool {
+	newInfo, err := os.Lstat(newPath)
+	if err != nil {
+		return false
+	}
+	info, err := os.Lstat(path)
+	return err != nil || !os.SameFile(info, newInfo)
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixNames(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	otherHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	files := map[string]string{
+		strings.ToUpper(hash):               "test content",
+		hash + ".bin":                       "test content",
+		strings.ToUpper(otherHash) + ".txt": "test content",
+		otherHash:                           "",
+		"photo.jpg":                         "test content",
+	}
+	write := func(t *testing.T) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+		}
+		return dir
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		dir := write(t)
+		result, err := FixNames(dir, dryRun, Options{Workers: 2})
+		if err != nil {
+			t.Fatalf("FixNames failed: %v", err)
+		}
+		if result.CanonicalFiles != 1 {
+			t.Errorf("Expected 1 canonical file, got %d", result.CanonicalFiles)
+		}
+		if len(result.CorruptedFiles) != 1 || result.CorruptedFiles[0] != filepath.Join(dir, strings.ToUpper(otherHash)+".txt") {
+			t.Errorf("Expected the file named after another hash to be corrupted, got %v", result.CorruptedFiles)
+		}
+		// The upper-case name comes first in the walk, so the one with an
+		// extension collides with it.
+		if len(result.Renamed) != 1 || result.Renamed[0].FilePath != filepath.Join(dir, strings.ToUpper(hash)) || result.Renamed[0].NewPath != filepath.Join(dir, hash) {
+			t.Errorf("Expected the upper-case name to be renamed, got %v", result.Renamed)
+		}
+		if len(result.Collisions) != 1 || result.Collisions[0].FilePath != filepath.Join(dir, hash+".bin") {
+			t.Errorf("Expected the name with an extension to collide, got %v", result.Collisions)
+		}
+		_, err = os.Stat(filepath.Join(dir, hash))
+		if renamed := err == nil; renamed == dryRun {
+			t.Errorf("Expected the upper-case name to be renamed only without a dry run, renamed: %v", renamed)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "photo.jpg")); err != nil {
+			t.Errorf("Expected photo.jpg to be left alone: %v", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// GenerateManifest hashes every regular file in the folder that is not
+// excluded and for which skip returns false, and calls emit with its path,
+// hash and size. Files are hashed by opts.Workers workers, so emit is called
+// in no particular order, but never concurrently. Files that cannot be read are
+// logged and left out.
+func GenerateManifest(folderPath string, skip func(path string) bool, opts Options, emit func(ManifestEntry) error) error {
+	var mu sync.Mutex
+	var emitErr error
+
+	pathChan := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < max(opts.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range pathChan {
+				sum, err := hashFile(path, opts)
+				if err != nil {
+					slog.Error("hashing file failed", "path", path, "error", err)
+					continue
+				}
+				mu.Lock()
+				if emitErr == nil {
+					emitErr = emit(ManifestEntry{Path: path, Hash: sum.hash, Size: sum.size, HasSize: true})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := walkFolder(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := opts.skipHidden(folderPath, path, info); skip {
+			return err
+		}
+		if skip, err := opts.skipNested(folderPath, path, info); skip {
+			return err
+		}
+		if isLockFile(folderPath, path) {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if opts.Exclude != nil && opts.Exclude.MatchString(path) {
+			slog.Debug("skipping excluded file", "path", path)
+			return nil
+		}
+		if skip != nil && skip(path) {
+			return nil
+		}
+		mu.Lock()
+		failed := emitErr != nil
+		mu.Unlock()
+		if failed {
+			return filepath.SkipAll
+		}
+		pathChan <- path
+		return nil
+	})
+	close(pathChan)
+	wg.Wait()
+
+	if err != nil {
+		return walkError(folderPath, err)
+	}
+	return emitErr
+}
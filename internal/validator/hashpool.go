@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+)
+
+const (
+	// chunkSize is the size of the chunks readers hand to the hash workers.
+	chunkSize = 1 << 20
+	// chunksAhead is the number of chunks a reader may read ahead of the hash worker.
+	chunksAhead = 4
+)
+
+var chunkPool = sync.Pool{New: func() any { return make([]byte, chunkSize) }}
+
+// hashPool hashes the chunks read by the workers on a separate, fixed number
+// of goroutines, so that the number of concurrent reads and the number of
+// CPUs busy hashing can be chosen independently.
+type hashPool struct {
+	jobs chan *hashJob
+	wg   sync.WaitGroup
+}
+
+// hashJob is a file being streamed from a reader to a hash worker.
+type hashJob struct {
+	chunks     chan []byte
+	detectType bool
+	done       chan fileSum
+}
+
+// newHashPool starts the hash workers. With affinity, every worker runs on the
+// CPUs of one NUMA node, where the platform supports it.
+func newHashPool(workers int, affinity bool) *hashPool {
+	p := &hashPool{jobs: make(chan *hashJob)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func(worker int) {
+			defer p.wg.Done()
+			if affinity {
+				pinHashWorker(worker)
+			}
+			for job := range p.jobs {
+				job.run()
+			}
+		}(i)
+	}
+	return p
+}
+
+// close stops the hash workers once no more files are read.
+func (p *hashPool) close() {
+	close(p.jobs)
+	p.wg.Wait()
+}
+
+func (j *hashJob) run() {
+	hash := sha256.New()
+	var head *sniffer
+	if j.detectType {
+		head = &sniffer{}
+	}
+	var n int64
+	for chunk := range j.chunks {
+		hash.Write(chunk)
+		if head != nil {
+			head.Write(chunk)
+		}
+		n += int64(len(chunk))
+		chunkPool.Put(chunk[:cap(chunk)])
+	}
+	sum := fileSum{hash: hex.EncodeToString(hash.Sum(nil)), size: n}
+	if head != nil {
+		sum.contentType = sniffType(head.buf)
+	}
+	j.done <- sum
+}
+
+// digest reads r in chunks and hands them to a hash worker. Reading continues
+// while the hash worker is busy, up to chunksAhead chunks.
+func (p *hashPool) digest(r io.Reader, opts Options) (fileSum, error) {
+	job := &hashJob{
+		chunks:     make(chan []byte, chunksAhead),
+		detectType: opts.DetectType,
+		done:       make(chan fileSum, 1),
+	}
+	queued := make(chan struct{})
+	go func() {
+		p.jobs <- job
+		close(queued)
+	}()
+
+	r = limitReader(r, opts.Limiter)
+	var readErr error
+	for {
+		chunk := chunkPool.Get().([]byte)
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			job.chunks <- chunk[:n]
+		} else {
+			chunkPool.Put(chunk)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	close(job.chunks)
+	<-queued
+	sum := <-job.done
+	if readErr != nil {
+		return fileSum{}, readErr
+	}
+	return sum, nil
+}
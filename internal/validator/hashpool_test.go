@@ -0,0 +1,138 @@
+package validator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// latentVolume simulates a remote backend with high bandwidth but a fixed
+// latency for every read, such as an object store mounted over the network.
+type latentVolume struct {
+	latency time.Duration
+}
+
+func (v *latentVolume) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (v *latentVolume) Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &latentFile{file: file, latency: v.latency}, nil
+}
+
+// latentFile does not embed *os.File, whose WriteTo method would let io.Copy
+// bypass Read.
+type latentFile struct {
+	file    *os.File
+	latency time.Duration
+}
+
+func (f *latentFile) Read(p []byte) (int, error) {
+	time.Sleep(f.latency)
+	return f.file.Read(p)
+}
+
+func (f *latentFile) Close() error {
+	return f.file.Close()
+}
+
+func TestProcessFolderHashWorkers(t *testing.T) {
+	dir := writeLocalityTree(t)
+	result, err := ProcessFolder(dir, Options{Workers: 8, HashWorkers: 2, DetectType: true, RecordFiles: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 32 || result.CorruptedFiles != 0 {
+		t.Errorf("Expected 32 intact files, got %d intact and %d corrupted", result.IntactFiles, result.CorruptedFiles)
+	}
+	if result.HashedBytes != 32*128<<10 {
+		t.Errorf("Expected %d hashed bytes, got %d", 32*128<<10, result.HashedBytes)
+	}
+	if result.Files[0].ContentType == "" {
+		t.Error("Expected the content type to be detected by the hash workers")
+	}
+}
+
+func benchmarkLatentVolume(b *testing.B, opts Options) {
+	dir := writeLocalityTree(b)
+	opts.Source = &latentVolume{latency: 2 * time.Millisecond}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := ProcessFolder(dir, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result.IntactFiles != 32 {
+			b.Fatalf("Expected 32 intact files, got %d", result.IntactFiles)
+		}
+	}
+}
+
+// BenchmarkLatentVolumeSharedPool hashes on the two workers that read, so
+// only two reads are in flight at a time.
+func BenchmarkLatentVolumeSharedPool(b *testing.B) {
+	benchmarkLatentVolume(b, Options{Workers: 2})
+}
+
+// BenchmarkLatentVolumeSplitPool keeps sixteen reads in flight while still
+// hashing on two goroutines.
+func BenchmarkLatentVolumeSplitPool(b *testing.B) {
+	benchmarkLatentVolume(b, Options{Workers: 16, HashWorkers: 2})
+}
+
+func benchmarkHashAffinity(b *testing.B, affinity bool) {
+	dir := writeLocalityTree(b)
+	opts := Options{Workers: runtime.NumCPU(), HashWorkers: runtime.NumCPU(), HashAffinity: affinity}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ProcessFolder(dir, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHashWorkersUnpinned and BenchmarkHashWorkersPinned compare hashing
+// with one hash worker per CPU, left to the scheduler or spread over the NUMA
+// nodes. They only differ on machines with more than one node.
+func BenchmarkHashWorkersUnpinned(b *testing.B) {
+	benchmarkHashAffinity(b, false)
+}
+
+func BenchmarkHashWorkersPinned(b *testing.B) {
+	benchmarkHashAffinity(b, true)
+}
+
+// benchmarkLargeFile hashes a single large file. Its SHA256 hash has to be
+// computed over the content in order, so the file is hashed on one CPU
+// either way; a hash worker only lets the next chunks be read meanwhile.
+func benchmarkLargeFile(b *testing.B, hashWorkers int) {
+	const size = 256 << 20
+	filePath := writeTestData(b, size)
+	var opts Options
+	if hashWorkers > 0 {
+		opts.hashers = newHashPool(hashWorkers, false)
+		defer opts.hashers.close()
+	}
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := hashFile(filePath, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLargeFileSerial(b *testing.B) {
+	benchmarkLargeFile(b, 0)
+}
+
+func BenchmarkLargeFileHashWorker(b *testing.B) {
+	benchmarkLargeFile(b, runtime.NumCPU())
+}
@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HashSource provides the expected hash of every file of a folder.
+type HashSource interface {
+	// ExpectedHash returns the expected hash of the file at filePath, found
+	// below folderPath. ok is false when the source has no hash for the file,
+	// which is then listed in Result.NotIndexed instead of being checked.
+	ExpectedHash(folderPath, filePath string, opts Options) (hash string, ok bool, err error)
+}
+
+// missingReporter is implemented by hash sources that list the files they
+// expect, so that listed files that were not found can be reported as missing.
+type missingReporter interface {
+	missing(folderPath string, found map[string]bool) []string
+}
+
+// fileSkipper is implemented by hash sources whose hashes are stored in files
+// of the folder, which must not be validated themselves.
+type fileSkipper interface {
+	skips(filePath string) bool
+}
+
+// hashSource returns the configured hash source, which defaults to the file names.
+func (opts Options) hashSource() HashSource {
+	if opts.HashSource != nil {
+		return opts.HashSource
+	}
+	return filenameSource{}
+}
+
+// filenameSource takes the expected hash from the file name, through
+// Options.NamePattern if it is set.
+type filenameSource struct{}
+
+func (filenameSource) ExpectedHash(folderPath, filePath string, opts Options) (string, bool, error) {
+	return expectedHashOf(filePath, opts), true, nil
+}
+
+// IndexSource maps paths relative to the folder, with forward slashes, to
+// their expected hash. Index entries without a file are reported as missing.
+type IndexSource map[string]string
+
+func (s IndexSource) ExpectedHash(folderPath, filePath string, opts Options) (string, bool, error) {
+	hash, ok := s[indexPath(folderPath, filePath)]
+	return hash, ok, nil
+}
+
+func (s IndexSource) missing(folderPath string, found map[string]bool) []string {
+	var missing []string
+	for rel := range s {
+		if !found[rel] {
+			missing = append(missing, filepath.Join(folderPath, filepath.FromSlash(rel)))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// SidecarExt is the extension of the sidecar files read by SidecarSource.
+const SidecarExt = ".sha256"
+
+// SidecarSource reads the expected hash of every file from a sidecar file
+// next to it with SidecarExt appended to its name, such as the output of
+// sha256sum for that file. Sidecar files are not validated themselves.
+type SidecarSource struct{}
+
+func (SidecarSource) ExpectedHash(folderPath, filePath string, opts Options) (string, bool, error) {
+	var file io.ReadCloser
+	var err error
+	if opts.Source != nil {
+		file, err = opts.Source.Open(filePath + SidecarExt)
+	} else if opts.LongPaths {
+		file, err = os.Open(longPath(filePath + SidecarExt))
+	} else {
+		file, err = os.Open(filePath + SidecarExt)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(io.LimitReader(file, 4096)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false, fmt.Errorf("sidecar file %s is empty", filePath+SidecarExt)
+	}
+	return strings.ToLower(fields[0]), true, nil
+}
+
+func (SidecarSource) skips(filePath string) bool {
+	return strings.HasSuffix(filePath, SidecarExt)
+}
+
+// XattrSource reads the expected hash of every file from the extended
+// attribute with the given name, such as "user.sha256". It is only supported
+// for local folders on Linux and macOS.
+type XattrSource struct {
+	Name string
+}
+
+func (s XattrSource) ExpectedHash(folderPath, filePath string, opts Options) (string, bool, error) {
+	if opts.Source != nil {
+		return "", false, errors.New("extended attributes cannot be read from remote folders")
+	}
+	value, ok, err := readXattr(filePath, s.Name)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	return strings.ToLower(strings.TrimSpace(value)), true, nil
+}
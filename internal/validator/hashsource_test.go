@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testContentHash = "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+
+func TestProcessFolderSidecarSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"0b6e7c2e-uuid":        "test content",
+		"0b6e7c2e-uuid.sha256": testContentHash + "  0b6e7c2e-uuid\n",
+		"4f1d9a70-uuid":        "changed",
+		"4f1d9a70-uuid.sha256": testContentHash + "\n",
+		"no-sidecar":           "test content",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2, HashSource: SidecarSource{}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 2 || result.IntactFiles != 1 || result.CorruptedFiles != 1 {
+		t.Errorf("Expected 1 intact and 1 corrupted file of 2, got %d intact and %d corrupted of %d", result.IntactFiles, result.CorruptedFiles, result.TotalFiles)
+	}
+	if len(result.NotIndexed) != 1 || result.NotIndexed[0] != filepath.Join(dir, "no-sidecar") {
+		t.Errorf("Expected the file without sidecar to be listed, got %v", result.NotIndexed)
+	}
+}
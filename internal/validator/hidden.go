@@ -0,0 +1,21 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// skipHidden reports whether the walk should leave out path because it is
+// hidden, its name starting with a dot, and opts.SkipHidden is set. For a
+// hidden directory, err is filepath.SkipDir so that its contents are pruned.
+// The root of the walk is never hidden.
+func (opts Options) skipHidden(root, path string, info os.FileInfo) (skip bool, err error) {
+	if !opts.SkipHidden || path == root || !strings.HasPrefix(filepath.Base(path), ".") {
+		return false, nil
+	}
+	if info.IsDir() {
+		return true, filepath.SkipDir
+	}
+	return true, nil
+}
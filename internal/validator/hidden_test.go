@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderSkipHidden(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".store")
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	for _, rel := range []string{
+		hash,
+		filepath.Join("data", hash),
+		filepath.Join("data", ".partial"),
+		filepath.Join(".trash", hash),
+		filepath.Join("data", ".cache", "nested", hash),
+		filepath.Join("data", "visible", ".hidden", hash),
+	} {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(root, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 6 {
+		t.Errorf("Expected hidden files to be checked by default, got %d files", result.TotalFiles)
+	}
+
+	progress := &Progress{}
+	result, err = ProcessFolder(root, Options{Workers: 2, SkipHidden: true, ReportEmptyDirs: true, Progress: progress})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 2 || result.IntactFiles != 2 {
+		t.Errorf("Expected only the 2 visible files to be checked, got %d intact of %d", result.IntactFiles, result.TotalFiles)
+	}
+	if want := []string{filepath.Join(root, "data", "visible")}; len(result.EmptyDirs) != 1 || result.EmptyDirs[0] != want[0] {
+		t.Errorf("Expected %v to be empty once hidden entries are skipped, got %v", want, result.EmptyDirs)
+	}
+	if s := progress.Snapshot(); s.Files != 2 {
+		t.Errorf("Expected the progress walk to skip hidden files too, got %d files", s.Files)
+	}
+}
@@ -0,0 +1,28 @@
+package validator
+
+type SizeBucket struct {
+	Range string `json:"range"`
+	Count int    `json:"count"`
+}
+
+// sizeBucketLimits are the exclusive upper bounds of every bucket except the last.
+var sizeBucketLimits = []int64{1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20, 100 << 20, 1 << 30}
+
+var sizeBucketRanges = []string{"<1KiB", "1-10KiB", "10-100KiB", "100KiB-1MiB", "1-10MiB", "10-100MiB", "100MiB-1GiB", ">1GiB"}
+
+func newSizeHistogram() []SizeBucket {
+	histogram := make([]SizeBucket, len(sizeBucketRanges))
+	for i, r := range sizeBucketRanges {
+		histogram[i].Range = r
+	}
+	return histogram
+}
+
+func sizeBucketIndex(size int64) int {
+	for i, limit := range sizeBucketLimits {
+		if size < limit {
+			return i
+		}
+	}
+	return len(sizeBucketLimits)
+}
@@ -0,0 +1,27 @@
+package validator
+
+import "os"
+
+// PreviousFiles indexes the file records of earlier results by path, for use as Options.Previous.
+func PreviousFiles(results []*Result) map[string]FileRecord {
+	files := make(map[string]FileRecord)
+	for _, result := range results {
+		for _, file := range result.Files {
+			files[file.FilePath] = file
+		}
+	}
+	return files
+}
+
+// isTrusted reports whether the file was intact in the previous report and has
+// not changed in size or modification time since.
+func isTrusted(previous map[string]FileRecord, filePath string, info os.FileInfo) bool {
+	if info == nil {
+		return false
+	}
+	prev, ok := previous[filePath]
+	if !ok || prev.Status != StatusIntact {
+		return false
+	}
+	return prev.Size == info.Size() && prev.ModTime.Equal(info.ModTime())
+}
@@ -0,0 +1,27 @@
+package validator
+
+import "path/filepath"
+
+// indexPath returns the key of the file in an IndexSource.
+func indexPath(folderPath, path string) string {
+	rel, err := filepath.Rel(folderPath, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (r *Result) addNotIndexed(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.NotIndexedFiles++
+	addToList(r, &r.NotIndexed, "not_indexed", path)
+}
+
+// addMissing reports files that were expected but not found as missing.
+func (r *Result) addMissing(paths []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.MissingFiles += len(paths)
+	r.MissingFileList = append(r.MissingFileList, paths...)
+}
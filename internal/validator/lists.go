@@ -0,0 +1,22 @@
+package validator
+
+// ListWriter receives the entries of the file lists of a result as they are
+// found, so that they can be written out instead of kept in memory. The
+// entries are only handed to the ListWriter and not added to the result.
+// Missing files and empty directories come in bulk at the end of a run and
+// are always kept in the result.
+type ListWriter interface {
+	// Add adds the entry to the list with the given JSON name, such as
+	// corrupted_file_list.
+	Add(list string, entry interface{})
+}
+
+// addToList adds the entry to the list of the result, or hands it to the
+// result's ListWriter when it has one. The caller holds r.mu.
+func addToList[T any](r *Result, list *[]T, name string, entry T) {
+	if r.lists != nil {
+		r.lists.Add(name, entry)
+		return
+	}
+	*list = append(*list, entry)
+}
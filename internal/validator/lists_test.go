@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// recordingLists records the entries handed to it by list.
+type recordingLists struct {
+	mu      sync.Mutex
+	entries map[string][]interface{}
+}
+
+func (l *recordingLists) Add(list string, entry interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.entries == nil {
+		l.entries = make(map[string][]interface{})
+	}
+	l.entries[list] = append(l.entries[list], entry)
+}
+
+func TestProcessFolderLists(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"invalid",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	lists := &recordingLists{}
+	result, err := ProcessFolder(dir, Options{Workers: 2, RecordFiles: true, Lists: lists})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.CorruptedFiles != 1 || result.InvalidFiles != 1 {
+		t.Errorf("Expected the counts to be kept, got %d corrupted and %d invalid", result.CorruptedFiles, result.InvalidFiles)
+	}
+	if result.CorruptedFileList != nil || result.InvalidFileList != nil || result.Files != nil {
+		t.Errorf("Expected the lists to be handed over instead of kept, got %v, %v and %v", result.CorruptedFileList, result.InvalidFileList, result.Files)
+	}
+	corrupted, ok := lists.entries["corrupted_file_list"]
+	if !ok || len(corrupted) != 1 || corrupted[0].(CorruptedFile).ActualHash != "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72" {
+		t.Errorf("Unexpected corrupted entries %v", corrupted)
+	}
+	if len(lists.entries["invalid_file_list"]) != 1 || len(lists.entries["files"]) != 3 {
+		t.Errorf("Unexpected entries %v", lists.entries)
+	}
+}
+
+// cancelingLists cancels the run once it has been handed the first entry.
+type cancelingLists struct {
+	recordingLists
+	cancel context.CancelFunc
+}
+
+func (l *cancelingLists) Add(list string, entry interface{}) {
+	l.recordingLists.Add(list, entry)
+	l.cancel()
+}
+
+func TestProcessFolderInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 50; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%064x", i)), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lists := &cancelingLists{cancel: cancel}
+	result, err := ProcessFolder(dir, Options{Workers: 1, QueueDepth: DefaultQueueDepth, Lists: lists, Context: ctx})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if !result.StoppedEarly || result.DeadlineExceeded {
+		t.Errorf("Expected the result to be stopped early without a deadline, got %+v", result)
+	}
+	corrupted := lists.entries["corrupted_file_list"]
+	if len(corrupted) == 0 || len(corrupted) >= 50 {
+		t.Errorf("Expected the run to stop after the first corrupted files, got %d", len(corrupted))
+	}
+	// Every file counted was handed over, and nothing after that.
+	if result.CorruptedFiles != len(corrupted) || result.TotalFiles != len(corrupted) {
+		t.Errorf("Expected %d corrupted files in the counts, got %d of %d", len(corrupted), result.CorruptedFiles, result.TotalFiles)
+	}
+}
@@ -0,0 +1,116 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// seekingVolume simulates a single spinning disk. Reads are serialized and a
+// read that does not continue the previously read file pays a seek penalty.
+type seekingVolume struct {
+	mu       sync.Mutex
+	lastFile string
+	lastPos  int64
+	seek     time.Duration
+}
+
+func (v *seekingVolume) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (v *seekingVolume) Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &seekingFile{file: file, volume: v, path: path}, nil
+}
+
+// seekingFile does not embed *os.File, whose WriteTo method would let io.Copy
+// bypass Read and with it the simulated seeks.
+type seekingFile struct {
+	file   *os.File
+	volume *seekingVolume
+	path   string
+	pos    int64
+}
+
+func (f *seekingFile) Read(p []byte) (int, error) {
+	v := f.volume
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	sequential := v.lastFile == f.path && v.lastPos == f.pos
+	// Moving to the start of the next file in the same directory is cheap, as
+	// files of a directory tend to be laid out next to each other.
+	nextInDir := f.pos == 0 && v.lastFile != "" && filepath.Dir(v.lastFile) == filepath.Dir(f.path)
+	if !sequential && !nextInDir {
+		time.Sleep(v.seek)
+	}
+	if len(p) > 32<<10 {
+		p = p[:32<<10]
+	}
+	n, err := f.file.Read(p)
+	f.pos += int64(n)
+	v.lastFile, v.lastPos = f.path, f.pos
+	return n, err
+}
+
+func (f *seekingFile) Close() error {
+	return f.file.Close()
+}
+
+func writeLocalityTree(t testing.TB) string {
+	dir := t.TempDir()
+	for d := 0; d < 4; d++ {
+		sub := filepath.Join(dir, fmt.Sprintf("dir%d", d))
+		if err := os.Mkdir(sub, 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		for f := 0; f < 8; f++ {
+			data := make([]byte, 128<<10)
+			data[0], data[1] = byte(d), byte(f)
+			sum := sha256.Sum256(data)
+			if err := os.WriteFile(filepath.Join(sub, hex.EncodeToString(sum[:])), data, 0o644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+		}
+	}
+	return dir
+}
+
+func TestProcessFolderLocalityAware(t *testing.T) {
+	dir := writeLocalityTree(t)
+	result, err := ProcessFolder(dir, Options{Workers: 3, LocalityAware: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 32 || result.IntactFiles != 32 {
+		t.Errorf("Expected 32 intact files, got %d of %d", result.IntactFiles, result.TotalFiles)
+	}
+}
+
+func benchmarkLocality(b *testing.B, localityAware bool) {
+	dir := writeLocalityTree(b)
+	opts := Options{Workers: 4, LocalityAware: localityAware, Source: &seekingVolume{seek: 200 * time.Microsecond}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ProcessFolder(dir, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProcessFolderPerFile(b *testing.B) {
+	benchmarkLocality(b, false)
+}
+
+func BenchmarkProcessFolderLocalityAware(b *testing.B) {
+	benchmarkLocality(b, true)
+}
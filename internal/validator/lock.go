@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockFile is the name of the lock file created in the root of a folder
+// while it is checked with a lock.
+const LockFile = ".verifydata.lock"
+
+// ErrLocked is returned by AcquireLock when another run holds the lock.
+var ErrLocked = errors.New("scan already in progress")
+
+// Lock is an advisory lock on a folder, held by the run that created its
+// lock file. It only keeps out other runs that lock the folder too.
+type Lock struct {
+	path string
+}
+
+// lockOwner is the content of the lock file, identifying the run holding it.
+type lockOwner struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// AcquireLock locks the folder by creating LockFile in it. When the folder is
+// locked already, it fails with ErrLocked, unless force is set: then the lock
+// is taken for stale, left behind by a run that was killed, and replaced.
+func AcquireLock(folderPath string, force bool) (*Lock, error) {
+	path := filepath.Join(folderPath, LockFile)
+	hostname, _ := os.Hostname()
+	owner, err := json.Marshal(lockOwner{PID: os.Getpid(), Hostname: hostname, StartedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		if !force {
+			return nil, lockedError(path)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(owner); err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return &Lock{path: path}, nil
+}
+
+// lockedError describes the run holding the lock at path, as far as the lock
+// file tells.
+func lockedError(path string) error {
+	data, err := os.ReadFile(path)
+	var owner lockOwner
+	if err != nil || json.Unmarshal(data, &owner) != nil {
+		return fmt.Errorf("%w: %s exists", ErrLocked, path)
+	}
+	return fmt.Errorf("%w: %s is held by pid %d on %s since %s", ErrLocked, path, owner.PID, owner.Hostname, owner.StartedAt.Format(time.RFC3339))
+}
+
+// Release removes the lock file.
+func (l *Lock) Release() error {
+	return os.Remove(l.path)
+}
+
+// isLockFile reports whether path is the lock file in the root of the walk,
+// which is not part of the data.
+func isLockFile(root, path string) bool {
+	return filepath.Base(path) == LockFile && filepath.Dir(path) == filepath.Clean(root)
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	root := t.TempDir()
+	lock, err := AcquireLock(root, false)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	if _, err := AcquireLock(root, false); !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), "pid") {
+		t.Errorf("Expected ErrLocked naming the holder, got %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, LockFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the lock file to be removed, got %v", err)
+	}
+
+	// A lock left behind by a killed run is only replaced with force.
+	if err := os.WriteFile(filepath.Join(root, LockFile), []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+	if _, err := AcquireLock(root, false); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked for a stale lock, got %v", err)
+	}
+	lock, err = AcquireLock(root, true)
+	if err != nil {
+		t.Fatalf("AcquireLock with force failed: %v", err)
+	}
+	defer lock.Release()
+
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	if err := os.WriteFile(filepath.Join(root, hash), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	result, err := ProcessFolder(root, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.InvalidFiles != 0 {
+		t.Errorf("Expected the lock file to be left out, got %d files and %d invalid", result.TotalFiles, result.InvalidFiles)
+	}
+}
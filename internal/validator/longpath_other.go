@@ -0,0 +1,16 @@
+//go:build !windows
+
+package validator
+
+import (
+	"errors"
+	"syscall"
+)
+
+func isPathTooLong(err error) bool {
+	return errors.Is(err, syscall.ENAMETOOLONG)
+}
+
+func longPath(path string) string {
+	return path
+}
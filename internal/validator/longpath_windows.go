@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const (
+	errorPathNotFound       syscall.Errno = 3
+	errorFilenameExcedRange syscall.Errno = 206
+
+	maxPath = 260
+)
+
+// isPathTooLong reports whether err was caused by a path exceeding the Windows
+// limits. Windows usually reports such paths as not found, so that error only
+// counts when the path is longer than MAX_PATH.
+func isPathTooLong(err error) bool {
+	if errors.Is(err, errorFilenameExcedRange) {
+		return true
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && errors.Is(err, errorPathNotFound) {
+		return len(pathErr.Path) >= maxPath && !strings.HasPrefix(pathErr.Path, `\\?\`)
+	}
+	return false
+}
+
+// longPath converts the path to an absolute extended-length path, which lifts
+// the MAX_PATH limit of the Windows API. Go already does so for long absolute
+// paths, but not for relative ones.
+func longPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
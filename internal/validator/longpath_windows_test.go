@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{`C:\data\blobs`, `\\?\C:\data\blobs`},
+		{`\\server\share\blobs`, `\\?\UNC\server\share\blobs`},
+		{`\\?\C:\data`, `\\?\C:\data`},
+	}
+
+	for _, test := range tests {
+		if got := longPath(test.path); got != test.expect {
+			t.Errorf("longPath(%s) = %s, want %s", test.path, got, test.expect)
+		}
+	}
+}
+
+func TestIsPathTooLong(t *testing.T) {
+	long := `C:\` + strings.Repeat(`a\`, 200)
+	tests := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{"Filename Exceeds Range", &fs.PathError{Op: "open", Path: `C:\a`, Err: errorFilenameExcedRange}, true},
+		{"Long Path Not Found", &fs.PathError{Op: "open", Path: long, Err: errorPathNotFound}, true},
+		{"Short Path Not Found", &fs.PathError{Op: "open", Path: `C:\missing`, Err: errorPathNotFound}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isPathTooLong(test.err); got != test.expect {
+				t.Errorf("isPathTooLong(%v) = %v, want %v", test.err, got, test.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"os"
+	"strings"
+)
+
+// ManifestEntry is a file listed in a manifest together with its expected
+// hash and, if the manifest records it, its expected size.
+type ManifestEntry struct {
+	Path string
+	Hash string
+	// Size is the expected size in bytes. It is only known when HasSize is set.
+	Size    int64
+	HasSize bool
+}
+
+// ProcessManifest validates the files listed in a manifest against the hashes
+// given there instead of their names. Listed files that do not exist are
+// reported as missing; those that are symlinks whose target does not exist
+// are listed as broken symlinks as well. With opts.HashLinks, symlinks are
+// hashed themselves rather than their targets. The name is used as the
+// result's folder path.
+func ProcessManifest(name string, entries []ManifestEntry, opts Options) (*Result, error) {
+	result := &Result{FolderPath: name, lists: opts.Lists}
+	if opts.SizeHistogram {
+		result.SizeHistogram = newSizeHistogram()
+	}
+	if opts.DedupInodes {
+		opts.inodes = newInodeCache()
+	}
+	if opts.ContentCache {
+		opts.contents = newContentCache()
+	}
+	if opts.HashWorkers > 0 {
+		opts.hashers = newHashPool(opts.HashWorkers, opts.HashAffinity)
+		defer opts.hashers.close()
+	}
+
+	opts.Progress.start(name)
+	ctx, cancel := runContext(opts)
+	defer cancel()
+	fileChan := make(chan []fileEntry, max(opts.QueueDepth, 0))
+	wg := startWorkers(ctx, cancel, fileChan, result, opts)
+
+	var resolver *nfcResolver
+	if opts.NormalizeUnicode {
+		resolver = newNFCResolver()
+	}
+	budget := opts.newBudget()
+	// present is the sum of the sizes of the listed files that exist.
+	var present int64
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if resolver != nil {
+			entry.Path = resolver.resolve(entry.Path)
+		}
+		stat := os.Stat
+		if opts.HashLinks {
+			stat = os.Lstat
+		}
+		info, err := stat(entry.Path)
+		if err != nil {
+			if isPathTooLong(err) {
+				result.addPathError(entry.Path, err)
+				continue
+			}
+			if !os.IsNotExist(err) {
+				result.addError(entry.Path, err)
+				continue
+			}
+			result.mu.Lock()
+			result.MissingFiles++
+			result.MissingFileList = append(result.MissingFileList, entry.Path)
+			if brokenLink(entry.Path) {
+				result.BrokenSymlinks++
+				addToList(result, &result.BrokenSymlinkList, "broken_symlink_list", entry.Path)
+			}
+			result.mu.Unlock()
+			continue
+		}
+		if info.IsDir() {
+			continue
+		}
+		if !info.Mode().IsRegular() && !isSymlink(info) {
+			result.skipSpecial(entry.Path, info)
+			continue
+		}
+		present += info.Size()
+		if !opts.sizeIncluded(info.Size()) {
+			result.skipSize()
+			continue
+		}
+		if opts.tooNew(info) {
+			result.skipTooNew(entry.Path)
+			continue
+		}
+		opts.Progress.addFound(info.Size())
+		file := fileEntry{path: entry.Path, info: info, expectedHash: strings.ToLower(entry.Hash), hasExpected: true, expectedSize: entry.Size, hasSize: entry.HasSize}
+		if budget != nil {
+			budget.add(file)
+			continue
+		}
+		fileChan <- []fileEntry{file}
+	}
+	if budget != nil {
+		budget.send(ctx, fileChan, result, opts)
+	}
+
+	opts.Progress.walkDone()
+	close(fileChan)
+	wg.Wait()
+	result.finishRun(ctx)
+	// The files after an interruption were not looked at.
+	if opts.HasExpectedTotal && !result.StoppedEarly {
+		result.ExpectedTotalBytes = opts.ExpectedTotal
+		result.PresentBytes = present
+		result.TotalSizeMismatch = present != opts.ExpectedTotal
+	}
+	result.sortLists(opts)
+	result.finishTree(opts)
+	result.computeRates()
+	return result, nil
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package validator
+
+import (
+	"errors"
+	"math"
+	"os"
+	"syscall"
+)
+
+var errMmapTooLarge = errors.New("file is too large to memory-map on this platform")
+
+func mmapFile(file *os.File, size int64) ([]byte, error) {
+	// On 32-bit platforms the mapping length cannot represent files of 2 GiB or more.
+	if uint64(size) > math.MaxInt {
+		return nil, errMmapTooLarge
+	}
+	return syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func munmapFile(data []byte) {
+	syscall.Munmap(data)
+}
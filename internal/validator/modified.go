@@ -0,0 +1,41 @@
+package validator
+
+import "os"
+
+// modifiedSince reports whether the local file was changed or removed since
+// info was taken, judging by its size and modification time, and returns
+// its current info. Files of a Source are never reported as changed.
+func modifiedSince(filePath string, info os.FileInfo, opts Options) (os.FileInfo, bool) {
+	if info == nil || opts.Source != nil {
+		return nil, false
+	}
+	openPath := filePath
+	if opts.LongPaths {
+		openPath = longPath(filePath)
+	}
+	current, err := os.Stat(openPath)
+	if err != nil {
+		return nil, os.IsNotExist(err)
+	}
+	return current, current.Size() != info.Size() || !current.ModTime().Equal(info.ModTime())
+}
+
+// recheckModified checks whether a file whose hash did not match was changed
+// since info was taken right before hashing it, as happens to a file that is
+// written while it is read. With opts.RehashModified, a changed file is
+// hashed once more, and only reported as modified if it changes again. The
+// returned sum and info are those of the second hash when it was taken.
+func recheckModified(filePath string, info os.FileInfo, sum fileSum, opts Options) (fileSum, os.FileInfo, bool, error) {
+	current, modified := modifiedSince(filePath, info, opts)
+	if !modified || !opts.RehashModified || current == nil {
+		return sum, info, modified, nil
+	}
+	rehashed, err := hashFile(filePath, opts)
+	if err != nil {
+		return sum, info, false, err
+	}
+	if _, modified := modifiedSince(filePath, current, opts); modified {
+		return sum, info, true, nil
+	}
+	return rehashed, current, false, nil
+}
@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeGrowing writes the first part of content to the file and returns its
+// info, then writes the whole content, as a file still being written would
+// look before and after it was hashed.
+func writeGrowing(t *testing.T, path string, content []byte) os.FileInfo {
+	t.Helper()
+	if err := os.WriteFile(path, content[:len(content)/2], 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat test file: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to change the modification time: %v", err)
+	}
+	return info
+}
+
+func TestCheckFileModified(t *testing.T) {
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("content being written "), 12<<10)
+	// The name is the hash of what was there before.
+	sum := sha256.Sum256([]byte("content before"))
+	path := filepath.Join(dir, hex.EncodeToString(sum[:]))
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat test file: %v", err)
+	}
+
+	// Reading the file at 1 MiB/s takes about a quarter of a second, during
+	// which it is changed.
+	changed := make(chan error)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		later := info.ModTime().Add(time.Second)
+		changed <- os.Chtimes(path, later, later)
+	}()
+	result := &Result{}
+	checkFile(path, hex.EncodeToString(sum[:]), info, result, Options{RecordFiles: true, Limiter: NewLimiter(1 << 20)})
+	if err := <-changed; err != nil {
+		t.Fatalf("Failed to change the modification time: %v", err)
+	}
+	if result.ModifiedFiles != 1 || len(result.ModifiedFileList) != 1 || result.CorruptedFiles != 0 {
+		t.Errorf("Expected 1 modified and no corrupted files, got %d: %v and %d", result.ModifiedFiles, result.ModifiedFileList, result.CorruptedFiles)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != StatusModified {
+		t.Errorf("Expected a file record with status %s, got %+v", StatusModified, result.Files)
+	}
+
+	// A file changed after the walk found it but before it was hashed is
+	// corrupted, since what was hashed does not match.
+	result = &Result{}
+	checkFile(path, hex.EncodeToString(sum[:]), writeGrowing(t, path, content), result, Options{})
+	if result.ModifiedFiles != 0 || result.CorruptedFiles != 1 {
+		t.Errorf("Expected 1 corrupted and no modified files, got %d and %d", result.CorruptedFiles, result.ModifiedFiles)
+	}
+
+	// Unchanged files that do not match are corrupted as ever.
+	result = &Result{}
+	if info, err = os.Stat(path); err != nil {
+		t.Fatalf("Failed to stat test file: %v", err)
+	}
+	checkFile(path, hex.EncodeToString(sum[:]), info, result, Options{})
+	if result.ModifiedFiles != 0 || result.CorruptedFiles != 1 {
+		t.Errorf("Expected 1 corrupted and no modified files, got %d and %d", result.CorruptedFiles, result.ModifiedFiles)
+	}
+}
+
+func TestRecheckModified(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("content being written")
+	contentSum := sha256.Sum256(content)
+	path := filepath.Join(dir, hex.EncodeToString(contentSum[:]))
+	info := writeGrowing(t, path, content)
+	// The hash of the first half, read while the file was written.
+	partial := fileSum{hash: "partial", size: info.Size()}
+
+	if _, _, modified, err := recheckModified(path, info, partial, Options{}); err != nil || !modified {
+		t.Errorf("Expected the file to be modified, got %v and %v", modified, err)
+	}
+	sum, current, modified, err := recheckModified(path, info, partial, Options{RehashModified: true})
+	if err != nil || modified {
+		t.Fatalf("Expected the file to be hashed again, got %v and %v", modified, err)
+	}
+	if sum.hash != hex.EncodeToString(contentSum[:]) || current.Size() != int64(len(content)) {
+		t.Errorf("Expected the hash and size of the whole content, got %s and %d", sum.hash, current.Size())
+	}
+}
@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProcessFolderExcludeNewerThan(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	old, fresh := filepath.Join(dir, hash), filepath.Join(dir, "sub", hash)
+	if err := os.Mkdir(filepath.Dir(fresh), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, path := range []string{old, fresh} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	hourAgo := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, hourAgo, hourAgo); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	progress := &Progress{}
+	result, err := ProcessFolder(dir, Options{Workers: 2, ExcludeNewerThan: time.Minute, Progress: progress})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 || result.SkippedTooNew != 1 {
+		t.Errorf("Expected the recent file to be skipped, got %d files, %d intact and %d too new", result.TotalFiles, result.IntactFiles, result.SkippedTooNew)
+	}
+	if snapshot := progress.Snapshot(); snapshot.Files != 1 {
+		t.Errorf("Expected the progress to count only the old file, got %d", snapshot.Files)
+	}
+
+	result, err = ProcessManifest("SHA256SUMS", []ManifestEntry{{Path: old, Hash: hash}, {Path: fresh, Hash: hash}}, Options{Workers: 2, ExcludeNewerThan: time.Minute})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.SkippedTooNew != 1 {
+		t.Errorf("Expected the recent manifest entry to be skipped, got %d intact and %d too new", result.IntactFiles, result.SkippedTooNew)
+	}
+}
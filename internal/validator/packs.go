@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"os"
+	"sort"
+	"sync"
+)
+
+// PackResult is the outcome of verifying the chunks stored in pack files, as
+// content-defined chunking backup tools store them.
+type PackResult struct {
+	TotalPacks   int `json:"total_packs"`
+	IntactPacks  int `json:"intact_packs"`
+	TotalChunks  int `json:"total_chunks"`
+	IntactChunks int `json:"intact_chunks"`
+	// CorruptedPacks are the packs with at least one corrupted chunk, with
+	// only those chunks listed.
+	CorruptedPacks []ChunkResult `json:"corrupted_packs"`
+	MissingPacks   []string      `json:"missing_packs"`
+	Errors         []ErroredFile `json:"errors"`
+}
+
+// VerifyPacks verifies the chunks of every pack with VerifyChunks, checking
+// opts.Workers packs at a time. Packs that do not exist are reported as
+// missing, and packs that cannot be read, or whose chunks overlap, as
+// errors. The packs of every list are sorted by path.
+func VerifyPacks(packs map[string][]Chunk, opts Options) *PackResult {
+	result := &PackResult{TotalPacks: len(packs)}
+	var mu sync.Mutex
+	pathChan := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < max(opts.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range pathChan {
+				chunks := packs[path]
+				checked, err := VerifyChunks(path, chunks, opts)
+				mu.Lock()
+				result.TotalChunks += len(chunks)
+				switch {
+				case os.IsNotExist(err):
+					result.MissingPacks = append(result.MissingPacks, path)
+				case err != nil:
+					result.Errors = append(result.Errors, ErroredFile{FilePath: path, Error: err.Error()})
+				case len(checked.CorruptedChunks) > 0:
+					result.IntactChunks += checked.IntactChunks
+					result.CorruptedPacks = append(result.CorruptedPacks, *checked)
+				default:
+					result.IntactChunks += checked.IntactChunks
+					result.IntactPacks++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for path := range packs {
+		pathChan <- path
+	}
+	close(pathChan)
+	wg.Wait()
+
+	sort.Strings(result.MissingPacks)
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].FilePath < result.Errors[j].FilePath })
+	sort.Slice(result.CorruptedPacks, func(i, j int) bool {
+		return result.CorruptedPacks[i].FilePath < result.CorruptedPacks[j].FilePath
+	})
+	return result
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// validExpected reports whether the expected hash can be checked: a SHA256
+// hash, or as many hex characters as opts.HashPrefixLen when it is set.
+func (opts Options) validExpected(expectedHash string) bool {
+	if opts.HashPrefixLen == 0 {
+		return isValidSha256(expectedHash)
+	}
+	return len(expectedHash) == opts.HashPrefixLen && hexPattern.MatchString(expectedHash)
+}
+
+// hashMatches reports whether the actual hash matches the expected one, or
+// only starts with it when opts.HashPrefixLen is set.
+func (opts Options) hashMatches(expectedHash, actualHash string) bool {
+	if opts.HashPrefixLen == 0 {
+		return expectedHash == actualHash
+	}
+	return len(actualHash) >= opts.HashPrefixLen && actualHash[:opts.HashPrefixLen] == expectedHash
+}
+
+// shardIncluded reports whether the file is in one of the shards of
+// opts.HashPrefixes: whether its expected hash, or its name when the hash is
+// only known once the file is checked, starts with one of the prefixes.
+// Every file is when there are no prefixes.
+func (opts Options) shardIncluded(entry fileEntry) bool {
+	if len(opts.HashPrefixes) == 0 {
+		return true
+	}
+	name := entry.expectedHash
+	if !entry.hasExpected {
+		name = strings.ToLower(filepath.Base(entry.path))
+	}
+	for _, prefix := range opts.HashPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
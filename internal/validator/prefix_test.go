@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderHashPrefixLen(t *testing.T) {
+	dir := t.TempDir()
+	const hash = "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	for _, name := range []string{
+		hash[:16],
+		"0000000000000000",
+		hash,
+		hash[:32],
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2, HashPrefixLen: 16})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.CorruptedFiles != 1 || result.InvalidFiles != 2 {
+		t.Errorf("Expected 1 intact, 1 corrupted and 2 invalid files, got %d, %d and %d", result.IntactFiles, result.CorruptedFiles, result.InvalidFiles)
+	}
+	if result.AlgorithmMismatches != 0 {
+		t.Errorf("Expected names of other lengths to be invalid with a prefix length, got %v", result.AlgorithmMismatchList)
+	}
+	if len(result.CorruptedFileList) == 1 && result.CorruptedFileList[0].ActualHash != hash {
+		t.Errorf("Expected the full actual hash to be reported, got %s", result.CorruptedFileList[0].ActualHash)
+	}
+}
+
+func TestProcessFolderHashPrefixes(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		"6AE8A75555209FD6C44157C0AED8016E763FF435A19CF186F76863140143FF72.bak",
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"00b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2, HashPrefixes: []string{"6a", "00b"}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 3 || result.IntactFiles != 1 || result.CorruptedFiles != 1 || result.InvalidFiles != 1 {
+		t.Errorf("Expected 3 files of the shards, 1 intact, 1 corrupted and 1 invalid, got %+v", result)
+	}
+
+	entries := []ManifestEntry{
+		{Path: filepath.Join(dir, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"), Hash: "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"},
+		{Path: filepath.Join(dir, "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"), Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	result, err = ProcessManifest("SHA256SUMS", entries, Options{Workers: 1, HashPrefixes: []string{"6a"}})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 {
+		t.Errorf("Expected the file listed with a hash of the shard to be checked, got %+v", result)
+	}
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// Progress counts the files of a run as they are found and checked. It is
+// safe for concurrent use, and its methods do nothing on a nil Progress.
+type Progress struct {
+	mu     sync.Mutex
+	folder string
+
+	found      atomic.Int64
+	foundBytes atomic.Int64
+	done       atomic.Int64
+	doneBytes  atomic.Int64
+	corrupted  atomic.Int64
+	walked     atomic.Bool
+}
+
+// ProgressSnapshot is the state of a Progress at one point in time. The
+// totals are only final once Walked is set.
+type ProgressSnapshot struct {
+	Folder    string
+	Files     int64
+	Bytes     int64
+	DoneFiles int64
+	DoneBytes int64
+	Corrupted int64
+	Walked    bool
+}
+
+// Snapshot returns the current counts.
+func (p *Progress) Snapshot() ProgressSnapshot {
+	if p == nil {
+		return ProgressSnapshot{}
+	}
+	p.mu.Lock()
+	folder := p.folder
+	p.mu.Unlock()
+	return ProgressSnapshot{
+		Folder:    folder,
+		Files:     p.found.Load(),
+		Bytes:     p.foundBytes.Load(),
+		DoneFiles: p.done.Load(),
+		DoneBytes: p.doneBytes.Load(),
+		Corrupted: p.corrupted.Load(),
+		Walked:    p.walked.Load(),
+	}
+}
+
+// start resets the counts for the folder.
+func (p *Progress) start(folder string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.folder = folder
+	p.mu.Unlock()
+	p.found.Store(0)
+	p.foundBytes.Store(0)
+	p.done.Store(0)
+	p.doneBytes.Store(0)
+	p.corrupted.Store(0)
+	p.walked.Store(false)
+}
+
+func (p *Progress) addFound(size int64) {
+	if p == nil {
+		return
+	}
+	p.found.Add(1)
+	p.foundBytes.Add(size)
+}
+
+func (p *Progress) addDone(size int64) {
+	if p == nil {
+		return
+	}
+	p.done.Add(1)
+	p.doneBytes.Add(size)
+}
+
+func (p *Progress) addCorrupted() {
+	if p == nil {
+		return
+	}
+	p.corrupted.Add(1)
+}
+
+func (p *Progress) walkDone() {
+	if p == nil {
+		return
+	}
+	p.walked.Store(true)
+}
+
+// countFiles walks root like ProcessFolder and adds every file that will be
+// handed to the workers to the totals of opts.Progress.
+func countFiles(ctx context.Context, walk func(string, filepath.WalkFunc) error, root string, skipper fileSkipper, opts Options) {
+	walk(root, func(path string, info os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return nil
+		}
+		if skip, err := opts.skipHidden(root, path, info); skip {
+			return err
+		}
+		if skip, err := opts.skipNested(root, path, info); skip {
+			return err
+		}
+		if isLockFile(root, path) {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if skipper != nil && skipper.skips(path) || !opts.sizeIncluded(info.Size()) || opts.tooNew(info) {
+			return nil
+		}
+		opts.Progress.addFound(info.Size())
+		return nil
+	})
+	opts.Progress.walkDone()
+}
@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// listingVolume simulates network storage that is slow to list directories,
+// pausing the walk before every directory, and reads files with a latency.
+type listingVolume struct {
+	latentVolume
+	listing time.Duration
+}
+
+func (v *listingVolume) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			time.Sleep(v.listing)
+		}
+		return fn(path, info, err)
+	})
+}
+
+func TestProcessFolderQueueDepth(t *testing.T) {
+	dir := writeLocalityTree(t)
+	for _, depth := range []int{0, 1, DefaultQueueDepth} {
+		result, err := ProcessFolder(dir, Options{Workers: 3, QueueDepth: depth})
+		if err != nil {
+			t.Fatalf("ProcessFolder with a queue of %d failed: %v", depth, err)
+		}
+		if result.TotalFiles != 32 || result.IntactFiles != 32 {
+			t.Errorf("Expected 32 intact files with a queue of %d, got %d of %d", depth, result.IntactFiles, result.TotalFiles)
+		}
+	}
+}
+
+func benchmarkQueueDepth(b *testing.B, depth int) {
+	dir := writeLocalityTree(b)
+	opts := Options{Workers: 2, QueueDepth: depth, Source: &listingVolume{latentVolume{latency: time.Millisecond}, 20 * time.Millisecond}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := ProcessFolder(dir, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result.IntactFiles != 32 {
+			b.Fatalf("Expected 32 intact files, got %d", result.IntactFiles)
+		}
+	}
+}
+
+// BenchmarkQueueUnbuffered lists every directory while the workers wait for
+// its files, so the listings and the reads add up.
+func BenchmarkQueueUnbuffered(b *testing.B) {
+	benchmarkQueueDepth(b, 0)
+}
+
+// BenchmarkQueueDefault lists the next directories while the workers read
+// the files already found.
+func BenchmarkQueueDefault(b *testing.B) {
+	benchmarkQueueDepth(b, DefaultQueueDepth)
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// limiterChunk is the largest read passed through the limiter at once, so
+// that workers take turns instead of one worker reserving a huge burst.
+const limiterChunk = 64 << 10
+
+// Limiter is a token bucket that caps the combined read rate of all workers.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a limiter allowing bytesPerSecond bytes to be read per second.
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	rate := float64(bytesPerSecond)
+	return &Limiter{rate: rate, burst: max(rate/10, limiterChunk), last: time.Now()}
+}
+
+// wait blocks until n bytes may be read.
+func (l *Limiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+type limitedReader struct {
+	r       io.Reader
+	limiter *Limiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > limiterChunk {
+		p = p[:limiterChunk]
+	}
+	n, err := lr.r.Read(p)
+	lr.limiter.wait(n)
+	return n, err
+}
+
+// limitReader wraps r so that reads are throttled by the limiter, if there is one.
+func limitReader(r io.Reader, limiter *Limiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &limitedReader{r: r, limiter: limiter}
+}
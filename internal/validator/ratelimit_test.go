@@ -0,0 +1,34 @@
+package validator
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterAccuracy(t *testing.T) {
+	const rate = 4 << 20
+	const workers = 4
+	const perWorker = 512 << 10
+	limiter := NewLimiter(rate)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			io.Copy(io.Discard, limitReader(bytes.NewReader(make([]byte, perWorker)), limiter))
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	// The bucket starts empty, so every byte is paced at the rate.
+	want := time.Duration(float64(workers*perWorker) / rate * float64(time.Second))
+	if elapsed < want*9/10 || elapsed > want*5/4 {
+		t.Errorf("Reading %d bytes took %v, want about %v", workers*perWorker, elapsed, want)
+	}
+}
@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// skipNested reports whether the walk should leave out path because it is a
+// directory below root and opts.NoRecurse is set, returning filepath.SkipDir
+// to prune it. Only the files directly in root are walked then.
+func (opts Options) skipNested(root, path string, info os.FileInfo) (skip bool, err error) {
+	if !opts.NoRecurse || path == root || !info.IsDir() {
+		return false, nil
+	}
+	return true, filepath.SkipDir
+}
+
+// walked returns the paths that a walk of root with opts would have visited,
+// so that files below root are not reported missing with opts.NoRecurse.
+func (opts Options) walked(root string, paths []string) []string {
+	if !opts.NoRecurse {
+		return paths
+	}
+	var top []string
+	for _, path := range paths {
+		if filepath.Dir(path) == filepath.Clean(root) {
+			top = append(top, path)
+		}
+	}
+	return top
+}
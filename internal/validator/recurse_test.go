@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderNoRecurse(t *testing.T) {
+	root := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	for _, rel := range []string{
+		hash,
+		filepath.Join("data", hash),
+		filepath.Join("data", "nested", hash),
+	} {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(root, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 3 {
+		t.Errorf("Expected subdirectories to be walked by default, got %d files", result.TotalFiles)
+	}
+
+	progress := &Progress{}
+	result, err = ProcessFolder(root, Options{Workers: 2, NoRecurse: true, RecordFiles: true, Progress: progress})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 || result.Files[0].FilePath != filepath.Join(root, hash) {
+		t.Errorf("Expected only the top-level file to be checked, got %d files: %v", result.TotalFiles, result.Files)
+	}
+	if s := progress.Snapshot(); s.Files != 1 {
+		t.Errorf("Expected the progress walk to skip subdirectories too, got %d files", s.Files)
+	}
+
+	index := IndexSource{hash: hash, "data/" + hash: hash, "gone": hash}
+	result, err = ProcessFolder(root, Options{Workers: 2, NoRecurse: true, HashSource: index})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.MissingFiles != 1 || result.MissingFileList[0] != filepath.Join(root, "gone") {
+		t.Errorf("Expected only the top-level index entry to be missing, got %v", result.MissingFileList)
+	}
+}
@@ -0,0 +1,55 @@
+package validator
+
+import "sort"
+
+// Orders of the file lists of a result in Options.OutputSort.
+const (
+	SortPath   = "path"
+	SortSize   = "size"
+	SortStatus = "status"
+)
+
+// sortLists sorts the file lists of the result in the order of
+// opts.OutputSort. Every list is sorted by path, and the lists of corrupted,
+// ignored and recorded files first by size, largest first, or by status
+// when that is the order. The status of a corrupted or ignored file is the
+// reason it was not hashed, if any. Lists written to Options.Lists are
+// left as they were found.
+func (r *Result) sortLists(opts Options) {
+	if r.lists != nil {
+		return
+	}
+	for _, list := range [][]string{r.InvalidFileList, r.MissingFileList, r.NotIndexed, r.BrokenSymlinkList, r.ModifiedFileList} {
+		sort.Strings(list)
+	}
+	for _, list := range [][]ErroredFile{r.PathErrorList, r.ErroredFileList, r.DecryptErrorList} {
+		sort.Slice(list, func(i, j int) bool { return list[i].FilePath < list[j].FilePath })
+	}
+	for _, list := range [][]AlgorithmMismatch{r.AlgorithmMismatchList, r.AlternateMatchList} {
+		sort.Slice(list, func(i, j int) bool { return list[i].FilePath < list[j].FilePath })
+	}
+	sort.Slice(r.HardLinks, func(i, j int) bool { return r.HardLinks[i].FilePath < r.HardLinks[j].FilePath })
+
+	for _, list := range [][]CorruptedFile{r.CorruptedFileList, r.IgnoredFileList} {
+		sort.Slice(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			switch {
+			case opts.OutputSort == SortSize && a.Size != b.Size:
+				return a.Size > b.Size
+			case opts.OutputSort == SortStatus && a.Reason != b.Reason:
+				return a.Reason < b.Reason
+			}
+			return a.FilePath < b.FilePath
+		})
+	}
+	sort.Slice(r.Files, func(i, j int) bool {
+		a, b := r.Files[i], r.Files[j]
+		switch {
+		case opts.OutputSort == SortSize && a.Size != b.Size:
+			return a.Size > b.Size
+		case opts.OutputSort == SortStatus && a.Status != b.Status:
+			return a.Status < b.Status
+		}
+		return a.FilePath < b.FilePath
+	})
+}
@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessFolderOutputSort(t *testing.T) {
+	dir := t.TempDir()
+	// Corrupted files of different sizes, written in no particular order.
+	for name, size := range map[string]int{
+		"a000000000000000000000000000000000000000000000000000000000000000": 10,
+		"b000000000000000000000000000000000000000000000000000000000000000": 30,
+		"c000000000000000000000000000000000000000000000000000000000000000": 20,
+		"not-a-hash-2": 1,
+		"not-a-hash-1": 1,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	for _, test := range []struct {
+		order string
+		want  string
+	}{
+		{"", "abc"},
+		{SortPath, "abc"},
+		{SortSize, "bca"},
+		{SortStatus, "abc"},
+	} {
+		result, err := ProcessFolder(dir, Options{Workers: 4, OutputSort: test.order})
+		if err != nil {
+			t.Fatalf("ProcessFolder failed: %v", err)
+		}
+		var got string
+		for _, file := range result.CorruptedFileList {
+			got += filepath.Base(file.FilePath)[:1]
+		}
+		if got != test.want {
+			t.Errorf("Expected corrupted files %s sorted by %q, got %s", test.want, test.order, got)
+		}
+		if len(result.InvalidFileList) != 2 || filepath.Base(result.InvalidFileList[0]) != "not-a-hash-1" {
+			t.Errorf("Expected invalid files sorted by path, got %v", result.InvalidFileList)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 1, OutputSort: SortSize})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if len(result.CorruptedFileList) > 0 && result.CorruptedFileList[0].Size != 30 {
+		t.Errorf("Expected the size of the largest corrupted file, got %d", result.CorruptedFileList[0].Size)
+	}
+}
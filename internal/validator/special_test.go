@@ -0,0 +1,149 @@
+//go:build unix
+
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestProcessFolderSkipsFIFO(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	if err := os.WriteFile(filepath.Join(dir, hash), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	// Nothing ever writes to the FIFO, so opening it for reading would block.
+	fifo := filepath.Join(dir, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	if err := syscall.Mkfifo(fifo, 0o644); err != nil {
+		t.Skipf("Creating a FIFO is not supported: %v", err)
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.SkippedSpecial != 1 {
+		t.Errorf("Expected 1 skipped special file, got %d", result.SkippedSpecial)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 {
+		t.Errorf("Expected only the regular file to be validated, got %d total and %d intact", result.TotalFiles, result.IntactFiles)
+	}
+}
+
+func TestProcessFolderBrokenSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	target := filepath.Join(dir, hash)
+	if err := os.WriteFile(target, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	dangling := filepath.Join(dir, "dangling")
+	if err := os.Symlink(filepath.Join(dir, "gone"), dangling); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.BrokenSymlinks != 0 {
+		t.Errorf("Expected symlink targets to be left alone by default, got %d broken", result.BrokenSymlinks)
+	}
+
+	result, err = ProcessFolder(dir, Options{Workers: 2, CheckSymlinks: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.BrokenSymlinks != 1 || len(result.BrokenSymlinkList) != 1 || result.BrokenSymlinkList[0] != dangling {
+		t.Errorf("Expected %s to be reported as broken, got %v", dangling, result.BrokenSymlinkList)
+	}
+	if result.SkippedSpecial != 2 || result.TotalFiles != 1 {
+		t.Errorf("Expected both symlinks to still be skipped, got %d skipped and %d checked", result.SkippedSpecial, result.TotalFiles)
+	}
+}
+
+func TestProcessManifestSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	dangling := filepath.Join(dir, "dangling")
+	if err := os.Symlink(filepath.Join(dir, "gone"), dangling); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	contentHash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	linkSum := sha256.Sum256([]byte(target))
+	linkHash := hex.EncodeToString(linkSum[:])
+	entries := []ManifestEntry{{Path: link, Hash: contentHash}, {Path: dangling, Hash: contentHash}}
+
+	result, err := ProcessManifest("SHA256SUMS", entries, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.IntactFiles != 1 {
+		t.Errorf("Expected the target of the link to be hashed, got %d intact", result.IntactFiles)
+	}
+	if result.MissingFiles != 1 || result.BrokenSymlinks != 1 || len(result.BrokenSymlinkList) != 1 || result.BrokenSymlinkList[0] != dangling {
+		t.Errorf("Expected %s to be missing and reported as broken, got %d missing and %v", dangling, result.MissingFiles, result.BrokenSymlinkList)
+	}
+
+	entries = []ManifestEntry{{Path: link, Hash: linkHash}, {Path: dangling, Hash: contentHash}}
+	result, err = ProcessManifest("SHA256SUMS", entries, Options{Workers: 2, HashLinks: true})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.CorruptedFiles != 1 || result.CorruptedFileList[0].FilePath != dangling {
+		t.Errorf("Expected the links themselves to be hashed, got %d intact and %v", result.IntactFiles, result.CorruptedFileList)
+	}
+	if result.MissingFiles != 0 || result.BrokenSymlinks != 0 {
+		t.Errorf("Expected no missing files or broken links, got %d and %d", result.MissingFiles, result.BrokenSymlinks)
+	}
+}
+
+func TestProcessFolderSymlinkedRoot(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	store := filepath.Join(dir, "store")
+	if err := os.MkdirAll(filepath.Join(store, "6a"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(store, "6a", hash), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(store, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	result, err := ProcessFolder(link, Options{Workers: 2, RecordFiles: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 {
+		t.Errorf("Expected the file of the target to be validated, got %d total and %d intact", result.TotalFiles, result.IntactFiles)
+	}
+	if want := filepath.Join(link, "6a", hash); len(result.Files) != 1 || result.Files[0].FilePath != want {
+		t.Errorf("Expected the file to be reported as %s, got %v", want, result.Files)
+	}
+
+	stat, err := Stat(link, false, Options{})
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if stat.TotalFiles != 1 {
+		t.Errorf("Expected 1 file below the symlink, got %d", stat.TotalFiles)
+	}
+}
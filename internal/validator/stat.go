@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StatResult is an inventory of the files of a folder, taken without reading
+// them.
+type StatResult struct {
+	FolderPath     string          `json:"folder_path"`
+	TotalFiles     int             `json:"total_files"`
+	TotalBytes     int64           `json:"total_bytes"`
+	ExcludedFiles  int             `json:"excluded_files"`
+	SkippedSpecial int             `json:"skipped_special"`
+	Extensions     []ExtensionStat `json:"extensions,omitempty"`
+	SizeHistogram  []SizeBucket    `json:"size_histogram,omitempty"`
+}
+
+// ExtensionStat is the number of files with an extension and their size. The
+// extension of files without one is empty.
+type ExtensionStat struct {
+	Extension string `json:"extension"`
+	Files     int    `json:"files"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// Stat counts the regular files of the folder that would be checked and adds
+// up their sizes without reading any. Excluded and hidden files, files in
+// subdirectories with opts.NoRecurse and files outside the size range are
+// skipped as by ProcessFolder, but opts.ExcludeTypes has no effect. With extensions, the
+// files are also counted by extension, largest first, and with
+// opts.SizeHistogram by size.
+func Stat(folderPath string, extensions bool, opts Options) (*StatResult, error) {
+	result := &StatResult{FolderPath: folderPath}
+	if opts.SizeHistogram {
+		result.SizeHistogram = newSizeHistogram()
+	}
+	byExtension := make(map[string]*ExtensionStat)
+
+	err := walkFolder(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip, err := opts.skipHidden(folderPath, path, info); skip {
+			return err
+		}
+		if skip, err := opts.skipNested(folderPath, path, info); skip {
+			return err
+		}
+		if isLockFile(folderPath, path) || info.IsDir() {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			result.SkippedSpecial++
+			return nil
+		}
+		if (opts.Exclude != nil && opts.Exclude.MatchString(path)) || !opts.sizeIncluded(info.Size()) || opts.tooNew(info) {
+			result.ExcludedFiles++
+			return nil
+		}
+
+		result.TotalFiles++
+		result.TotalBytes += info.Size()
+		if opts.SizeHistogram {
+			result.SizeHistogram[sizeBucketIndex(info.Size())].Count++
+		}
+		if extensions {
+			ext := strings.ToLower(filepath.Ext(path))
+			stat := byExtension[ext]
+			if stat == nil {
+				stat = &ExtensionStat{Extension: ext}
+				byExtension[ext] = stat
+			}
+			stat.Files++
+			stat.Bytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, walkError(folderPath, err)
+	}
+
+	for _, stat := range byExtension {
+		result.Extensions = append(result.Extensions, *stat)
+	}
+	sort.Slice(result.Extensions, func(i, j int) bool {
+		a, b := result.Extensions[i], result.Extensions[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Extension < b.Extension
+	})
+	return result, nil
+}
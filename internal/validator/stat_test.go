@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72": "test content",
+		"data/a.json": "{}",
+		"data/b.JSON": "[1]",
+		"data/c.pack": "packed",
+		"config":      "excluded",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := Stat(dir, true, Options{Exclude: regexp.MustCompile(`config$`), SizeHistogram: true})
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if result.TotalFiles != 4 || result.TotalBytes != 23 || result.ExcludedFiles != 1 {
+		t.Errorf("Expected 4 files of 23 bytes and 1 excluded, got %+v", result)
+	}
+	want := []ExtensionStat{{"", 1, 12}, {".pack", 1, 6}, {".json", 2, 5}}
+	if len(result.Extensions) != len(want) {
+		t.Fatalf("Expected extensions %v, got %v", want, result.Extensions)
+	}
+	for i := range want {
+		if result.Extensions[i] != want[i] {
+			t.Errorf("Expected extensions %v, got %v", want, result.Extensions)
+			break
+		}
+	}
+	if result.SizeHistogram[0].Count != 4 {
+		t.Errorf("Expected 4 files below 1KiB, got %v", result.SizeHistogram)
+	}
+}
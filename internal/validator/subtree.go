@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// KeepUnder removes the entries outside the subtree at the relative path dir
+// of the folder from the lists of the result, keeping the counts of the whole
+// folder, and records dir in Result.ReportedUnder.
+func (r *Result) KeepUnder(dir string) {
+	dir = filepath.Clean(dir)
+	under := func(path string) bool {
+		rel, err := filepath.Rel(r.FolderPath, path)
+		if err != nil {
+			return false
+		}
+		return dir == "." || rel == dir || strings.HasPrefix(rel, dir+string(filepath.Separator))
+	}
+	filePath := func(path string) string { return path }
+	r.ReportedUnder = dir
+	r.CorruptedFileList = keepUnder(r.CorruptedFileList, func(f CorruptedFile) string { return f.FilePath }, under)
+	r.InvalidFileList = keepUnder(r.InvalidFileList, filePath, under)
+	r.AlgorithmMismatchList = keepUnder(r.AlgorithmMismatchList, func(f AlgorithmMismatch) string { return f.FilePath }, under)
+	r.AlternateMatchList = keepUnder(r.AlternateMatchList, func(f AlgorithmMismatch) string { return f.FilePath }, under)
+	r.MissingFileList = keepUnder(r.MissingFileList, filePath, under)
+	r.NotIndexed = keepUnder(r.NotIndexed, filePath, under)
+	r.IgnoredFileList = keepUnder(r.IgnoredFileList, func(f CorruptedFile) string { return f.FilePath }, under)
+	r.HardLinks = keepUnder(r.HardLinks, func(l HardLink) string { return l.FilePath }, under)
+	r.EmptyDirs = keepUnder(r.EmptyDirs, filePath, under)
+	r.BrokenSymlinkList = keepUnder(r.BrokenSymlinkList, filePath, under)
+	r.PathErrorList = keepUnder(r.PathErrorList, func(f ErroredFile) string { return f.FilePath }, under)
+	r.ModifiedFileList = keepUnder(r.ModifiedFileList, filePath, under)
+	r.ErroredFileList = keepUnder(r.ErroredFileList, func(f ErroredFile) string { return f.FilePath }, under)
+	r.DecryptErrorList = keepUnder(r.DecryptErrorList, func(f ErroredFile) string { return f.FilePath }, under)
+	r.Files = keepUnder(r.Files, func(f FileRecord) string { return f.FilePath }, under)
+}
+
+// keepUnder returns the entries of the list whose path is under the subtree,
+// reusing the list.
+func keepUnder[T any](list []T, path func(T) string, under func(string) bool) []T {
+	kept := list[:0]
+	for _, entry := range list {
+		if under(path(entry)) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
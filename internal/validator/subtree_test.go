@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeepUnder(t *testing.T) {
+	root := t.TempDir()
+	corrupted := "0000000000000000000000000000000000000000000000000000000000000000"
+	for _, path := range []string{
+		filepath.Join("a", "b", corrupted),
+		filepath.Join("a", "bc", corrupted),
+		filepath.Join("c", corrupted),
+		filepath.Join("a", "b", "readme"),
+	} {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(root, Options{Workers: 2, RecordFiles: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	result.KeepUnder(filepath.Join("a", "b"))
+	if result.CorruptedFiles != 3 || result.InvalidFiles != 1 || result.ReportedUnder != filepath.Join("a", "b") {
+		t.Errorf("Expected the counts of the whole folder, got %d corrupted and %d invalid files under %q", result.CorruptedFiles, result.InvalidFiles, result.ReportedUnder)
+	}
+	if len(result.CorruptedFileList) != 1 || result.CorruptedFileList[0].FilePath != filepath.Join(root, "a", "b", corrupted) {
+		t.Errorf("Expected only the corrupted file below a/b to be listed, got %v", result.CorruptedFileList)
+	}
+	if len(result.InvalidFileList) != 1 || len(result.Files) != 2 {
+		t.Errorf("Expected 1 invalid file and 2 file records below a/b, got %v and %v", result.InvalidFileList, result.Files)
+	}
+}
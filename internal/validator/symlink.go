@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkTarget returns the directory that the folder points to when the
+// folder itself is a symlink to a directory, which filepath.Walk would not
+// follow.
+func SymlinkTarget(folderPath string) (string, bool) {
+	info, err := os.Lstat(folderPath)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(folderPath)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return target, true
+}
+
+// walkFolder walks the folder like filepath.Walk, walking the target of the
+// folder when it is a symlink to a directory. The paths below the target are
+// reported below the folder.
+func walkFolder(folderPath string, fn filepath.WalkFunc) error {
+	target, ok := SymlinkTarget(folderPath)
+	if !ok {
+		return filepath.Walk(folderPath, fn)
+	}
+	return filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+		return fn(folderPath+strings.TrimPrefix(path, target), info, err)
+	})
+}
+
+// checkSymlink records the file as a broken symlink when it is a symlink
+// whose target does not exist. The target is only looked up with
+// Options.CheckSymlinks, and not for folders of a FileSource.
+func (r *Result) checkSymlink(path string, info os.FileInfo, opts Options) {
+	if !opts.CheckSymlinks || opts.Source != nil || info.Mode()&os.ModeSymlink == 0 {
+		return
+	}
+	statPath := path
+	if opts.LongPaths {
+		statPath = longPath(path)
+	}
+	if _, err := os.Stat(statPath); !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.BrokenSymlinks++
+	addToList(r, &r.BrokenSymlinkList, "broken_symlink_list", path)
+}
+
+// isSymlink reports whether info is that of a symlink, which checkFile only
+// gets for a manifest entry with Options.HashLinks.
+func isSymlink(info os.FileInfo) bool {
+	return info != nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// hashLink hashes the symlink itself, that is the path it points to.
+func hashLink(filePath string, opts Options) (fileSum, error) {
+	openPath := filePath
+	if opts.LongPaths {
+		openPath = longPath(filePath)
+	}
+	target, err := os.Readlink(openPath)
+	if err != nil {
+		return fileSum{}, err
+	}
+	sum := sha256.Sum256([]byte(target))
+	return fileSum{hash: hex.EncodeToString(sum[:]), size: int64(len(target))}, nil
+}
+
+// brokenLink reports whether the path, which does not exist as far as
+// os.Stat can tell, is a symlink whose target does not exist.
+func brokenLink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
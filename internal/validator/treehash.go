@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// notHashed stands for the hash of a file in the tree hash when the file was
+// not hashed, because its name is not a hash or its size is wrong.
+const notHashed = "-"
+
+// addTreeEntry adds the file to the tree hash with its hash, or notHashed.
+// The caller holds r.mu.
+func (r *Result) addTreeEntry(opts Options, filePath, hash string) {
+	if !opts.TreeHash {
+		return
+	}
+	if rel, err := filepath.Rel(opts.folder, filePath); err == nil {
+		filePath = rel
+	}
+	r.tree = append(r.tree, hash+"  "+filepath.ToSlash(filePath)+"\n")
+}
+
+// finishTree sets Result.TreeHash with opts.TreeHash, and compares it with
+// opts.ExpectTreeHash. The tree hash is only known for a run that checked
+// every file without errors.
+func (r *Result) finishTree(opts Options) {
+	if !opts.TreeHash {
+		return
+	}
+	r.ExpectedTreeHash = opts.ExpectTreeHash
+	if r.StoppedEarly || r.ErroredFiles > 0 || r.DecryptErrors > 0 || r.PathErrors > 0 || r.ModifiedFiles > 0 {
+		r.TreeHashMismatch = opts.ExpectTreeHash != ""
+		return
+	}
+	sort.Strings(r.tree)
+	sum := sha256.Sum256([]byte(strings.Join(r.tree, "")))
+	r.tree = nil
+	r.TreeHash = hex.EncodeToString(sum[:])
+	r.TreeHashMismatch = opts.ExpectTreeHash != "" && r.TreeHash != opts.ExpectTreeHash
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderTreeHash(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	write := func(dir, name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	treeHash := func(dir string, expect string) *Result {
+		result, err := ProcessFolder(dir, Options{Workers: 2, TreeHash: true, ExpectTreeHash: expect})
+		if err != nil {
+			t.Fatalf("ProcessFolder failed: %v", err)
+		}
+		return result
+	}
+
+	dir := t.TempDir()
+	write(dir, filepath.Join("6a", hash), "test content")
+	write(dir, "notes.txt", "not a hash")
+	sum := sha256.Sum256([]byte("-  notes.txt\n" + hash + "  6a/" + hash + "\n"))
+	want := hex.EncodeToString(sum[:])
+	if result := treeHash(dir, want); result.TreeHash != want || result.TreeHashMismatch {
+		t.Errorf("Expected tree hash %s, got %s", want, result.TreeHash)
+	}
+
+	// The same files elsewhere have the same tree hash.
+	copied := t.TempDir()
+	write(copied, filepath.Join("6a", hash), "test content")
+	write(copied, "notes.txt", "not a hash")
+	if result := treeHash(copied, want); result.TreeHashMismatch {
+		t.Errorf("Expected the copy to have tree hash %s, got %s", want, result.TreeHash)
+	}
+
+	write(copied, filepath.Join("6a", hash), "changed content")
+	changed := treeHash(copied, want)
+	if !changed.TreeHashMismatch || changed.TreeHash == want || changed.ExpectedTreeHash != want {
+		t.Errorf("Expected a changed file to change the tree hash, got %s", changed.TreeHash)
+	}
+	write(dir, "added.txt", "")
+	if result := treeHash(dir, want); !result.TreeHashMismatch {
+		t.Errorf("Expected an added file to change the tree hash, got %s", result.TreeHash)
+	}
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// nfcResolver finds files whose name differs from the wanted one only in
+// Unicode normalization, such as a decomposed (NFD) name written on macOS
+// that is listed in its composed (NFC) form.
+type nfcResolver struct {
+	mu sync.Mutex
+	// dirs maps a directory to its entries keyed by their NFC name.
+	dirs map[string]map[string]string
+}
+
+func newNFCResolver() *nfcResolver {
+	return &nfcResolver{dirs: make(map[string]map[string]string)}
+}
+
+// resolve returns the path of the existing file that matches path after
+// normalizing every element to NFC, or path itself if there is none.
+func (r *nfcResolver) resolve(path string) string {
+	if _, err := os.Lstat(path); err == nil {
+		return path
+	}
+	dir, name := filepath.Split(path)
+	dir = filepath.Clean(dir)
+	if dir != path {
+		dir = r.resolve(dir)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names, ok := r.dirs[dir]
+	if !ok {
+		names = make(map[string]string)
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			names[norm.NFC.String(entry.Name())] = entry.Name()
+		}
+		r.dirs[dir] = names
+	}
+	if actual, ok := names[norm.NFC.String(name)]; ok {
+		return filepath.Join(dir, actual)
+	}
+	return filepath.Join(dir, name)
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+const (
+	composed   = "caf\u00e9"
+	decomposed = "cafe\u0301"
+)
+
+func TestProcessManifestNormalizeUnicode(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, decomposed), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, decomposed, decomposed+".txt"), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, composed)); err == nil {
+		t.Skip("The file system does not distinguish normalization forms")
+	}
+	entries := []ManifestEntry{{
+		Path: filepath.Join(dir, composed, composed+".txt"),
+		Hash: "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+	}}
+
+	result, err := ProcessManifest("SHA256SUMS", entries, Options{Workers: 1})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.MissingFiles != 1 {
+		t.Errorf("Expected the composed name to be missing without normalization, got %d missing", result.MissingFiles)
+	}
+
+	result, err = ProcessManifest("SHA256SUMS", entries, Options{Workers: 1, NormalizeUnicode: true})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.MissingFiles != 0 {
+		t.Errorf("Expected the decomposed file to be found intact, got %d intact and %d missing", result.IntactFiles, result.MissingFiles)
+	}
+}
+
+func TestExpectedHashOfNormalizeUnicode(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	opts := Options{NamePattern: regexp.MustCompile("^" + composed + "_(?P<hash>[a-f0-9]+)$")}
+	filePath := filepath.Join("store", decomposed+"_"+hash)
+
+	if got := expectedHashOf(filePath, opts); got != "" {
+		t.Errorf("Expected a decomposed name not to match without normalization, got %q", got)
+	}
+	opts.NormalizeUnicode = true
+	if got := expectedHashOf(filePath, opts); got != hash {
+		t.Errorf("Expected %s, got %q", hash, got)
+	}
+}
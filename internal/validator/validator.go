@@ -1,62 +1,811 @@
 package validator
 
 import (
+	"bytes"
+	"context"
+	"crypto/cipher"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMMapThreshold is the minimum file size memory-mapped when Options.MMap is set.
+const DefaultMMapThreshold = 64 << 20
+
+// DefaultQueueDepth is the default of Options.QueueDepth used by the command
+// line. A queued file is its path and file info, a few hundred bytes, so the
+// queue stays well below a MiB.
+const DefaultQueueDepth = 1024
+
+// sniffLen is the number of leading bytes used to detect the content type.
+const sniffLen = 512
+
+// File statuses recorded in FileRecord.Status.
+const (
+	StatusIntact    = "intact"
+	StatusCorrupted = "corrupted"
+	StatusInvalid   = "invalid"
+	StatusIgnored   = "ignored"
+	// StatusAlgorithmMismatch marks a file named after the hash of another algorithm.
+	StatusAlgorithmMismatch = "algorithm_mismatch"
+	// StatusModified marks a file that did not match but changed while it was hashed.
+	StatusModified = "modified"
 )
 
 type Result struct {
-	FolderPath        string          `json:"folder_path"`
-	TotalFiles        int             `json:"total_files"`
-	IntactFiles       int             `json:"intact_files"`
-	CorruptedFiles    int             `json:"corrupted_files"`
-	CorruptedFileList []CorruptedFile `json:"corrupted_file_list"`
-	InvalidFiles      int             `json:"invalid_files"`
-	InvalidFileList   []string        `json:"invalid_file_list"`
+	FolderPath            string              `json:"folder_path"`
+	ToolVersion           string              `json:"tool_version,omitempty"`
+	RunID                 string              `json:"run_id,omitempty"`
+	StartedAt             string              `json:"started_at,omitempty"`
+	FinishedAt            string              `json:"finished_at,omitempty"`
+	Status                string              `json:"status,omitempty"`
+	Failed                bool                `json:"failed"`
+	ReportedUnder         string              `json:"reported_under,omitempty"`
+	TotalFiles            int                 `json:"total_files"`
+	IntactFiles           int                 `json:"intact_files"`
+	CorruptedFiles        int                 `json:"corrupted_files"`
+	IntactBytes           int64               `json:"intact_bytes"`
+	CorruptedBytes        int64               `json:"corrupted_bytes"`
+	CorruptedFileList     []CorruptedFile     `json:"corrupted_file_list,omitempty"`
+	InvalidFiles          int                 `json:"invalid_files"`
+	InvalidFileList       []string            `json:"invalid_file_list,omitempty"`
+	AlgorithmMismatches   int                 `json:"algorithm_mismatches"`
+	AlgorithmMismatchList []AlgorithmMismatch `json:"algorithm_mismatch_list,omitempty"`
+	AlternateMatches      int                 `json:"alternate_matches"`
+	AlternateMatchList    []AlgorithmMismatch `json:"alternate_match_list,omitempty"`
+	MissingFiles          int                 `json:"missing_files"`
+	MissingFileList       []string            `json:"missing_file_list,omitempty"`
+	TotalSizeMismatch     bool                `json:"total_size_mismatch,omitempty"`
+	ExpectedTotalBytes    int64               `json:"expected_total_bytes,omitempty"`
+	PresentBytes          int64               `json:"present_bytes,omitempty"`
+	TreeHash              string              `json:"tree_hash,omitempty"`
+	ExpectedTreeHash      string              `json:"expected_tree_hash,omitempty"`
+	TreeHashMismatch      bool                `json:"tree_hash_mismatch,omitempty"`
+	NotIndexedFiles       int                 `json:"not_indexed_files"`
+	NotIndexed            []string            `json:"not_indexed,omitempty"`
+	CorruptionRate        float64             `json:"corruption_rate"`
+	InvalidRate           float64             `json:"invalid_rate"`
+	IgnoredFiles          int                 `json:"ignored_files"`
+	IgnoredFileList       []CorruptedFile     `json:"ignored_file_list,omitempty"`
+	TrustedFiles          int                 `json:"trusted_files"`
+	SkippedSpecial        int                 `json:"skipped_special"`
+	BrokenSymlinks        int                 `json:"broken_symlinks"`
+	BrokenSymlinkList     []string            `json:"broken_symlink_list,omitempty"`
+	SkippedSize           int                 `json:"skipped_size"`
+	SkippedByType         int                 `json:"skipped_by_type"`
+	SkippedTooNew         int                 `json:"skipped_too_new"`
+	SkippedByBudget       int                 `json:"skipped_by_budget"`
+	SkippedBudgetBytes    int64               `json:"skipped_budget_bytes"`
+	BudgetCoverage        float64             `json:"budget_coverage,omitempty"`
+	StoppedEarly          bool                `json:"stopped_early"`
+	DeadlineExceeded      bool                `json:"deadline_exceeded"`
+	HashedBytes           int64               `json:"hashed_bytes"`
+	DedupedFiles          int                 `json:"deduped_files"`
+	DedupedBytes          int64               `json:"deduped_bytes"`
+	ContentCacheHits      int                 `json:"content_cache_hits"`
+	ContentCacheBytes     int64               `json:"content_cache_bytes"`
+	HardLinks             []HardLink          `json:"hard_links,omitempty"`
+	EmptyDirs             []string            `json:"empty_dirs,omitempty"`
+	PathErrors            int                 `json:"path_errors"`
+	PathErrorList         []ErroredFile       `json:"path_error_list,omitempty"`
+	ModifiedFiles         int                 `json:"modified_files"`
+	ModifiedFileList      []string            `json:"modified_file_list,omitempty"`
+	ErroredFiles          int                 `json:"errored_files"`
+	ErroredFileList       []ErroredFile       `json:"errored_file_list,omitempty"`
+	DecryptErrors         int                 `json:"decrypt_errors"`
+	DecryptErrorList      []ErroredFile       `json:"decrypt_error_list,omitempty"`
+	SizeHistogram         []SizeBucket        `json:"size_histogram,omitempty"`
+	WorkerStats           []WorkerStat        `json:"worker_stats,omitempty"`
+	Files                 []FileRecord        `json:"files,omitempty"`
+
+	mu sync.Mutex
+	// tree holds the lines of the tree hash until the run is done.
+	tree []string
+	// lists receives the entries of the file lists instead of the result
+	// when Options.Lists is set.
+	lists ListWriter
 }
 
 type CorruptedFile struct {
-	FilePath   string `json:"file_path"`
-	ActualHash string `json:"actual_hash"`
+	FilePath     string `json:"file_path"`
+	Size         int64  `json:"size"`
+	ExpectedHash string `json:"expected_hash,omitempty"`
+	ActualHash   string `json:"actual_hash"`
+	ContentType  string `json:"content_type,omitempty"`
+	// Reason explains why a file is corrupted when it was not hashed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ErroredFile is a file that could not be validated because of an error.
+type ErroredFile struct {
+	FilePath string `json:"file_path"`
+	Error    string `json:"error"`
+}
+
+// HardLink is a file that was not hashed because it shares its inode with an
+// already hashed file.
+type HardLink struct {
+	FilePath string `json:"file_path"`
+	Original string `json:"original"`
+}
+
+// FileRecord captures the state of a single file at the time it was validated.
+type FileRecord struct {
+	FilePath    string    `json:"file_path"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	Status      string    `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+}
+
+// FileSource provides the files of a folder that is not on the local file system.
+type FileSource interface {
+	Walk(root string, fn filepath.WalkFunc) error
+	Open(path string) (io.ReadCloser, error)
+}
+
+// Options controls how ProcessFolder walks a folder and validates its files.
+type Options struct {
+	// Exclude skips files whose path matches. Nothing is excluded when it is nil.
+	Exclude *regexp.Regexp
+	Workers int
+	// MMap memory-maps files of at least MMapThreshold bytes instead of streaming them.
+	MMap          bool
+	MMapThreshold int64
+	// SizeHistogram buckets every validated file by size into Result.SizeHistogram.
+	SizeHistogram bool
+	// WorkerStats counts the files and bytes checked by every worker in
+	// Result.WorkerStats, to tell how evenly the work was spread.
+	WorkerStats bool
+	// IgnoreHashes lists expected hashes of files that are known to be corrupted.
+	// Such files are reported under Result.IgnoredFileList instead of as corrupted.
+	IgnoreHashes map[string]bool
+	// RecordFiles records the size, modification time and status of every
+	// validated file in Result.Files.
+	RecordFiles bool
+	// Previous holds the files of an earlier report. Intact files whose size and
+	// modification time are unchanged are trusted without being hashed again.
+	Previous map[string]FileRecord
+	// LongPaths walks and opens files through extended-length paths on Windows
+	// so that relative roots with paths beyond MAX_PATH can be read. It has no
+	// effect on other platforms.
+	LongPaths bool
+	// DirWorkers limits the number of workers checking files below the
+	// top-level directories of the folder named by its keys, such as the
+	// shards of a store on different mounts, to the given number each. A
+	// worker waiting for a slot does not take other files meanwhile. Files of
+	// other directories are only limited by Workers. It has no effect on
+	// ProcessManifest.
+	DirWorkers map[string]int
+	dirSlots   *dirSlots
+	// CheckSymlinks looks up the target of every symlink, which is
+	// still skipped, and lists those whose target does not exist in
+	// Result.BrokenSymlinkList.
+	CheckSymlinks bool
+	// HashLinks hashes the symlinks listed in a manifest themselves, that is
+	// the paths they point to, instead of their targets. It only has an
+	// effect on ProcessManifest.
+	HashLinks bool
+	// LocalityAware hands the files of a directory to a single worker, which
+	// reads them in order, instead of spreading them across all workers.
+	LocalityAware bool
+	// QueueDepth is the number of files the walk can find ahead of the
+	// workers, so that a walk that is slow at times, such as one listing
+	// directories on network storage, keeps the workers busy. In LocalityAware
+	// mode it counts directories rather than files. With 0, the walk waits for
+	// a worker to take every file.
+	QueueDepth int
+	// DedupInodes hashes files sharing an inode only once and records the
+	// other links in Result.HardLinks.
+	DedupInodes bool
+	inodes      *inodeCache
+	// ContentCache trusts the hash of an earlier file of the same size whose
+	// first and last KiB hash the same instead of reading a file in full,
+	// counting the file in Result.ContentCacheHits. A file that differs
+	// from the earlier file only between its first and last KiB is then
+	// taken for it: a damaged copy is reported intact, and an intact file
+	// with other content is reported corrupted. It is only meant for
+	// stores with many copies of the same content.
+	ContentCache bool
+	contents     *contentCache
+	// HashWorkers hashes the files read by the Workers on this many separate
+	// goroutines when it is positive, so that the number of concurrent reads
+	// and of CPUs busy hashing can be tuned independently. Otherwise every
+	// worker hashes what it reads.
+	HashWorkers int
+	// HashAffinity pins every hash worker to the CPUs of one NUMA node,
+	// spreading the workers over the nodes. It is experimental and has no
+	// effect without HashWorkers or on platforms other than Linux.
+	HashAffinity bool
+	hashers      *hashPool
+	// NamePattern extracts the expected hash from the file name through its
+	// named group "hash". The whole file name is the expected hash when it is nil.
+	NamePattern *regexp.Regexp
+	// HashSource provides the expected hash of every file of a folder instead
+	// of the file name. Files it has no hash for are listed in
+	// Result.NotIndexed. The file name, through NamePattern, is used when it is nil.
+	HashSource HashSource
+	// folder is the folder being processed, for the HashSource.
+	folder string
+	// NormalizeUnicode normalizes file names to NFC before they are matched
+	// against NamePattern, and finds manifest entries whose names on disk are
+	// in a different normalization form.
+	NormalizeUnicode bool
+	// DetectType sniffs the content type of every hashed file and records it
+	// in Result.CorruptedFileList, Result.IgnoredFileList and Result.Files.
+	DetectType bool
+	// ReportEmptyDirs lists the directories that contain no files after
+	// exclusions, directly or below them, in Result.EmptyDirs.
+	ReportEmptyDirs bool
+	// LimitFiles stops the run once this many files have been validated,
+	// marking the result with StoppedEarly. There is no limit when it is 0.
+	LimitFiles int
+	// Deadline stops the run once it has passed, marking the result with
+	// StoppedEarly and DeadlineExceeded. Files being hashed at that point are
+	// not counted. There is no deadline when it is zero.
+	Deadline time.Time
+	// Context interrupts the run once it is done, marking the result with
+	// StoppedEarly. Files being hashed at that point are not counted.
+	Context context.Context
+	// Decrypt decrypts every file with the cipher before it is hashed, so
+	// that encrypted files are checked against the hash of their plaintext.
+	// Files that fail authentication are reported in Result.DecryptErrorList.
+	Decrypt cipher.AEAD
+	// ParallelSize is the size from which VerifyFile hashes a file with up to
+	// Workers goroutines, for algorithms whose hashes of separate parts can
+	// be combined, which only BLAKE3 allows. Smaller files, and files hashed
+	// with other algorithms, SHA256 included, are hashed serially. Parallel
+	// hashing is off when it is zero.
+	ParallelSize int64
+	// DecryptLimit is the size of the largest file that is decrypted, since
+	// files are decrypted in memory. Larger files are reported in
+	// Result.DecryptErrorList without being read. DefaultDecryptLimit is
+	// used when it is zero.
+	DecryptLimit int64
+	// Progress counts the files as they are found and checked when set.
+	Progress *Progress
+	// SkipHidden leaves out files and directories whose name starts with a
+	// dot, pruning hidden directories with everything below them.
+	SkipHidden bool
+	// NoRecurse walks only the files directly in the folder, skipping all
+	// subdirectories.
+	NoRecurse bool
+	// HashPrefixLen compares only the first HashPrefixLen hex characters of
+	// the actual hash with the expected hash, for names made of a truncated
+	// hash. Expected hashes of any other length are invalid. 0 compares the
+	// full hash.
+	HashPrefixLen int
+	// TryAlgorithms hashes the files whose full hash names the SHA256 hash of
+	// their content does not match with these algorithms of
+	// AlternateAlgorithms, whose hashes are as long. A file matching one of
+	// them is intact, and listed in Result.AlternateMatchList.
+	TryAlgorithms []string
+	// RehashModified hashes a file that did not match once more when its
+	// size or modification time changed while it was hashed, instead of
+	// listing it in Result.ModifiedFileList right away. It is only listed
+	// there when it changes again.
+	RehashModified bool
+	// Lists receives the entries of the file lists of the result as they are
+	// found, instead of the result keeping them.
+	Lists ListWriter
+	// ExcludeTypes skips files whose content type, sniffed from their first
+	// bytes, is one of these media types, such as application/json or image/*.
+	ExcludeTypes []string
+	// MinSize and MaxSize skip files smaller or larger than them, in bytes,
+	// counting them in Result.SkippedSize. MaxSize is no limit when it is 0.
+	MinSize int64
+	MaxSize int64
+	// HashPrefixes, in lower-case hex, checks only the files whose name, or
+	// expected hash from a manifest, starts with one of them. Every file is
+	// checked when it is empty.
+	HashPrefixes []string
+	// TreeHash sets Result.TreeHash to the SHA256 hash of the sorted lines
+	// "<hash>  <path>" of every checked file, with paths relative to the
+	// folder, so that any added, removed or changed file changes it. Files
+	// that were not hashed are listed with "-" instead of their hash. With
+	// ExpectTreeHash, Result.TreeHashMismatch is set when the tree hash
+	// differs from it or could not be computed.
+	TreeHash       bool
+	ExpectTreeHash string
+	// OutputSort is the order of the file lists of the result: SortPath,
+	// SortSize or SortStatus. The lists are sorted by path when it is empty.
+	OutputSort string
+	// ExcludeNewerThan skips files modified less than this long ago, which may
+	// still be being written, counting them in Result.SkippedTooNew. Nothing
+	// is skipped when it is 0.
+	ExcludeNewerThan time.Duration
+	// ByteBudget checks the files in ascending order of size, and only as many
+	// as fit into this many bytes, counting the others in
+	// Result.SkippedByBudget. The files are held back until the walk is done
+	// to be sorted, which disables LocalityAware. There is no budget when it
+	// is 0.
+	ByteBudget int64
+	// ExpectedTotal is the sum of the sizes of the files of a manifest given
+	// by its header. ProcessManifest compares it with the sizes of the files
+	// found when HasExpectedTotal is set, setting Result.TotalSizeMismatch.
+	ExpectedTotal    int64
+	HasExpectedTotal bool
+	// Limiter caps the combined read rate of all workers when set.
+	Limiter *Limiter
+	// Verified is called with the path and hash of every file that was hashed and found intact.
+	Verified func(filePath, hash string)
+	// Corrupted is called with the path, expected and actual hash of every
+	// corrupted file that is not ignored. It must not block.
+	Corrupted func(filePath, expectedHash, actualHash string)
+	// Checked is called with the record of every file given a status, its
+	// expected hash, or "" for an invalid name, and its actual hash, or "-"
+	// when it was not hashed. It is called from the workers, without the
+	// result locked, so it may block and must be safe for concurrent use.
+	Checked func(record FileRecord, expectedHash, actualHash string)
+	// Source walks and opens the folder's files. The local file system is used when it is nil.
+	Source FileSource
+}
+
+type fileEntry struct {
+	path string
+	info os.FileInfo
+	// expectedHash overrides the hash derived from the file name when hasExpected is set.
+	expectedHash string
+	hasExpected  bool
+	// expectedSize is compared with the file's size before hashing when hasSize is set.
+	expectedSize int64
+	hasSize      bool
 }
 
 // ValidateFile checks if the file is valid and calculates the SHA256 hash of the file
 func ValidateFile(filePath string, result *Result) {
-	expectedHash := filepath.Base(filePath)
+	validateFile(filePath, nil, result, Options{})
+}
+
+func validateFile(filePath string, info os.FileInfo, result *Result, opts Options) {
+	expectedHash, ok, err := opts.hashSource().ExpectedHash(opts.folder, filePath, opts)
+	if err != nil {
+		result.addError(filePath, err)
+		return
+	}
+	if !ok {
+		result.addNotIndexed(filePath)
+		return
+	}
+	checkFile(filePath, expectedHash, info, result, opts)
+}
+
+// checkFile compares the hash of the file's content with the expected hash.
+func checkFile(filePath, expectedHash string, info os.FileInfo, result *Result, opts Options) {
+	result.mu.Lock()
 	result.TotalFiles++
-	if !isValidSha256(expectedHash) {
+	if opts.SizeHistogram && info != nil {
+		result.SizeHistogram[sizeBucketIndex(info.Size())].Count++
+	}
+	// With truncated hashes, names of any other length are invalid.
+	if algorithm, ok := otherAlgorithm(expectedHash); ok && opts.HashPrefixLen == 0 {
+		result.AlgorithmMismatches++
+		addToList(result, &result.AlgorithmMismatchList, "algorithm_mismatch_list", AlgorithmMismatch{FilePath: filePath, Algorithm: algorithm})
+		checked := result.addFile(opts, filePath, info, StatusAlgorithmMismatch, expectedHash, notHashed, "")
+		result.mu.Unlock()
+		checked.report(opts)
+		return
+	}
+	if !opts.validExpected(expectedHash) {
 		result.InvalidFiles++
-		result.InvalidFileList = append(result.InvalidFileList, filePath)
+		addToList(result, &result.InvalidFileList, "invalid_file_list", filePath)
+		checked := result.addFile(opts, filePath, info, StatusInvalid, "", notHashed, "")
+		result.mu.Unlock()
+		checked.report(opts)
+		return
+	}
+	if isTrusted(opts.Previous, filePath, info) {
+		result.IntactFiles++
+		result.IntactBytes += info.Size()
+		result.TrustedFiles++
+		checked := result.addFile(opts, filePath, info, StatusIntact, expectedHash, expectedHash, "")
+		result.mu.Unlock()
+		checked.report(opts)
 		return
 	}
+	result.mu.Unlock()
 
-	file, err := os.Open(filePath)
+	var sum fileSum
+	var original string
+	var cached bool
+	var err error
+	link := isSymlink(info)
+	// A change is judged against the file as it was right before it was
+	// hashed rather than when the walk found it, which may be long before.
+	baseline := info
+	if !link {
+		if current, _ := modifiedSince(filePath, info, opts); current != nil {
+			baseline = current
+		}
+	}
+	hashFn := func() (fileSum, error) {
+		if link {
+			return hashLink(filePath, opts)
+		}
+		return hashFile(filePath, opts)
+	}
+	if opts.contents != nil && !link {
+		hashContent := hashFn
+		hashFn = func() (fileSum, error) {
+			sum, hit, err := opts.contents.hash(filePath, info, opts, hashContent)
+			cached = hit
+			return sum, err
+		}
+	}
+	if opts.inodes != nil {
+		sum, original, err = opts.inodes.hash(filePath, info, hashFn)
+	} else {
+		sum, err = hashFn()
+	}
+	// A file written while it was read does not match, but is not corrupted.
+	var modified bool
+	if err == nil && !link && !opts.hashMatches(expectedHash, sum.hash) {
+		sum, info, modified, err = recheckModified(filePath, baseline, sum, opts)
+	}
+	var algorithm string
+	if err == nil && !link && !modified && opts.tryAlgorithms(expectedHash, sum.hash) {
+		algorithm, err = matchingAlgorithm(filePath, expectedHash, opts)
+	}
 	if err != nil {
-		fmt.Printf("Error opening file %s: %v\n", filePath, err)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			result.unstart(info, opts)
+			return
+		}
+		if errors.Is(err, ErrDecrypt) || errors.Is(err, ErrDecryptTooLarge) {
+			result.addDecryptError(filePath, err)
+			return
+		}
+		if isPathTooLong(err) {
+			result.addPathError(filePath, err)
+			return
+		}
+		result.addError(filePath, err)
 		return
 	}
+
+	var checked checkedFile
+	result.mu.Lock()
+	defer func() {
+		result.mu.Unlock()
+		checked.report(opts)
+	}()
+	actualHash := sum.hash
+	result.HashedBytes += sum.size
+	if original != "" {
+		result.DedupedFiles++
+		result.DedupedBytes += info.Size()
+		addToList(result, &result.HardLinks, "hard_links", HardLink{FilePath: filePath, Original: original})
+	}
+	if cached {
+		result.ContentCacheHits++
+		result.ContentCacheBytes += info.Size()
+	}
+	// The size on disk counts rather than what was hashed, which differs for
+	// decrypted files.
+	size := sum.size
+	if info != nil {
+		size = info.Size()
+	}
+	if modified {
+		result.ModifiedFiles++
+		addToList(result, &result.ModifiedFileList, "modified_file_list", filePath)
+		checked = result.addFile(opts, filePath, info, StatusModified, expectedHash, actualHash, sum.contentType)
+	} else if opts.hashMatches(expectedHash, actualHash) {
+		result.IntactFiles++
+		result.IntactBytes += size
+		checked = result.addFile(opts, filePath, info, StatusIntact, expectedHash, actualHash, sum.contentType)
+		if opts.Verified != nil {
+			opts.Verified(filePath, actualHash)
+		}
+	} else if algorithm != "" {
+		result.IntactFiles++
+		result.IntactBytes += size
+		result.AlternateMatches++
+		addToList(result, &result.AlternateMatchList, "alternate_match_list", AlgorithmMismatch{FilePath: filePath, Algorithm: algorithm})
+		checked = result.addFile(opts, filePath, info, StatusIntact, expectedHash, actualHash, sum.contentType)
+		if opts.Verified != nil {
+			opts.Verified(filePath, actualHash)
+		}
+	} else if opts.IgnoreHashes[expectedHash] {
+		result.IgnoredFiles++
+		addToList(result, &result.IgnoredFileList, "ignored_file_list", CorruptedFile{FilePath: filePath, Size: size, ExpectedHash: expectedHash, ActualHash: actualHash, ContentType: sum.contentType})
+		checked = result.addFile(opts, filePath, info, StatusIgnored, expectedHash, actualHash, sum.contentType)
+	} else {
+		result.CorruptedFiles++
+		result.CorruptedBytes += size
+		opts.Progress.addCorrupted()
+		addToList(result, &result.CorruptedFileList, "corrupted_file_list", CorruptedFile{FilePath: filePath, Size: size, ExpectedHash: expectedHash, ActualHash: actualHash, ContentType: sum.contentType})
+		checked = result.addFile(opts, filePath, info, StatusCorrupted, expectedHash, actualHash, sum.contentType)
+		if opts.Corrupted != nil {
+			opts.Corrupted(filePath, expectedHash, actualHash)
+		}
+	}
+}
+
+// addSizeMismatch records a file whose size differs from the expected one as
+// corrupted without hashing it.
+func (r *Result) addSizeMismatch(entry fileEntry, opts Options) {
+	reason := fmt.Sprintf("size mismatch: expected %d bytes, got %d", entry.expectedSize, entry.info.Size())
+	file := CorruptedFile{FilePath: entry.path, Size: entry.info.Size(), ExpectedHash: entry.expectedHash, Reason: reason}
+
+	var checked checkedFile
+	r.mu.Lock()
+	defer func() {
+		r.mu.Unlock()
+		checked.report(opts)
+	}()
+	r.TotalFiles++
+	if opts.SizeHistogram {
+		r.SizeHistogram[sizeBucketIndex(entry.info.Size())].Count++
+	}
+	if opts.IgnoreHashes[entry.expectedHash] {
+		r.IgnoredFiles++
+		addToList(r, &r.IgnoredFileList, "ignored_file_list", file)
+		checked = r.addFile(opts, entry.path, entry.info, StatusIgnored, entry.expectedHash, notHashed, "")
+		return
+	}
+	r.CorruptedFiles++
+	r.CorruptedBytes += entry.info.Size()
+	opts.Progress.addCorrupted()
+	addToList(r, &r.CorruptedFileList, "corrupted_file_list", file)
+	checked = r.addFile(opts, entry.path, entry.info, StatusCorrupted, entry.expectedHash, notHashed, "")
+	if opts.Corrupted != nil {
+		opts.Corrupted(entry.path, entry.expectedHash, "")
+	}
+}
+
+func (r *Result) addPathError(filePath string, err error) {
+	slog.Warn("path too long", "path", filePath, "error", err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.PathErrors++
+	addToList(r, &r.PathErrorList, "path_error_list", ErroredFile{FilePath: filePath, Error: err.Error()})
+}
+
+// DropFileLists removes the lists of files from the result, keeping the counts.
+func (r *Result) DropFileLists() {
+	r.CorruptedFileList = nil
+	r.InvalidFileList = nil
+	r.AlgorithmMismatchList = nil
+	r.AlternateMatchList = nil
+	r.MissingFileList = nil
+	r.NotIndexed = nil
+	r.IgnoredFileList = nil
+	r.HardLinks = nil
+	r.EmptyDirs = nil
+	r.BrokenSymlinkList = nil
+	r.PathErrorList = nil
+	r.ModifiedFileList = nil
+	r.ErroredFileList = nil
+	r.DecryptErrorList = nil
+	r.Files = nil
+}
+
+// addError records a file that could not be read.
+func (r *Result) addError(filePath string, err error) {
+	slog.Error("reading file failed", "path", filePath, "error", err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ErroredFiles++
+	addToList(r, &r.ErroredFileList, "errored_file_list", ErroredFile{FilePath: filePath, Error: err.Error()})
+}
+
+// skipSpecial records a file that is not a regular file.
+func (r *Result) skipSpecial(path string, info os.FileInfo) {
+	slog.Debug("skipping special file", "path", path, "mode", info.Mode().Type().String())
+	r.mu.Lock()
+	r.SkippedSpecial++
+	r.mu.Unlock()
+}
+
+// sizeIncluded reports whether a file of the size is within opts.MinSize and opts.MaxSize.
+func (opts Options) sizeIncluded(size int64) bool {
+	return size >= opts.MinSize && (opts.MaxSize == 0 || size <= opts.MaxSize)
+}
+
+// tooNew reports whether the file was modified less than
+// opts.ExcludeNewerThan ago, or has a modification time in the future.
+func (opts Options) tooNew(info os.FileInfo) bool {
+	return opts.ExcludeNewerThan > 0 && time.Since(info.ModTime()) < opts.ExcludeNewerThan
+}
+
+// skipTooNew records a file that was modified too recently to be checked.
+func (r *Result) skipTooNew(path string) {
+	slog.Debug("skipping recently modified file", "path", path)
+	r.mu.Lock()
+	r.SkippedTooNew++
+	r.mu.Unlock()
+}
+
+// skipSize records a file that is outside the size range.
+func (r *Result) skipSize() {
+	r.mu.Lock()
+	r.SkippedSize++
+	r.mu.Unlock()
+}
+
+// computeRates derives the corruption and invalid rates from the file counts.
+func (r *Result) computeRates() {
+	if r.TotalFiles == 0 {
+		r.CorruptionRate, r.InvalidRate = 0, 0
+		return
+	}
+	r.CorruptionRate = float64(r.CorruptedFiles) / float64(r.TotalFiles)
+	r.InvalidRate = float64(r.InvalidFiles) / float64(r.TotalFiles)
+}
+
+// mostlyInvalidRate and mostlyInvalidFiles are the invalid rate and number
+// of files from which a result is taken for a misconfigured run.
+const (
+	mostlyInvalidRate  = 0.9
+	mostlyInvalidFiles = 10
+)
+
+// MostlyInvalid reports whether so many of the files have a name that is not
+// a hash that the run was probably pointed at the wrong folder, or the names
+// need other settings such as --name-pattern.
+func (r *Result) MostlyInvalid() bool {
+	return r.InvalidFiles >= mostlyInvalidFiles && r.InvalidRate > mostlyInvalidRate
+}
+
+// addFile records the file's size and modification time when opts.RecordFiles
+// is set, and its hash, or notHashed, for the tree hash. It must be called
+// with r.mu held, and returns the record for opts.Checked, which is to be
+// reported once r.mu is unlocked.
+func (r *Result) addFile(opts Options, filePath string, info os.FileInfo, status, expectedHash, hash, contentType string) checkedFile {
+	r.addTreeEntry(opts, filePath, hash)
+	if info == nil {
+		return checkedFile{}
+	}
+	record := FileRecord{FilePath: filePath, Size: info.Size(), ModTime: info.ModTime(), Status: status, ContentType: contentType}
+	if opts.RecordFiles {
+		addToList(r, &r.Files, "files", record)
+	}
+	return checkedFile{record: &record, expectedHash: expectedHash, hash: hash}
+}
+
+// checkedFile is a file given a status, for opts.Checked.
+type checkedFile struct {
+	record       *FileRecord
+	expectedHash string
+	hash         string
+}
+
+// report hands the file to opts.Checked. It is called without the result
+// locked, so that a slow callback does not hold up the other workers.
+func (c checkedFile) report(opts Options) {
+	if c.record != nil && opts.Checked != nil {
+		opts.Checked(*c.record, c.expectedHash, c.hash)
+	}
+}
+
+// expectedHashOf returns the expected hash encoded in the file name, or an
+// empty string if the name does not match opts.NamePattern.
+func expectedHashOf(filePath string, opts Options) string {
+	name := filepath.Base(filePath)
+	if opts.NormalizeUnicode {
+		name = norm.NFC.String(name)
+	}
+	if opts.NamePattern == nil {
+		return name
+	}
+	match := opts.NamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+	return match[opts.NamePattern.SubexpIndex("hash")]
+}
+
+// fileSum is the result of hashing a file.
+type fileSum struct {
+	hash string
+	size int64
+	// contentType is only detected when Options.DetectType is set.
+	contentType string
+}
+
+// hashFile returns the hex encoded SHA256 hash of the file and the number of bytes hashed. Large files are
+// memory-mapped when opts.MMap is set, falling back to streaming on failure.
+func hashFile(filePath string, opts Options) (fileSum, error) {
+	if opts.Source != nil {
+		return hashSourceFile(filePath, opts)
+	}
+
+	openPath := filePath
+	if opts.LongPaths {
+		openPath = longPath(filePath)
+	}
+	file, err := os.Open(openPath)
+	if err != nil {
+		return fileSum{}, err
+	}
+	defer file.Close()
+
+	if opts.Decrypt != nil {
+		if info, err := file.Stat(); err == nil {
+			if err := checkDecryptSize(info.Size(), opts); err != nil {
+				return fileSum{}, err
+			}
+		}
+		return decryptDigest(file, opts)
+	}
+	if opts.MMap {
+		info, err := file.Stat()
+		if err == nil && info.Size() > 0 && info.Size() >= opts.MMapThreshold {
+			data, err := mmapFile(file, info.Size())
+			if err == nil {
+				defer munmapFile(data)
+				return digest(bytes.NewReader(data), opts)
+			}
+		}
+	}
+	return digest(file, opts)
+}
+
+func hashSourceFile(filePath string, opts Options) (fileSum, error) {
+	file, err := opts.Source.Open(filePath)
+	if err != nil {
+		return fileSum{}, err
+	}
 	defer file.Close()
+	if opts.Decrypt != nil {
+		return decryptDigest(file, opts)
+	}
+	return digest(file, opts)
+}
 
+// digest hashes everything read from r. With Options.DetectType, the content
+// type is sniffed from the bytes as they are hashed, so the file is read once.
+func digest(r io.Reader, opts Options) (fileSum, error) {
+	r = withCancel(withDeadline(r, opts.Deadline), opts.Context)
+	if opts.hashers != nil {
+		return opts.hashers.digest(r, opts)
+	}
 	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		fmt.Printf("Error calculating SHA256 hash for file %s: %v\n", filePath, err)
-		return
+	var w io.Writer = hash
+	var head *sniffer
+	if opts.DetectType {
+		head = &sniffer{}
+		w = io.MultiWriter(hash, head)
+	}
+	n, err := io.Copy(w, limitReader(r, opts.Limiter))
+	if err != nil {
+		return fileSum{}, err
+	}
+	sum := fileSum{hash: hex.EncodeToString(hash.Sum(nil)), size: n}
+	if head != nil {
+		sum.contentType = sniffType(head.buf)
 	}
+	return sum, nil
+}
 
-	actualHash := hex.EncodeToString(hash.Sum(nil))
+// sniffer keeps the first bytes written to it, as many as http.DetectContentType considers.
+type sniffer struct {
+	buf []byte
+}
 
-	if expectedHash == actualHash {
-		result.IntactFiles++
-	} else {
-		result.CorruptedFiles++
-		result.CorruptedFileList = append(result.CorruptedFileList, CorruptedFile{FilePath: filePath, ActualHash: actualHash})
+func (s *sniffer) Write(p []byte) (int, error) {
+	if n := min(sniffLen-len(s.buf), len(p)); n > 0 {
+		s.buf = append(s.buf, p[:n]...)
 	}
+	return len(p), nil
+}
+
+// IsValidSha256 reports whether hash is a lower-case hex encoded SHA256 hash.
+func IsValidSha256(hash string) bool {
+	return isValidSha256(hash)
 }
 
 func isValidSha256(hash string) bool {
@@ -72,41 +821,236 @@ func isValidSha256(hash string) bool {
 	return true
 }
 
-func ProcessFolder(folderPath string, exclude *regexp.Regexp, numWorkers int) (*Result, error) {
-	result := &Result{FolderPath: folderPath}
-
+// startWorkers starts opts.Workers workers, at least one, validating the batches sent on fileChan.
+// The returned wait group is done once fileChan is closed and drained. Once
+// opts.LimitFiles files have been validated, the workers mark the result as
+// stopped early, call stop and skip the remaining files; they also skip them
+// once ctx is done.
+func startWorkers(ctx context.Context, stop context.CancelFunc, fileChan <-chan []fileEntry, result *Result, opts Options) *sync.WaitGroup {
 	var wg sync.WaitGroup
-	fileChan := make(chan string)
-
-	for i := 0; i < numWorkers; i++ {
+	var claimed atomic.Int64
+	workers := max(opts.Workers, 1)
+	if opts.WorkerStats {
+		result.WorkerStats = newWorkerStats(workers)
+	}
+	// Without a worker, the walk would block forever on its first file.
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for filePath := range fileChan {
-				if !exclude.MatchString(filePath) {
-					ValidateFile(filePath, result)
+			for batch := range fileChan {
+				for _, entry := range batch {
+					if ctx.Err() != nil {
+						continue
+					}
+					if opts.Exclude != nil && opts.Exclude.MatchString(entry.path) {
+						slog.Debug("skipping excluded file", "path", entry.path)
+						opts.Progress.addDone(entry.info.Size())
+						continue
+					}
+					if !opts.shardIncluded(entry) {
+						opts.Progress.addDone(entry.info.Size())
+						continue
+					}
+					// Files of an excluded type are sniffed before they take a
+					// slot of --limit-files.
+					if excluded, contentType := opts.typeExcluded(entry.path); excluded {
+						result.skipType(entry.path, contentType)
+						opts.Progress.addDone(entry.info.Size())
+						continue
+					}
+					if opts.LimitFiles > 0 && claimed.Add(1) > int64(opts.LimitFiles) {
+						result.mu.Lock()
+						result.StoppedEarly = true
+						result.mu.Unlock()
+						stop()
+						continue
+					}
+					release := opts.dirSlots.acquire(entry.path)
+					if entry.hasSize && entry.info.Size() != entry.expectedSize {
+						result.addSizeMismatch(entry, opts)
+					} else if entry.hasExpected {
+						checkFile(entry.path, entry.expectedHash, entry.info, result, opts)
+					} else {
+						validateFile(entry.path, entry.info, result, opts)
+					}
+					release()
+					if result.WorkerStats != nil {
+						result.WorkerStats[i].Files++
+						result.WorkerStats[i].Bytes += entry.info.Size()
+					}
+					opts.Progress.addDone(entry.info.Size())
 				}
 			}
 		}()
 	}
+	return &wg
+}
+
+func ProcessFolder(folderPath string, opts Options) (*Result, error) {
+	result := &Result{FolderPath: folderPath, lists: opts.Lists}
+	if opts.SizeHistogram {
+		result.SizeHistogram = newSizeHistogram()
+	}
+	if opts.DedupInodes {
+		opts.inodes = newInodeCache()
+	}
+	if opts.ContentCache {
+		opts.contents = newContentCache()
+	}
+	if opts.HashWorkers > 0 {
+		opts.hashers = newHashPool(opts.HashWorkers, opts.HashAffinity)
+		defer opts.hashers.close()
+	}
+
+	opts.folder = folderPath
+	opts.dirSlots = opts.newDirSlots(folderPath)
+	opts.Progress.start(folderPath)
+	source := opts.hashSource()
+	reporter, _ := source.(missingReporter)
+	skipper, _ := source.(fileSkipper)
 
-	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
+	ctx, cancel := runContext(opts)
+	defer cancel()
+	fileChan := make(chan []fileEntry, max(opts.QueueDepth, 0))
+	wg := startWorkers(ctx, cancel, fileChan, result, opts)
+
+	// In locality-aware mode, consecutive files of the same directory are
+	// handed to a single worker as one batch.
+	var batch []fileEntry
+	flush := func() {
+		if len(batch) > 0 {
+			fileChan <- batch
+			batch = nil
+		}
+	}
+
+	walk := filepath.Walk
+	if opts.Source != nil {
+		walk = opts.Source.Walk
+	}
+	root := folderPath
+	if opts.Source == nil {
+		// A folder that is a symlink to a directory is walked through its
+		// target, since the walk does not follow symlinks.
+		if target, ok := SymlinkTarget(folderPath); ok {
+			root = target
+		}
+		if opts.LongPaths {
+			root = longPath(root)
+		}
+	}
+	found := make(map[string]bool)
+	budget := opts.newBudget()
+	var emptyDirs *emptyDirTracker
+	if opts.ReportEmptyDirs {
+		emptyDirs = &emptyDirTracker{}
+	}
+	// The walk is held back by the workers, so the files are counted by a
+	// walk of their own to know the totals early.
+	var counted sync.WaitGroup
+	if opts.Progress != nil {
+		counted.Add(1)
+		go func() {
+			defer counted.Done()
+			countFiles(ctx, walk, root, skipper, opts)
+		}()
+	}
+	err := walk(root, func(path string, info os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		// Report paths below the folder as given rather than below the
+		// extended-length root or the target of a symlinked folder.
+		if root != folderPath {
+			path = folderPath + strings.TrimPrefix(path, root)
+		}
 		if err != nil {
+			if isPathTooLong(err) {
+				result.addPathError(path, err)
+				return nil
+			}
+			return err
+		}
+		if skip, err := opts.skipHidden(folderPath, path, info); skip {
 			return err
 		}
-		if !info.IsDir() {
-			fileChan <- path
+		if skip, err := opts.skipNested(folderPath, path, info); skip {
+			return err
+		}
+		if isLockFile(folderPath, path) {
+			return nil
+		}
+		if emptyDirs != nil {
+			emptyDirs.visit(path, info.IsDir(), opts.Exclude != nil && opts.Exclude.MatchString(path))
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// FIFOs, sockets, devices and symlinks have no content to check, and
+		// reading a FIFO can block forever.
+		if !info.Mode().IsRegular() {
+			result.skipSpecial(path, info)
+			result.checkSymlink(path, info, opts)
+			return nil
 		}
+		if skipper != nil && skipper.skips(path) {
+			return nil
+		}
+		if reporter != nil {
+			found[indexPath(folderPath, path)] = true
+		}
+		// Files outside the size range are dropped here rather than by the
+		// workers, saving the hand-off for files that would be skipped anyway.
+		if !opts.sizeIncluded(info.Size()) {
+			result.skipSize()
+			return nil
+		}
+		if opts.tooNew(info) {
+			result.skipTooNew(path)
+			return nil
+		}
+		if emptyDirs != nil && (opts.Exclude == nil || !opts.Exclude.MatchString(path)) {
+			emptyDirs.addFile()
+		}
+		entry := fileEntry{path: path, info: info}
+		if budget != nil {
+			budget.add(entry)
+			return nil
+		}
+		if !opts.LocalityAware {
+			fileChan <- []fileEntry{entry}
+			return nil
+		}
+		if len(batch) > 0 && filepath.Dir(batch[0].path) != filepath.Dir(path) {
+			flush()
+		}
+		batch = append(batch, entry)
 		return nil
 	})
+	flush()
+	if budget != nil && err == nil {
+		budget.send(ctx, fileChan, result, opts)
+	}
 
 	close(fileChan)
 	wg.Wait()
+	counted.Wait()
 
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return nil, err
+		slog.Error("walking folder failed", "folder", folderPath, "error", err)
+		return nil, walkError(folderPath, err)
+	}
+	result.finishRun(ctx)
+	if emptyDirs != nil && !result.StoppedEarly {
+		result.EmptyDirs = emptyDirs.finish()
+	}
+	if reporter != nil && !result.StoppedEarly {
+		result.addMissing(opts.walked(folderPath, reporter.missing(folderPath, found)))
 	}
+	result.sortLists(opts)
+	result.finishTree(opts)
+	result.computeRates()
 
 	return result, nil
 }
@@ -1,9 +1,20 @@
 package validator
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestIsValidSha256(t *testing.T) {
@@ -75,3 +86,787 @@ func TestValidateFile(t *testing.T) {
 		}
 	})
 }
+
+func writeTestData(t testing.TB, size int) string {
+	filePath := filepath.Join(t.TempDir(), "data")
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		t.Fatalf("Failed to write test data: %v", err)
+	}
+	return filePath
+}
+
+func TestHashFileMmap(t *testing.T) {
+	filePath := writeTestData(t, 1<<20+7)
+
+	streamed, err := hashFile(filePath, Options{})
+	if err != nil {
+		t.Fatalf("Streaming hash failed: %v", err)
+	}
+	mapped, err := hashFile(filePath, Options{MMap: true, MMapThreshold: 1})
+	if err != nil {
+		t.Fatalf("Mmap hash failed: %v", err)
+	}
+	if streamed.hash != mapped.hash {
+		t.Errorf("Mmap hash %s does not match streaming hash %s", mapped.hash, streamed.hash)
+	}
+}
+
+func benchmarkHashFile(b *testing.B, opts Options) {
+	const size = 64 << 20
+	filePath := writeTestData(b, size)
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := hashFile(filePath, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHashFileStream(b *testing.B) {
+	benchmarkHashFile(b, Options{})
+}
+
+func BenchmarkHashFileMmap(b *testing.B) {
+	benchmarkHashFile(b, Options{MMap: true, MMapThreshold: 1})
+}
+
+func TestProcessFolderSizeHistogram(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"small": 10, "medium": 5 << 10, "large": 2 << 20} {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Exclude: regexp.MustCompile("^$"), Workers: 2, SizeHistogram: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+
+	want := map[string]int{"<1KiB": 1, "1-10KiB": 1, "1-10MiB": 1}
+	for _, bucket := range result.SizeHistogram {
+		if bucket.Count != want[bucket.Range] {
+			t.Errorf("Bucket %s has %d files, want %d", bucket.Range, bucket.Count, want[bucket.Range])
+		}
+	}
+}
+
+func TestProcessFolderCurrentDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.bin"), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd failed: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir failed: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	result, err := ProcessFolder(".", Options{Exclude: regexp.MustCompile("^$"), Workers: 1})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if len(result.InvalidFileList) != 1 || result.InvalidFileList[0] != "data.bin" {
+		t.Errorf("Expected data.bin to be reported as found by the walk, got %v", result.InvalidFileList)
+	}
+}
+
+func TestValidateFileIgnoredHash(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	filePath := filepath.Join(t.TempDir(), hash)
+	if err := os.WriteFile(filePath, []byte("placeholder"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result := &Result{}
+	validateFile(filePath, nil, result, Options{IgnoreHashes: map[string]bool{hash: true}})
+	if result.CorruptedFiles != 0 {
+		t.Errorf("Expected 0 corrupted files, got %d", result.CorruptedFiles)
+	}
+	if result.IgnoredFiles != 1 || len(result.IgnoredFileList) != 1 {
+		t.Errorf("Expected 1 ignored file, got %d", result.IgnoredFiles)
+	}
+}
+
+func TestProcessFolderPrevious(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	opts := Options{Exclude: regexp.MustCompile("^$"), Workers: 1}
+
+	unrecorded, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if len(unrecorded.Files) != 0 {
+		t.Fatalf("Expected no recorded files without RecordFiles, got %d", len(unrecorded.Files))
+	}
+
+	opts.RecordFiles = true
+	first, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if first.TrustedFiles != 0 || len(first.Files) != 1 {
+		t.Fatalf("Expected 1 recorded and no trusted files, got %d and %d", len(first.Files), first.TrustedFiles)
+	}
+
+	opts.Previous = PreviousFiles([]*Result{first})
+	second, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if second.TrustedFiles != 1 || second.IntactFiles != 1 {
+		t.Errorf("Expected unchanged file to be trusted, got %d trusted", second.TrustedFiles)
+	}
+	if first.HashedBytes != int64(len("test content")) || second.HashedBytes != 0 {
+		t.Errorf("Expected only the first run to hash bytes, got %d and %d", first.HashedBytes, second.HashedBytes)
+	}
+
+	if err := os.WriteFile(filePath, []byte("changed content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	third, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if third.TrustedFiles != 0 || third.CorruptedFiles != 1 {
+		t.Errorf("Expected changed file to be verified again, got %d trusted and %d corrupted", third.TrustedFiles, third.CorruptedFiles)
+	}
+}
+
+func TestValidateFilePathTooLong(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("name length limits differ on Windows")
+	}
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	dir := t.TempDir()
+
+	// A path component at the usual 255 byte limit is still readable.
+	atLimit := filepath.Join(dir, strings.Repeat("a", 255))
+	if err := os.Mkdir(atLimit, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(atLimit, hash), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	result := &Result{}
+	validateFile(filepath.Join(atLimit, hash), nil, result, Options{})
+	if result.IntactFiles != 1 {
+		t.Errorf("Expected 1 intact file, got %d", result.IntactFiles)
+	}
+
+	result = &Result{}
+	validateFile(filepath.Join(dir, strings.Repeat("a", 256), hash), nil, result, Options{})
+	if result.PathErrors != 1 || len(result.PathErrorList) != 1 {
+		t.Errorf("Expected 1 path error, got %d", result.PathErrors)
+	}
+}
+
+func TestMmapFileTooLarge(t *testing.T) {
+	if runtime.GOOS == "windows" || math.MaxInt > math.MaxInt32 {
+		t.Skip("only 32-bit unix platforms cannot map files of 2 GiB or more")
+	}
+	file, err := os.Open(writeTestData(t, 1))
+	if err != nil {
+		t.Fatalf("Failed to open test data: %v", err)
+	}
+	defer file.Close()
+	if _, err := mmapFile(file, math.MaxInt32+1); err == nil {
+		t.Errorf("Expected mapping a file larger than math.MaxInt to fail")
+	}
+}
+
+func TestValidateFileNamePattern(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "blob_"+hash+"_v1.bin")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	opts := Options{NamePattern: regexp.MustCompile(`^blob_(?P<hash>[a-f0-9]+)_`)}
+
+	result := &Result{}
+	validateFile(filePath, nil, result, opts)
+	if result.IntactFiles != 1 {
+		t.Errorf("Expected 1 intact file, got %d", result.IntactFiles)
+	}
+
+	result = &Result{}
+	validateFile(filepath.Join(dir, hash), nil, result, opts)
+	if result.InvalidFiles != 1 {
+		t.Errorf("Expected a name not matching the pattern to be invalid, got %d invalid", result.InvalidFiles)
+	}
+}
+
+func TestComputeRates(t *testing.T) {
+	result := &Result{}
+	result.computeRates()
+	if result.CorruptionRate != 0 || result.InvalidRate != 0 {
+		t.Errorf("Expected zero rates without files, got %v and %v", result.CorruptionRate, result.InvalidRate)
+	}
+
+	result = &Result{TotalFiles: 8, CorruptedFiles: 2, InvalidFiles: 1}
+	result.computeRates()
+	if result.CorruptionRate != 0.25 || result.InvalidRate != 0.125 {
+		t.Errorf("Expected rates 0.25 and 0.125, got %v and %v", result.CorruptionRate, result.InvalidRate)
+	}
+}
+
+func TestMostlyInvalid(t *testing.T) {
+	tests := []struct {
+		total, invalid int
+		want           bool
+	}{
+		{100, 95, true},
+		{100, 90, false},
+		{5, 5, false},
+		{10, 10, true},
+	}
+	for _, test := range tests {
+		result := &Result{TotalFiles: test.total, InvalidFiles: test.invalid}
+		result.computeRates()
+		if got := result.MostlyInvalid(); got != test.want {
+			t.Errorf("MostlyInvalid() with %d of %d invalid = %v, want %v", test.invalid, test.total, got, test.want)
+		}
+	}
+}
+
+func TestProcessManifest(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	entries := []ManifestEntry{
+		{Path: filePath, Hash: "6AE8A75555209FD6C44157C0AED8016E763FF435A19CF186F76863140143FF72"},
+		{Path: filepath.Join(dir, "gone.txt"), Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+
+	result, err := ProcessManifest("SHA256SUMS", entries, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.IntactFiles != 1 {
+		t.Errorf("Expected 1 intact file, got %d", result.IntactFiles)
+	}
+	if result.MissingFiles != 1 || result.MissingFileList[0] != entries[1].Path {
+		t.Errorf("Expected %s to be missing, got %v", entries[1].Path, result.MissingFileList)
+	}
+}
+
+func TestValidateFileDetectType(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 1024)...)
+	if err := os.WriteFile(filePath, png, 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("Failed to stat test file: %v", err)
+	}
+
+	result := &Result{}
+	validateFile(filePath, info, result, Options{DetectType: true, RecordFiles: true})
+	if len(result.CorruptedFileList) != 1 || result.CorruptedFileList[0].ContentType != "image/png" {
+		t.Fatalf("Expected a corrupted image/png file, got %v", result.CorruptedFileList)
+	}
+	if result.Files[0].ContentType != "image/png" {
+		t.Errorf("Expected the recorded file to be image/png, got %q", result.Files[0].ContentType)
+	}
+	if result.HashedBytes != int64(len(png)) {
+		t.Errorf("Expected %d hashed bytes, got %d", len(png), result.HashedBytes)
+	}
+}
+
+func TestProcessFolderLimitFiles(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d", i)), nil, 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 1, LimitFiles: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 2 || !result.StoppedEarly {
+		t.Errorf("Expected to stop early after 2 files, got %d files and stopped early %v", result.TotalFiles, result.StoppedEarly)
+	}
+
+	result, err = ProcessFolder(dir, Options{Workers: 2, LimitFiles: 5})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 5 || result.StoppedEarly {
+		t.Errorf("Expected all 5 files without stopping early, got %d files and stopped early %v", result.TotalFiles, result.StoppedEarly)
+	}
+}
+
+func TestGenerateManifest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"done", "todo"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	var entries []ManifestEntry
+	skip := func(path string) bool { return filepath.Base(path) == "done" }
+	err := GenerateManifest(dir, skip, Options{Workers: 2}, func(entry ManifestEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateManifest failed: %v", err)
+	}
+	want := ManifestEntry{Path: filepath.Join(dir, "todo"), Hash: "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72", Size: 12, HasSize: true}
+	if len(entries) != 1 || entries[0] != want {
+		t.Errorf("Expected only %v, got %v", want, entries)
+	}
+}
+
+func TestProcessFolderReportEmptyDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"full/nested", "empty/nested", "excluded-only", "skip"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	for _, name := range []string{"full/nested/a", "excluded-only/tmp"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	opts := Options{Workers: 2, ReportEmptyDirs: true, Exclude: regexp.MustCompile(`tmp$|skip$`)}
+	result, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "empty"),
+		filepath.Join(dir, "empty", "nested"),
+		filepath.Join(dir, "excluded-only"),
+	}
+	if strings.Join(result.EmptyDirs, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected empty directories %v, got %v", want, result.EmptyDirs)
+	}
+}
+
+func TestProcessFolderIndex(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"indexed", "unknown"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	index := map[string]string{
+		"indexed":    "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72",
+		"sub/absent": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2, HashSource: IndexSource(index)})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 {
+		t.Errorf("Expected only the indexed file to be validated, got %d total and %d intact", result.TotalFiles, result.IntactFiles)
+	}
+	if len(result.NotIndexed) != 1 || result.NotIndexed[0] != filepath.Join(dir, "unknown") {
+		t.Errorf("Expected unknown not to be indexed, got %v", result.NotIndexed)
+	}
+	if result.MissingFiles != 1 || result.MissingFileList[0] != filepath.Join(dir, "sub", "absent") {
+		t.Errorf("Expected sub/absent to be missing, got %v", result.MissingFileList)
+	}
+}
+
+func TestProcessManifestSizeMismatch(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "truncated")
+	if err := os.WriteFile(filePath, []byte("test"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+
+	result, err := ProcessManifest("SHA256SUMS", []ManifestEntry{{Path: filePath, Hash: hash, Size: 12, HasSize: true}}, Options{Workers: 1})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.CorruptedFiles != 1 || !strings.HasPrefix(result.CorruptedFileList[0].Reason, "size mismatch") {
+		t.Errorf("Expected a size mismatch, got %v", result.CorruptedFileList)
+	}
+	if result.HashedBytes != 0 {
+		t.Errorf("Expected the file not to be hashed, got %d hashed bytes", result.HashedBytes)
+	}
+	if result.CorruptedBytes != 4 {
+		t.Errorf("Expected the size on disk to count as corrupted bytes, got %d", result.CorruptedBytes)
+	}
+}
+
+func TestProcessManifestTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	entries := []ManifestEntry{
+		{Path: filePath, Hash: "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"},
+		{Path: filepath.Join(dir, "gone.txt"), Hash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+
+	result, err := ProcessManifest("SHA256SUMS", entries, Options{Workers: 1, ExpectedTotal: 12, HasExpectedTotal: true})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.TotalSizeMismatch || result.PresentBytes != 12 {
+		t.Errorf("Expected the present files to add up to 12 bytes, got %d", result.PresentBytes)
+	}
+
+	result, err = ProcessManifest("SHA256SUMS", entries, Options{Workers: 1, ExpectedTotal: 20, HasExpectedTotal: true})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if !result.TotalSizeMismatch || result.ExpectedTotalBytes != 20 || result.PresentBytes != 12 {
+		t.Errorf("Expected a mismatch of 12 for 20 bytes, got %v of %d for %d", result.TotalSizeMismatch, result.PresentBytes, result.ExpectedTotalBytes)
+	}
+
+	result, err = ProcessManifest("SHA256SUMS", entries, Options{Workers: 1})
+	if err != nil {
+		t.Fatalf("ProcessManifest failed: %v", err)
+	}
+	if result.TotalSizeMismatch || result.PresentBytes != 0 {
+		t.Errorf("Expected no total size check without a header, got %d present bytes", result.PresentBytes)
+	}
+}
+
+// failingVolume walks the local file system but cannot open any file.
+type failingVolume struct{}
+
+func (failingVolume) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (failingVolume) Open(path string) (io.ReadCloser, error) {
+	return nil, errors.New("input/output error")
+}
+
+func TestProcessFolderErroredFiles(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72")
+	if err := os.WriteFile(filePath, []byte("test"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 1, Source: failingVolume{}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.ErroredFiles != 1 || result.ErroredFileList[0].FilePath != filePath {
+		t.Fatalf("Expected %s to be errored, got %v", filePath, result.ErroredFileList)
+	}
+	if result.IntactFiles != 0 || result.CorruptedFiles != 0 {
+		t.Errorf("Expected the errored file to be neither intact nor corrupted, got %d intact and %d corrupted", result.IntactFiles, result.CorruptedFiles)
+	}
+}
+
+func TestProcessFolderDeadline(t *testing.T) {
+	dir := writeLocalityTree(t)
+	opts := Options{Workers: 2, Source: &latentVolume{latency: 5 * time.Millisecond}, Deadline: time.Now().Add(50 * time.Millisecond)}
+	result, err := ProcessFolder(dir, opts)
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if !result.DeadlineExceeded || !result.StoppedEarly {
+		t.Errorf("Expected the run to stop at the deadline, got %+v", result)
+	}
+	if result.TotalFiles >= 32 || result.TotalFiles != result.IntactFiles {
+		t.Errorf("Expected only the files checked before the deadline to be counted, got %d total and %d intact", result.TotalFiles, result.IntactFiles)
+	}
+	if result.ErroredFiles != 0 {
+		t.Errorf("Expected interrupted files not to be reported as errored, got %v", result.ErroredFileList)
+	}
+}
+
+// checkNoLeak fails the test if goroutines started since before are still
+// running shortly after it returns.
+func checkNoLeak(t *testing.T, before int) {
+	t.Helper()
+	for i := 0; i < 50 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Expected %d goroutines after the run, got %d", before, n)
+	}
+}
+
+func TestProcessFolderEdgeCases(t *testing.T) {
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	filePath := filepath.Join(t.TempDir(), hash)
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+		total   int
+	}{
+		{name: "empty directory", path: t.TempDir(), opts: Options{Workers: 4}},
+		{name: "empty directory with hash workers", path: t.TempDir(), opts: Options{Workers: 4, HashWorkers: 2}},
+		{name: "nonexistent path", path: filepath.Join(t.TempDir(), "missing"), opts: Options{Workers: 4}, wantErr: true},
+		{name: "nonexistent path with hash workers", path: filepath.Join(t.TempDir(), "missing"), opts: Options{Workers: 4, HashWorkers: 2}, wantErr: true},
+		{name: "file", path: filePath, opts: Options{Workers: 4, ReportEmptyDirs: true}, total: 1},
+		{name: "no workers", path: filepath.Dir(filePath), opts: Options{}, total: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			done := make(chan struct{})
+			var result *Result
+			var err error
+			go func() {
+				defer close(done)
+				result, err = ProcessFolder(tt.path, tt.opts)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("ProcessFolder did not return")
+			}
+			checkNoLeak(t, before)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessFolder failed: %v", err)
+			}
+			if result.TotalFiles != tt.total || result.IntactFiles != tt.total {
+				t.Errorf("Expected %d intact files, got %d intact of %d", tt.total, result.IntactFiles, result.TotalFiles)
+			}
+			if len(result.EmptyDirs) != 0 {
+				t.Errorf("Expected no empty directories, got %v", result.EmptyDirs)
+			}
+		})
+	}
+}
+
+func TestProcessFolderProgress(t *testing.T) {
+	dir := writeLocalityTree(t)
+	progress := &Progress{}
+	if _, err := ProcessFolder(dir, Options{Workers: 4, Progress: progress}); err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	s := progress.Snapshot()
+	if s.Folder != dir || !s.Walked {
+		t.Errorf("Expected the walk of %s to be finished, got %+v", dir, s)
+	}
+	if s.Files != 32 || s.DoneFiles != 32 || s.Bytes != 32*128<<10 || s.DoneBytes != s.Bytes {
+		t.Errorf("Expected all 32 files to be found and checked, got %+v", s)
+	}
+
+	corrupted := filepath.Join(dir, "0000000000000000000000000000000000000000000000000000000000000000")
+	if err := os.WriteFile(corrupted, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if _, err := ProcessFolder(dir, Options{Workers: 4, Progress: progress}); err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if s := progress.Snapshot(); s.DoneFiles != 33 || s.Corrupted != 1 {
+		t.Errorf("Expected 33 files checked with 1 corrupted, got %+v", s)
+	}
+}
+
+func TestProcessFolderDecrypt(t *testing.T) {
+	key := make([]byte, 32)
+	for _, name := range []string{"aes-gcm", "chacha20poly1305"} {
+		t.Run(name, func(t *testing.T) {
+			aead, err := NewAEAD(name, key)
+			if err != nil {
+				t.Fatalf("NewAEAD failed: %v", err)
+			}
+			hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+			nonce := make([]byte, aead.NonceSize())
+			sealed := aead.Seal(nonce, nonce, []byte("test content"), nil)
+			tampered := append([]byte(nil), sealed...)
+			tampered[len(tampered)-1] ^= 1
+
+			dir := t.TempDir()
+			files := map[string][]byte{
+				hash: sealed,
+				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855": aead.Seal(nonce, nonce, []byte("other"), nil),
+				"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08": tampered,
+			}
+			for name, data := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+					t.Fatalf("Failed to write test file: %v", err)
+				}
+			}
+
+			result, err := ProcessFolder(dir, Options{Workers: 2, Decrypt: aead})
+			if err != nil {
+				t.Fatalf("ProcessFolder failed: %v", err)
+			}
+			if result.IntactFiles != 1 || result.CorruptedFiles != 1 {
+				t.Errorf("Expected 1 intact and 1 corrupted file, got %d intact and %d corrupted", result.IntactFiles, result.CorruptedFiles)
+			}
+			if result.DecryptErrors != 1 || len(result.DecryptErrorList) != 1 || filepath.Base(result.DecryptErrorList[0].FilePath) != "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" {
+				t.Errorf("Expected the tampered file to fail decryption, got %v", result.DecryptErrorList)
+			}
+			if result.HashedBytes != int64(len("test content")+len("other")) {
+				t.Errorf("Expected the plaintext to be hashed, got %d bytes", result.HashedBytes)
+			}
+
+			// Only the file holding "other" is small enough to be decrypted.
+			result, err = ProcessFolder(dir, Options{Workers: 2, Decrypt: aead, DecryptLimit: int64(len(sealed) - 1)})
+			if err != nil {
+				t.Fatalf("ProcessFolder failed: %v", err)
+			}
+			if result.DecryptErrors != 2 || result.IntactFiles != 0 || result.HashedBytes != int64(len("other")) {
+				t.Errorf("Expected 2 files too large to decrypt, got %d decrypt errors, %d intact and %d bytes hashed", result.DecryptErrors, result.IntactFiles, result.HashedBytes)
+			}
+		})
+	}
+}
+
+// recordingVolume reads the local file system and records the files opened.
+type recordingVolume struct {
+	mu     sync.Mutex
+	opened []string
+}
+
+func (v *recordingVolume) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (v *recordingVolume) Open(path string) (io.ReadCloser, error) {
+	v.mu.Lock()
+	v.opened = append(v.opened, path)
+	v.mu.Unlock()
+	return os.Open(path)
+}
+
+func TestProcessFolderSizeRange(t *testing.T) {
+	dir := t.TempDir()
+	contents := []string{"", "test content", strings.Repeat("x", 100)}
+	for _, content := range contents {
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+		if err := os.WriteFile(filepath.Join(dir, hash), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	volume := &recordingVolume{}
+	result, err := ProcessFolder(dir, Options{Workers: 2, Source: volume, MinSize: 1, MaxSize: 50})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.TotalFiles != 1 || result.IntactFiles != 1 || result.SkippedSize != 2 {
+		t.Errorf("Expected 1 intact file and 2 skipped by size, got %d intact of %d and %d skipped", result.IntactFiles, result.TotalFiles, result.SkippedSize)
+	}
+	if len(volume.opened) != 1 || filepath.Base(volume.opened[0]) != "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72" {
+		t.Errorf("Expected only the file within the size range to be opened, got %v", volume.opened)
+	}
+}
+
+func TestProcessFolderBytes(t *testing.T) {
+	dir := t.TempDir()
+	hash := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	if err := os.WriteFile(filepath.Join(dir, hash), []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	corrupted := strings.Repeat("e", 64)
+	if err := os.WriteFile(filepath.Join(dir, corrupted), []byte(strings.Repeat("x", 1000)), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "invalid"), []byte("not counted"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactBytes != 12 || result.CorruptedBytes != 1000 {
+		t.Errorf("Expected 12 intact and 1000 corrupted bytes, got %d and %d", result.IntactBytes, result.CorruptedBytes)
+	}
+}
+
+func TestProcessFolderChecked(t *testing.T) {
+	root := t.TempDir()
+	intact := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+	corrupted := strings.Repeat("0", 64)
+	for _, name := range []string{intact, corrupted, "readme.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	type checked struct{ status, expected, actual string }
+	var mu sync.Mutex
+	got := make(map[string]checked)
+	_, err := ProcessFolder(root, Options{Workers: 2, Checked: func(record FileRecord, expectedHash, actualHash string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[filepath.Base(record.FilePath)] = checked{record.Status, expectedHash, actualHash}
+	}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	want := map[string]checked{
+		intact:       {StatusIntact, intact, intact},
+		corrupted:    {StatusCorrupted, corrupted, intact},
+		"readme.txt": {StatusInvalid, "", notHashed},
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("Expected %s to be checked as %+v, got %+v", name, w, got[name])
+		}
+	}
+}
+
+func TestProcessFolderCheckedUnlocked(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72", strings.Repeat("0", 64)} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	// The first call blocks until the other worker has checked its file,
+	// which it could not while the result was locked.
+	var calls atomic.Int32
+	release := make(chan struct{})
+	_, err := ProcessFolder(root, Options{Workers: 2, Checked: func(record FileRecord, expectedHash, actualHash string) {
+		if calls.Add(1) > 1 {
+			close(release)
+			return
+		}
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			t.Error("Expected the other file to be checked while Checked blocks")
+		}
+	}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+}
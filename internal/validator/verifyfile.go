@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// FileCheck is the outcome of checking a single file against a given hash.
+type FileCheck struct {
+	FilePath     string `json:"file_path"`
+	Algorithm    string `json:"algorithm"`
+	ExpectedHash string `json:"expected_hash"`
+	ActualHash   string `json:"actual_hash"`
+	Intact       bool   `json:"intact"`
+}
+
+// fileAlgorithms are the algorithms VerifyFile supports besides SHA256, for
+// checking downloads against whatever checksum was published.
+var fileAlgorithms = map[string]func() hash.Hash{
+	"blake3": newBlake3,
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha512": sha512.New,
+}
+
+// parallelAlgorithms hash the first size bytes of a file with up to workers
+// goroutines, reading every part through wrap. Only algorithms whose hashes
+// of separate parts can be combined are listed; the others are always
+// hashed serially.
+var parallelAlgorithms = map[string]func(r io.ReaderAt, size int64, workers int, wrap func(io.Reader) io.Reader) ([]byte, error){
+	"blake3": blake3Parallel,
+}
+
+// FileAlgorithms returns the names of the algorithms VerifyFile supports.
+func FileAlgorithms() []string {
+	names := []string{"sha256"}
+	for name := range fileAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VerifyFile hashes the file with the algorithm and compares the hash with
+// expectedHash, regardless of the name of the file. The expected hash may be
+// upper- or lower-case hex.
+func VerifyFile(filePath, expectedHash, algorithm string, opts Options) (*FileCheck, error) {
+	algorithm = strings.ToLower(algorithm)
+	expectedHash = strings.ToLower(strings.TrimSpace(expectedHash))
+	newHash, other := fileAlgorithms[algorithm]
+	if !other && algorithm != "sha256" {
+		return nil, fmt.Errorf("%w %q: expected one of %s", ErrUnsupportedAlgo, algorithm, strings.Join(FileAlgorithms(), ", "))
+	}
+	size := sha256.Size
+	if other {
+		size = newHash().Size()
+	}
+	if len(expectedHash) != 2*size || !hexPattern.MatchString(expectedHash) {
+		return nil, fmt.Errorf("expected hash %q is not a hex encoded %s hash", expectedHash, algorithm)
+	}
+
+	check := &FileCheck{FilePath: filePath, Algorithm: algorithm, ExpectedHash: expectedHash}
+	// SHA256 goes through hashFile, which honors --mmap and --decrypt.
+	if !other {
+		sum, err := hashFile(filePath, opts)
+		if err != nil {
+			return nil, err
+		}
+		check.ActualHash = sum.hash
+	} else {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		sum, err := fileHash(file, algorithm, opts)
+		if err != nil {
+			return nil, err
+		}
+		check.ActualHash = hex.EncodeToString(sum)
+	}
+	check.Intact = check.ActualHash == expectedHash
+	return check, nil
+}
+
+// fileHash hashes the file with one of fileAlgorithms. A file of at least
+// opts.ParallelSize bytes is hashed in parallel when the algorithm allows it.
+func fileHash(file *os.File, algorithm string, opts Options) ([]byte, error) {
+	wrap := func(r io.Reader) io.Reader { return limitReader(r, opts.Limiter) }
+	if parallel, ok := parallelAlgorithms[algorithm]; ok && opts.ParallelSize > 0 {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() >= opts.ParallelSize {
+			return parallel(file, info.Size(), opts.Workers, wrap)
+		}
+	}
+	h := fileAlgorithms[algorithm]()
+	if _, err := io.Copy(h, wrap(file)); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "download.iso")
+	if err := os.WriteFile(filePath, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		algorithm string
+		expected  string
+		intact    bool
+	}{
+		{"sha256", "6AE8A75555209FD6C44157C0AED8016E763FF435A19CF186F76863140143FF72", true},
+		{"sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", false},
+		{"md5", "9473fdd0d880a43c21b7778d34872157", true},
+		{"SHA1", " 1eebdf4fdc9fc7bf283031b93f9aef3338de9052\n", true},
+		{"blake3", "ead3df8af4aece7792496936f83b6b6d191a7f256585ce6b6028db161278017e", true},
+	}
+	for _, test := range tests {
+		check, err := VerifyFile(filePath, test.expected, test.algorithm, Options{})
+		if err != nil {
+			t.Fatalf("VerifyFile(%s) failed: %v", test.algorithm, err)
+		}
+		if check.Intact != test.intact {
+			t.Errorf("VerifyFile(%s, %s) intact = %v, want %v (actual %s)", test.algorithm, test.expected, check.Intact, test.intact, check.ActualHash)
+		}
+	}
+
+	if _, err := VerifyFile(filePath, "9473fdd0d880a43c21b7778d34872157", "sha256", Options{}); err == nil {
+		t.Error("Expected an error for an MD5 hash checked as SHA256")
+	}
+	if _, err := VerifyFile(filePath, "abc", "crc32", Options{}); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected an error for an unsupported algorithm, got %v", err)
+	}
+}
+
+func TestVerifyFileParallel(t *testing.T) {
+	vector := blake3Vectors[len(blake3Vectors)-1]
+	filePath := filepath.Join(t.TempDir(), "image.raw")
+	if err := os.WriteFile(filePath, blake3Input(vector.size), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	for _, size := range []int64{0, 1 << 20, int64(vector.size) + 1} {
+		check, err := VerifyFile(filePath, vector.hash, "blake3", Options{Workers: 4, ParallelSize: size})
+		if err != nil {
+			t.Fatalf("VerifyFile(ParallelSize %d) failed: %v", size, err)
+		}
+		if !check.Intact {
+			t.Errorf("VerifyFile(ParallelSize %d) = %s, want %s", size, check.ActualHash, vector.hash)
+		}
+	}
+
+	// Algorithms that cannot be combined are hashed serially.
+	check, err := VerifyFile(filePath, "6c1b6ad0a8e3f2ecf84e3ae4f3fe4936", "md5", Options{Workers: 4, ParallelSize: 1})
+	if err != nil {
+		t.Fatalf("VerifyFile(md5) failed: %v", err)
+	}
+	if check.Intact {
+		t.Errorf("VerifyFile(md5) = %s, want a mismatch", check.ActualHash)
+	}
+}
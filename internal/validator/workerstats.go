@@ -0,0 +1,18 @@
+package validator
+
+// WorkerStat is the number of files, and their size, checked by a worker.
+type WorkerStat struct {
+	Worker int   `json:"worker"`
+	Files  int   `json:"files"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// newWorkerStats returns the stats of the workers, numbered from 1. Every
+// worker only updates its own.
+func newWorkerStats(workers int) []WorkerStat {
+	stats := make([]WorkerStat, workers)
+	for i := range stats {
+		stats[i].Worker = i + 1
+	}
+	return stats
+}
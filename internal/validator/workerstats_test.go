@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessFolderWorkerStats(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 10; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%064x", i)), []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 3, WorkerStats: true})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if len(result.WorkerStats) != 3 {
+		t.Fatalf("Expected stats for 3 workers, got %v", result.WorkerStats)
+	}
+	files, bytes := 0, int64(0)
+	for i, stat := range result.WorkerStats {
+		if stat.Worker != i+1 {
+			t.Errorf("Expected worker %d, got %d", i+1, stat.Worker)
+		}
+		files += stat.Files
+		bytes += stat.Bytes
+	}
+	if files != 10 || bytes != 120 {
+		t.Errorf("Expected the workers to have checked 10 files of 120 bytes, got %d of %d", files, bytes)
+	}
+
+	result, err = ProcessFolder(dir, Options{Workers: 3})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.WorkerStats != nil {
+		t.Errorf("Expected no stats without WorkerStats, got %v", result.WorkerStats)
+	}
+}
@@ -0,0 +1,5 @@
+package validator
+
+import "golang.org/x/sys/unix"
+
+const errNoAttr = unix.ENODATA
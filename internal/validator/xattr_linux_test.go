@@ -0,0 +1,30 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestProcessFolderXattrSource(t *testing.T) {
+	dir := t.TempDir()
+	tagged := filepath.Join(dir, "tagged")
+	for _, path := range []string{tagged, filepath.Join(dir, "untagged")} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	if err := unix.Setxattr(tagged, "user.sha256", []byte(testContentHash), 0); err != nil {
+		t.Skipf("File system does not support user extended attributes: %v", err)
+	}
+
+	result, err := ProcessFolder(dir, Options{Workers: 1, HashSource: XattrSource{Name: "user.sha256"}})
+	if err != nil {
+		t.Fatalf("ProcessFolder failed: %v", err)
+	}
+	if result.IntactFiles != 1 || result.NotIndexedFiles != 1 {
+		t.Errorf("Expected 1 intact and 1 untagged file, got %d intact and %d not indexed", result.IntactFiles, result.NotIndexedFiles)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package validator
+
+import "errors"
+
+func readXattr(filePath, name string) (string, bool, error) {
+	return "", false, errors.New("extended attributes are not supported on this platform")
+}
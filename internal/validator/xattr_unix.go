@@ -0,0 +1,29 @@
+//go:build linux || darwin
+
+package validator
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// readXattr returns the value of the extended attribute of the file. ok is
+// false when the file does not have the attribute.
+func readXattr(filePath, name string) (string, bool, error) {
+	buf := make([]byte, 256)
+	for {
+		n, err := unix.Getxattr(filePath, name, buf)
+		if errors.Is(err, errNoAttr) {
+			return "", false, nil
+		}
+		if errors.Is(err, unix.ERANGE) {
+			buf = make([]byte, 2*len(buf))
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return string(buf[:n]), true, nil
+	}
+}
@@ -0,0 +1,97 @@
+// Package verifydb persists when each file was last verified successfully.
+package verifydb
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Entry struct {
+	Hash       string    `json:"hash"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// DB maps file paths to the last time they were verified successfully.
+type DB struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// Open loads the database stored at path. A missing file yields an empty database.
+func Open(path string) (*DB, error) {
+	db := &DB{path: path, entries: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &db.entries); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Record marks the file as verified with the given hash at the current time.
+func (db *DB) Record(filePath, hash string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.entries[filePath] = Entry{Hash: hash, VerifiedAt: time.Now().UTC()}
+}
+
+// Save writes the database back to its file, replacing it atomically.
+func (db *DB) Save() error {
+	db.mu.Lock()
+	data, err := json.MarshalIndent(db.entries, "", "  ")
+	db.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), db.path)
+}
+
+// VerifiedFile is a file together with its last successful verification.
+type VerifiedFile struct {
+	FilePath string `json:"file_path"`
+	Entry
+}
+
+// OlderThan returns the files last verified before the cutoff, oldest first.
+func (db *DB) OlderThan(cutoff time.Time) []VerifiedFile {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var files []VerifiedFile
+	for filePath, entry := range db.entries {
+		if entry.VerifiedAt.Before(cutoff) {
+			files = append(files, VerifiedFile{FilePath: filePath, Entry: entry})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].VerifiedAt.Equal(files[j].VerifiedAt) {
+			return files[i].VerifiedAt.Before(files[j].VerifiedAt)
+		}
+		return files[i].FilePath < files[j].FilePath
+	})
+	return files
+}
@@ -0,0 +1,35 @@
+package verifydb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordSaveOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verify.db")
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open of a missing database failed: %v", err)
+	}
+	db.Record("/data/a", "hash-a")
+	db.Record("/data/b", "hash-b")
+	db.entries["/data/b"] = Entry{Hash: "hash-b", VerifiedAt: time.Now().Add(-100 * 24 * time.Hour)}
+	if err := db.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if len(db.entries) != 2 || db.entries["/data/a"].Hash != "hash-a" {
+		t.Fatalf("Unexpected entries after reopening: %v", db.entries)
+	}
+
+	stale := db.OlderThan(time.Now().Add(-90 * 24 * time.Hour))
+	if len(stale) != 1 || stale[0].FilePath != "/data/b" {
+		t.Errorf("Expected only /data/b to be stale, got %v", stale)
+	}
+}
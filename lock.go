@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/konidev20/verifydata/internal/validator"
+)
+
+// lockFolders locks the folders when --lock is set, so that a second run
+// locking the same folders fails fast. The returned function releases the
+// locks.
+func lockFolders(opts VerifyDataOptions, folderPaths []string) (release func(), err error) {
+	var locks []*validator.Lock
+	release = func() {
+		for _, lock := range locks {
+			if err := lock.Release(); err != nil {
+				slog.Error("releasing lock failed", "error", err)
+			}
+		}
+	}
+	if !opts.Lock {
+		return release, nil
+	}
+	for _, folderPath := range folderPaths {
+		if isRemote(folderPath) {
+			release()
+			return nil, fmt.Errorf("--lock does not support remote folders: %s", folderPath)
+		}
+		lock, err := validator.AcquireLock(folderPath, opts.Force)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("%w; pass --force if the lock was left behind by a run that was killed", err)
+		}
+		locks = append(locks, lock)
+	}
+	return release, nil
+}
@@ -2,25 +2,124 @@ package main
 
 import (
 	"bufio"
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/konidev20/verifydata/internal/hook"
+	"github.com/konidev20/verifydata/internal/manifest"
+	"github.com/konidev20/verifydata/internal/resultdb"
+	"github.com/konidev20/verifydata/internal/source"
+	"github.com/konidev20/verifydata/internal/storage"
 	"github.com/konidev20/verifydata/internal/template"
 	"github.com/konidev20/verifydata/internal/ui"
 	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/konidev20/verifydata/internal/verifydb"
 	"github.com/spf13/cobra"
 )
 
 type VerifyDataOptions struct {
-	Paths     []string
-	PathsFile []string
-	Exclude   []string
-	Workers   int
-	JSON      bool
-	Template  []string
+	Paths          []string
+	PathsFile      []string
+	Exclude        []string
+	ExcludeFrom    []string
+	Workers        int
+	AutoWorkers    bool
+	ReadWorkers    int
+	HashWorkers    int
+	ParallelLarge  bool
+	ParallelMin    string
+	HashPinning    bool
+	JSON           bool
+	JSONCompact    bool
+	JSONCanon      bool
+	SummaryOnly    bool
+	ReportUnder    string
+	Compact        bool
+	JSONStream     bool
+	Findings       string
+	Report         string
+	ReportFormat   string
+	SignReport     string
+	Lock           bool
+	Force          bool
+	TraceFile      string
+	CPUProfile     string
+	MemProfile     string
+	Template       []string
+	NoDefaults     bool
+	MMap           bool
+	MMapSize       int64
+	SizeHist       bool
+	WorkerStats    bool
+	Ignore         []string
+	IgnoreList     []string
+	SinceReport    string
+	RecordFiles    bool
+	DetectType     bool
+	LongPaths      bool
+	VerifyDB       string
+	SQLiteOut      string
+	Locality       bool
+	QueueDepth     int
+	DirWorkers     map[string]int
+	SymlinkTargets bool
+	DedupInodes    bool
+	ContentCache   bool
+	Verbose        bool
+	OnCorrupt      string
+	HookJobs       int
+	DryRun         bool
+	AssumeYes      bool
+	LogLevel       string
+	LogFormat      string
+	NamePattern    string
+	HashPrefixLen  int
+	HashPrefixes   []string
+	TryAlgorithms  []string
+	RehashModified bool
+	OutputSort     string
+	Bandwidth      string
+	SkipHidden     bool
+	NoRecurse      bool
+	ExcludeTypes   []string
+	MinSize        string
+	MaxSize        string
+	ByteBudget     string
+	ExcludeNewer   time.Duration
+	Manifest       string
+	FailOn         []string
+	FailOnZero     bool
+	TreeHash       bool
+	ExpectTree     string
+	LimitFiles     int
+	MaxRuntime     time.Duration
+	Progress       bool
+	ProgressJSON   string
+	ProgressEvery  time.Duration
+	Normalize      bool
+	EmptyDirs      bool
+	IndexDB        string
+	HashSource     string
+	Decrypt        string
+	DecryptKey     string
+	DecryptKeyFile string
+	DecryptMax     string
+	XattrName      string
+	ManifestDir    string
+	ManifestFormat string
+	DerefManifest  bool
 }
 
 var verifyDataOptions VerifyDataOptions
@@ -33,33 +132,190 @@ func main() {
 Assuming the file names are the SHA256 hash of the file, it calculates the SHA256 hash of each file and compares it with the file name.
 If the file name matches the hash, the file is intact; otherwise, it is corrupted.
 The tool can be used to check the integrity of files in a directory before deploying them to a server.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyEnv(cmd); err != nil {
+				return err
+			}
+			return setupLogger(verifyDataOptions.LogLevel, verifyDataOptions.LogFormat, cmd.ErrOrStderr())
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runChecker(cmd, verifyDataOptions, args)
+			return withProfiling(verifyDataOptions, func() error {
+				return runChecker(cmd, verifyDataOptions, args)
+			})
 		},
 	}
 
-	goos := runtime.GOOS
+	rootCmd.PersistentFlags().StringSliceVarP(&verifyDataOptions.Paths, "path", "p", []string{"."}, "Path to the folder, an sftp://[user@]host[:port]/path URL, or an http(s):// URL of a directory listing. Can be specified multiple times.")
+	rootCmd.PersistentFlags().StringSliceVarP(&verifyDataOptions.PathsFile, "paths-file", "", []string{}, "Path to a file containing a list of folder paths. Each path should be on a new line.")
+	rootCmd.PersistentFlags().StringSliceVarP(&verifyDataOptions.Exclude, "exclude", "e", []string{}, "Regular expression pattern for excluding files and folders. Can be specified multiple times.")
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.ExcludeFrom, "exclude-from", []string{}, "Path to a file containing exclude patterns, one per line. Lines starting with # are comments. Can be specified multiple times.")
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.ExcludeTypes, "exclude-type", []string{}, "Content type of files to skip, sniffed from their first bytes, such as application/json or image/*. Can be specified multiple times.")
+	verifyDataOptions.Workers = storage.DefaultWorkers
+	rootCmd.PersistentFlags().VarP(workersValue{&verifyDataOptions}, "workers", "w", "Number of workers for parallel processing, or auto-io to pick it from the storage of the first --path: NumCPU for SSDs, fewer for spinning disks and more for network file systems and remote folders")
+	rootCmd.PersistentFlags().IntVar(&verifyDataOptions.ReadWorkers, "read-workers", 0, "Number of workers reading files when --hash-workers is set, which it requires. Defaults to --workers.")
+	rootCmd.PersistentFlags().IntVar(&verifyDataOptions.HashWorkers, "hash-workers", 0, "Number of workers hashing what the readers read. By default every worker hashes what it reads.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.ParallelLarge, "parallel-large-files", false, "Hash files of at least --parallel-threshold with up to --workers goroutines each. Only file --hash blake3 supports it; other algorithms, such as the SHA256 of folder checks, are hashed serially.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ParallelMin, "parallel-threshold", "256MiB", "Smallest file hashed in parallel with --parallel-large-files")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.HashPinning, "hash-workers-affinity", false, "Experimental: pin every --hash-workers worker to the CPUs of one NUMA node, spreading the workers over the nodes. No effect on platforms other than Linux.")
+	rootCmd.PersistentFlags().BoolVarP(&verifyDataOptions.JSON, "json", "j", false, "Print the results in JSON format")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.JSONCompact, "json-compact", false, "Print the results as JSON on a single line")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.JSONCanon, "json-canonical", false, "Print the results as canonical JSON (RFC 8785) with sorted keys, byte-identical for identical results")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.JSONStream, "json-stream", false, "Print the results as JSON, writing the lists of files as they are found instead of holding them in memory until the end")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.Findings, "finding-template", "", "Print every corrupted and invalid file through this Go text/template as it is found, instead of the results. The fields are .Kind (corrupted or invalid), .FilePath, .Size, .ExpectedHash, .ActualHash, .ContentType and .Reason.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.Report, "report", "", "Write the results to this file instead of stdout")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ReportFormat, "report-format", "", "Format of the --report file, chosen apart from the output on stdout, which is then printed too: table, compact, json, json-compact, json-canonical, csv, sarif or junit. By default, the output goes to the report file instead of stdout.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.SignReport, "sign-report", "", "Path to an Ed25519 private key signing the JSON results written with --report. The signature is written next to the report with the .sig extension added.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.SummaryOnly, "summary-only", false, "Print only the counts, leaving out the lists of files")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ReportUnder, "report-under", "", "Relative path of a subtree of every --path whose files alone are listed in the results. The whole folder is still checked, and the counts and exit status are those of the whole folder.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.OutputSort, "output-sort", validator.SortPath, "Order of the lists of files in the results: path, size to list the largest corrupted files first, or status to group them by the reason they are corrupted")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.Compact, "compact", false, "Print the results as \"key: value\" lines instead of tables. Tables too wide for the terminal are printed this way anyway.")
+	rootCmd.PersistentFlags().StringSliceVarP(&verifyDataOptions.Template, "template", "t", []string{}, "Template to use for excluding files and folders. Accepts glob patterns such as 'os-*' and 'all' for every template. Can be specified multiple times. Defaults to restic and the template of the current OS.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.NoDefaults, "no-default-templates", false, "Do not apply the default templates when --template is not given")
+
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.MMap, "mmap", false, "Memory-map large files instead of streaming them")
+	rootCmd.PersistentFlags().Int64Var(&verifyDataOptions.MMapSize, "mmap-threshold", validator.DefaultMMapThreshold, "Minimum file size in bytes to memory-map when --mmap is set")
+
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.SizeHist, "size-histogram", false, "Report a histogram of file sizes")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.WorkerStats, "worker-stats", false, "Report the number of files and bytes checked by every worker, to tell whether the work was spread evenly")
+
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.Ignore, "ignore-hash", []string{}, "Expected hash of a file known to be corrupted. Such files are reported as ignored. Can be specified multiple times.")
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.IgnoreList, "ignore-list", []string{}, "Path to a file containing hashes to ignore, one per line. Lines starting with # are comments.")
+
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.EmptyDirs, "report-empty-dirs", false, "Report directories that contain no files after exclusions")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.SymlinkTargets, "verify-symlink-targets", false, "Report symlinks whose target does not exist. Symlinks are skipped either way.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.RecordFiles, "record-files", false, "Record the size, modification time and status of every file in the JSON output, for use with --since-report")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.DetectType, "detect-type", false, "Detect the content type of every hashed file and report it with corrupted, ignored and recorded files")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.SinceReport, "since-report", "", "Path to a previous JSON report written with --record-files. Intact files whose size and modification time are unchanged are not hashed again.")
+
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.LongPaths, "long-paths", false, "Walk and open files through extended-length paths on Windows, for relative paths with files beyond MAX_PATH")
+
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.Progress, "progress", false, "Print the progress of the run to stderr, with the estimated time remaining once all files have been found")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ProgressJSON, "progress-json", "", "Write progress events as JSON lines to stderr or to the given file descriptor number, for programs wrapping verifydata")
+	rootCmd.PersistentFlags().DurationVar(&verifyDataOptions.ProgressEvery, "progress-interval", time.Second, "Interval between progress updates of --progress and --progress-json. Setting it prints a status line with the files processed and corrupted and the time elapsed to stderr every interval, for logs of runs without a terminal, even without --progress.")
+	rootCmd.PersistentFlags().DurationVar(&verifyDataOptions.MaxRuntime, "max-runtime", 0, "Stop the run once it has taken this long, for example 2h30m, print the partial results and exit with a non-zero status. 0 means no limit.")
+	rootCmd.PersistentFlags().IntVar(&verifyDataOptions.LimitFiles, "limit-files", 0, "Stop after this many files of each folder have been validated. 0 means no limit.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.Locality, "locality-aware", false, "Hand the files of a directory to a single worker to improve sequential reads on spinning disks")
+	rootCmd.PersistentFlags().IntVar(&verifyDataOptions.QueueDepth, "queue-depth", validator.DefaultQueueDepth, "Number of files the walk can find ahead of the workers, so that slow directory listings do not leave them idle. 0 hands every file to a worker as it is found.")
+	rootCmd.PersistentFlags().StringToIntVar(&verifyDataOptions.DirWorkers, "dir-workers", nil, "Maximum number of workers checking files below top-level directories of --path, such as 00=2,ff=8. Other directories are only limited by --workers.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.RehashModified, "rehash-modified", false, "Hash a file that does not match once more when it changed while it was hashed, instead of reporting it as modified right away")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.DedupInodes, "dedup-inodes", false, "Hash files sharing an inode only once")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.ContentCache, "content-cache", false, "Trust the hash of an earlier file of the same size whose first and last KiB are the same instead of reading a file in full, for stores with many copies of the same content. This can go wrong both ways: a copy damaged only between its first and last KiB is reported intact, and an intact file that differs from an earlier one only there is reported corrupted.")
+	rootCmd.PersistentFlags().BoolVarP(&verifyDataOptions.Verbose, "verbose", "v", false, "Print additional details about the run to stderr")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.OnCorrupt, "on-corrupt", "", "Shell command to run for every corrupted file. The file and hashes are passed in VERIFYDATA_FILE, VERIFYDATA_EXPECTED_HASH and VERIFYDATA_ACTUAL_HASH.")
+	rootCmd.PersistentFlags().IntVar(&verifyDataOptions.HookJobs, "on-corrupt-jobs", 2, "Maximum number of --on-corrupt commands running at the same time")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.DryRun, "dry-run", false, "Print the commands and changes that would be made instead of making them")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.Lock, "lock", false, "Lock every folder with a "+validator.LockFile+" file while it is checked, so that a second run with --lock fails instead of running at the same time")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.Force, "force", false, "Replace the lock file of --lock when it was left behind by a run that was killed")
+	rootCmd.PersistentFlags().BoolVarP(&verifyDataOptions.AssumeYes, "assume-yes", "y", false, "Answer yes to the confirmation of operations that change files, such as canonicalize, for use in scripts")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.HashSource, "hash-source", "", "Where the expected hash of every file is taken from: filename, xattr, sidecar or index-db. Defaults to index-db with --index-db and to filename otherwise.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.IndexDB, "index-db", "", "Path to a SQLite index with the expected hash of every file, used instead of the file names")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.Decrypt, "decrypt", "", "Decrypt every file before hashing it, for stores of encrypted files named by the hash of their plaintext: aes-gcm or chacha20poly1305")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.DecryptKey, "decrypt-key", "", "Hex encoded key for --decrypt. Prefer --decrypt-key-file, since command lines are visible to other users.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.DecryptKeyFile, "decrypt-key-file", "", "Path to the key for --decrypt, as raw bytes or hex encoded")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.DecryptMax, "decrypt-max-size", "1GiB", "Largest file to decrypt with --decrypt, which decrypts every file in memory. Larger files are reported as decrypt errors without being read.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.XattrName, "xattr-name", "user.sha256", "Name of the extended attribute holding the expected hash with --hash-source xattr")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.NamePattern, "name-pattern", "", "Regular expression with a named group \"hash\" that extracts the expected hash from the file name")
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.HashPrefixes, "hash-prefix", nil, "Check only the files whose name starts with these hex digits, such as 00 or ab1, to recheck a shard of the store. Can be specified multiple times.")
+	rootCmd.PersistentFlags().IntVar(&verifyDataOptions.HashPrefixLen, "hash-prefix-len", 0, "Number of leading hex characters of the SHA256 hash that file names are made of, for stores naming files by a truncated hash. 0 means the full hash.")
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.TryAlgorithms, "try-algorithms", nil, "Algorithms whose hashes are as long as SHA256 ones to try on files whose SHA256 hash does not match their name, such as sha256,sha3-256,blake2b-256. A file matching one of them is intact and reported with the algorithm. Supported: "+strings.Join(validator.AlternateAlgorithms(), ", ")+".")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.Manifest, "manifest", "", "Path or http(s) URL of a sha256sum manifest. Only the listed files are verified, against the hashes in the manifest; --path is ignored.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ManifestFormat, "manifest-format", "auto", "Line format of --manifest: gnu (sha256sum), bsd (\"SHA256 (<path>) = <hash>\") or auto to tell them apart by every line")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.Normalize, "normalize-unicode", false, "Normalize file names and manifest entries to NFC before comparing them")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ManifestDir, "manifest-base", "", "Directory against which relative paths in --manifest are resolved. Defaults to the directory of the manifest, or the current directory for a URL.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.DerefManifest, "dereference-manifest-paths", true, "Hash the targets of symlinks listed in --manifest. With =false, the symlinks themselves are hashed, that is the paths they point to.")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.SkipHidden, "skip-hidden", false, "Skip files and directories whose name starts with a dot, including everything below hidden directories")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.NoRecurse, "no-recurse", false, "Check only the files directly in --path, skipping all subdirectories")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.MinSize, "min-size", "", "Skip files smaller than this size, e.g. 4KiB")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.MaxSize, "max-size", "", "Skip files larger than this size, e.g. 10GiB")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ByteBudget, "byte-budget", "", "Check the smallest files first and stop once their sizes add up to this many bytes, e.g. 500GiB. Files are found before any is checked.")
+	rootCmd.PersistentFlags().DurationVar(&verifyDataOptions.ExcludeNewer, "exclude-newer-than", 0, "Skip files modified less than this long ago, such as 30s, which may still be being written")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.Bandwidth, "max-bandwidth", "", "Maximum combined read rate of all workers, e.g. 50MiB/s")
+	rootCmd.PersistentFlags().StringSliceVar(&verifyDataOptions.FailOn, "fail-on", []string{"corrupted"}, "Categories of files that make the command exit with a non-zero status: corrupted, invalid, missing and errored")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.FailOnZero, "fail-on-zero-files", false, "Exit with a non-zero status when no files matched in a folder, as happens with a wrong --path or excludes that match everything")
+	rootCmd.PersistentFlags().BoolVar(&verifyDataOptions.TreeHash, "tree-hash", false, "Report the tree hash of every folder: the SHA256 hash of the sorted \"<hash>  <path>\" lines of its files, which changes with any added, removed or changed file")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.ExpectTree, "expect-tree-hash", "", "Tree hash the folder must have, as reported by --tree-hash. The command exits with a non-zero status when it differs.")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.LogLevel, "log-level", "info", "Minimum level of log messages written to stderr: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.LogFormat, "log-format", "text", "Format of log messages written to stderr: text or json")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.VerifyDB, "verify-db", "", "Path to a database recording when each file was last verified successfully")
+	rootCmd.PersistentFlags().StringVar(&verifyDataOptions.SQLiteOut, "sqlite-out", "", "Path to a SQLite database to write the path, status, expected and actual hash, size and modification time of every checked file to, in a files table replacing that of an earlier run")
+
+	// Profiling is for developers tuning the worker pipeline, so it is left
+	// out of the help.
+	rootCmd.Flags().StringVar(&verifyDataOptions.TraceFile, "trace", "", "Write a runtime execution trace of the run to this file")
+	rootCmd.Flags().StringVar(&verifyDataOptions.CPUProfile, "cpuprofile", "", "Write a CPU profile of the run to this file")
+	rootCmd.Flags().StringVar(&verifyDataOptions.MemProfile, "memprofile", "", "Write a heap profile to this file at the end of the run")
+	for _, name := range []string{"trace", "cpuprofile", "memprofile"} {
+		rootCmd.Flags().MarkHidden(name)
+	}
+
+	registerFlagCompletions(rootCmd)
 
-	rootCmd.Flags().StringSliceVarP(&verifyDataOptions.Paths, "path", "p", []string{"."}, "Path to the folder. Can be specified multiple times.")
-	rootCmd.Flags().StringSliceVarP(&verifyDataOptions.PathsFile, "paths-file", "pf", []string{}, "Path to a file containing a list of folder paths. Each path should be on a new line.")
-	rootCmd.Flags().StringSliceVarP(&verifyDataOptions.Exclude, "exclude", "e", []string{}, "Regular expression pattern for excluding files and folders. Can be specified multiple times.")
-	rootCmd.Flags().IntVarP(&verifyDataOptions.Workers, "workers", "w", 4, "Number of workers for parallel processing")
-	rootCmd.Flags().BoolVarP(&verifyDataOptions.JSON, "json", "j", false, "Print the results in JSON format")
-	rootCmd.Flags().StringSliceVarP(&verifyDataOptions.Template, "template", "t", []string{"restic", goos}, "Template to use for excluding files and folders. Can be specified multiple times.")
+	rootCmd.AddCommand(newBenchCommand())
+	rootCmd.AddCommand(newAuditCommand())
+	rootCmd.AddCommand(newCanonicalizeCommand())
+	rootCmd.AddCommand(newChunksCommand())
+	rootCmd.AddCommand(newCompareCommand())
+	rootCmd.AddCommand(newDoctorCommand())
+	rootCmd.AddCommand(newFileCommand())
+	rootCmd.AddCommand(newFixNamesCommand())
+	rootCmd.AddCommand(newGenerateCommand())
+	rootCmd.AddCommand(newPacksCommand())
+	rootCmd.AddCommand(newSchemaCommand())
+	rootCmd.AddCommand(newServeCommand())
+	rootCmd.AddCommand(newServeGRPCCommand())
+	rootCmd.AddCommand(newStatCommand())
+	rootCmd.AddCommand(newVerifyReportCommand())
+	rootCmd.AddCommand(newVersionCommand())
 
-	rootCmd.Execute()
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
 
 // collectExcludePatterns compiles a regular expression that matches any of the file or folder patterns
 // specified in the verifydataOptions. This includes both directly specified exclude patterns and those
 // derived from named templates.
-func collectExcludePatterns(opts VerifyDataOptions) *regexp.Regexp {
+// It returns nil when there is nothing to exclude.
+func collectExcludePatterns(opts VerifyDataOptions) (*regexp.Regexp, error) {
 	excludePatterns := opts.Exclude
-	for _, t := range opts.Template {
+	for _, t := range templateNames(opts) {
 		excludePatterns = append(excludePatterns, template.Templates[t].Exclude...)
 	}
-	combinedPattern := "(" + strings.Join(excludePatterns, ")|(") + ")"
-	return regexp.MustCompile(combinedPattern)
+	exclude, err := validator.CompileExcludes(excludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("--exclude: %w", err)
+	}
+	return exclude, nil
+}
+
+// templateNames returns the templates given with --template, with "all" and
+// glob patterns expanded, or the default templates when none were given and
+// --no-default-templates is not set.
+func templateNames(opts VerifyDataOptions) []string {
+	var names []string
+	for _, t := range opts.Template {
+		if t != "" {
+			names = append(names, t)
+		}
+	}
+	if len(opts.Template) == 0 && !opts.NoDefaults {
+		names = template.Defaults(runtime.GOOS)
+	}
+	return template.Expand(names)
+}
+
+// readExcludeFromFiles appends the patterns read from --exclude-from files to opts.Exclude.
+func readExcludeFromFiles(opts VerifyDataOptions) (VerifyDataOptions, error) {
+	exclude := append([]string{}, opts.Exclude...)
+	for _, ef := range opts.ExcludeFrom {
+		patterns, err := readListFile(ef)
+		if err != nil {
+			return opts, err
+		}
+		exclude = append(exclude, patterns...)
+	}
+	opts.Exclude = exclude
+	return opts, nil
 }
 
 func getFolderPaths(opts VerifyDataOptions) ([]string, error) {
@@ -67,7 +323,7 @@ func getFolderPaths(opts VerifyDataOptions) ([]string, error) {
 	for _, pf := range opts.PathsFile {
 		file, err := os.Open(pf)
 		if err != nil {
-			fmt.Printf("Error opening file: %v\n", err)
+			slog.Error("opening paths file failed", "path", pf, "error", err)
 			return nil, err
 		}
 		defer file.Close()
@@ -79,29 +335,665 @@ func getFolderPaths(opts VerifyDataOptions) ([]string, error) {
 	return folderPaths, nil
 }
 
-func runChecker(cmd *cobra.Command, opts VerifyDataOptions, _ []string) error {
-	numWorkers := opts.Workers
-	jsonOutput := opts.JSON
+// getIgnoredHashes collects the hashes passed with --ignore-hash and those read from --ignore-list files.
+// Hashes are matched case-insensitively, and entries that are not valid hashes are rejected.
+func getIgnoredHashes(opts VerifyDataOptions) (map[string]bool, error) {
+	ignored := make(map[string]bool)
+	add := func(h string) error {
+		h = strings.ToLower(h)
+		if !validator.IsValidSha256(h) {
+			return fmt.Errorf("invalid hash to ignore: %q", h)
+		}
+		ignored[h] = true
+		return nil
+	}
 
+	for _, h := range opts.Ignore {
+		if err := add(h); err != nil {
+			return nil, err
+		}
+	}
+	for _, il := range opts.IgnoreList {
+		lines, err := readListFile(il)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range lines {
+			if err := add(h); err != nil {
+				return nil, fmt.Errorf("%s: %w", il, err)
+			}
+		}
+	}
+	return ignored, nil
+}
+
+// readListFile reads a file with one entry per line, skipping blank lines and # comments.
+func readListFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// loadPreviousFiles reads a JSON report written by an earlier run.
+func loadPreviousFiles(path string) (map[string]validator.FileRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []*validator.Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	return validator.PreviousFiles(results), nil
+}
+
+// validatorOptions translates the command line options into options for the validator.
+func validatorOptions(opts VerifyDataOptions) (validator.Options, error) {
+	if err := template.Validate(templateNames(opts)); err != nil {
+		return validator.Options{}, err
+	}
+
+	opts, err := readExcludeFromFiles(opts)
+	if err != nil {
+		slog.Error("reading exclude patterns failed", "error", err)
+		return validator.Options{}, err
+	}
+
+	ignored, err := getIgnoredHashes(opts)
+	if err != nil {
+		slog.Error("reading ignore list failed", "error", err)
+		return validator.Options{}, err
+	}
+
+	var previous map[string]validator.FileRecord
+	if opts.SinceReport != "" {
+		previous, err = loadPreviousFiles(opts.SinceReport)
+		if err != nil {
+			slog.Error("reading previous report failed", "path", opts.SinceReport, "error", err)
+			return validator.Options{}, err
+		}
+	}
+
+	if opts.ExcludeNewer < 0 {
+		return validator.Options{}, fmt.Errorf("--exclude-newer-than must not be negative, got %s", opts.ExcludeNewer)
+	}
+	for dir, n := range opts.DirWorkers {
+		if n < 1 {
+			return validator.Options{}, fmt.Errorf("--dir-workers %s must be at least 1, got %d", dir, n)
+		}
+		if dir == "" || dir == "." || dir == ".." || strings.ContainsAny(dir, `/\`) {
+			return validator.Options{}, fmt.Errorf("--dir-workers %q is not the name of a top-level directory", dir)
+		}
+	}
+	if opts.QueueDepth < 0 {
+		return validator.Options{}, fmt.Errorf("--queue-depth must not be negative, got %d", opts.QueueDepth)
+	}
+	hashPrefixes := make([]string, 0, len(opts.HashPrefixes))
+	for _, prefix := range opts.HashPrefixes {
+		prefix = strings.ToLower(prefix)
+		if len(prefix) == 0 || len(prefix) > 64 || strings.Trim(prefix, "0123456789abcdef") != "" {
+			return validator.Options{}, fmt.Errorf("--hash-prefix must be 1 to 64 hex digits, got %q", prefix)
+		}
+		hashPrefixes = append(hashPrefixes, prefix)
+	}
+	switch opts.OutputSort {
+	case validator.SortPath, validator.SortSize, validator.SortStatus:
+	default:
+		return validator.Options{}, fmt.Errorf("--output-sort must be path, size or status, got %q", opts.OutputSort)
+	}
+	var tryAlgorithms []string
+	for _, algorithm := range opts.TryAlgorithms {
+		algorithm = strings.ToLower(algorithm)
+		switch {
+		case algorithm == "sha256":
+			// Every file is hashed with SHA256 first anyway.
+		case !slices.Contains(validator.AlternateAlgorithms(), algorithm):
+			return validator.Options{}, fmt.Errorf("--try-algorithms must be among sha256, %s, got %q", strings.Join(validator.AlternateAlgorithms(), ", "), algorithm)
+		case !slices.Contains(tryAlgorithms, algorithm):
+			tryAlgorithms = append(tryAlgorithms, algorithm)
+		}
+	}
+	if len(tryAlgorithms) > 0 && opts.Decrypt != "" {
+		return validator.Options{}, fmt.Errorf("--try-algorithms cannot be used with --decrypt")
+	}
+	expectTree := strings.ToLower(opts.ExpectTree)
+	if expectTree != "" && !validator.IsValidSha256(expectTree) {
+		return validator.Options{}, fmt.Errorf("--expect-tree-hash must be a SHA256 hash, got %q", opts.ExpectTree)
+	}
+	if opts.HashPrefixLen < 0 || opts.HashPrefixLen > 64 {
+		return validator.Options{}, fmt.Errorf("--hash-prefix-len must be between 0 and 64, got %d", opts.HashPrefixLen)
+	}
+
+	exclude, err := collectExcludePatterns(opts)
+	if err != nil {
+		return validator.Options{}, err
+	}
+
+	var namePattern *regexp.Regexp
+	if opts.NamePattern != "" {
+		if namePattern, err = validator.CompileNamePattern(opts.NamePattern); err != nil {
+			return validator.Options{}, fmt.Errorf("--name-pattern: %w", err)
+		}
+	}
+
+	hashSource, err := newHashSource(opts)
+	if err != nil {
+		return validator.Options{}, err
+	}
+
+	decryptor, err := newDecryptor(opts)
+	if err != nil {
+		return validator.Options{}, err
+	}
+
+	var limiter *validator.Limiter
+	if opts.Bandwidth != "" {
+		rate, err := parseBandwidth(opts.Bandwidth)
+		if err != nil {
+			return validator.Options{}, err
+		}
+		limiter = validator.NewLimiter(rate)
+	}
+
+	var minSize, maxSize int64
+	if opts.MinSize != "" {
+		if minSize, err = parseSize(opts.MinSize); err != nil {
+			return validator.Options{}, fmt.Errorf("--min-size: %w", err)
+		}
+	}
+	if opts.MaxSize != "" {
+		if maxSize, err = parseSize(opts.MaxSize); err != nil {
+			return validator.Options{}, fmt.Errorf("--max-size: %w", err)
+		}
+		if maxSize == 0 {
+			return validator.Options{}, fmt.Errorf("--max-size must be greater than 0")
+		}
+	}
+
+	var byteBudget int64
+	if opts.ByteBudget != "" {
+		if byteBudget, err = parseSize(opts.ByteBudget); err != nil {
+			return validator.Options{}, fmt.Errorf("--byte-budget: %w", err)
+		}
+		if byteBudget == 0 {
+			return validator.Options{}, fmt.Errorf("--byte-budget must be greater than 0")
+		}
+	}
+
+	var decryptLimit int64
+	if opts.DecryptMax != "" {
+		if decryptLimit, err = parseSize(opts.DecryptMax); err != nil {
+			return validator.Options{}, fmt.Errorf("--decrypt-max-size: %w", err)
+		}
+		if decryptLimit == 0 {
+			return validator.Options{}, fmt.Errorf("--decrypt-max-size must be greater than 0")
+		}
+	}
+
+	var parallelSize int64
+	if opts.ParallelLarge {
+		if parallelSize, err = parseSize(opts.ParallelMin); err != nil {
+			return validator.Options{}, fmt.Errorf("--parallel-threshold: %w", err)
+		}
+		if parallelSize == 0 {
+			return validator.Options{}, fmt.Errorf("--parallel-threshold must be greater than 0")
+		}
+	}
+
+	workers := opts.Workers
+	if opts.AutoWorkers {
+		workers = autoWorkers(opts)
+	}
+	if opts.ReadWorkers > 0 {
+		if opts.HashWorkers == 0 {
+			return validator.Options{}, fmt.Errorf("--read-workers requires --hash-workers")
+		}
+		workers = opts.ReadWorkers
+	}
+
+	return validator.Options{
+		Exclude:          exclude,
+		Workers:          workers,
+		HashWorkers:      opts.HashWorkers,
+		HashAffinity:     opts.HashPinning,
+		ParallelSize:     parallelSize,
+		MMap:             opts.MMap,
+		MMapThreshold:    opts.MMapSize,
+		SizeHistogram:    opts.SizeHist,
+		WorkerStats:      opts.WorkerStats,
+		IgnoreHashes:     ignored,
+		RecordFiles:      opts.RecordFiles,
+		DetectType:       opts.DetectType,
+		LocalityAware:    opts.Locality,
+		QueueDepth:       opts.QueueDepth,
+		DirWorkers:       opts.DirWorkers,
+		CheckSymlinks:    opts.SymlinkTargets,
+		HashLinks:        !opts.DerefManifest,
+		DedupInodes:      opts.DedupInodes,
+		ContentCache:     opts.ContentCache,
+		NamePattern:      namePattern,
+		HashPrefixLen:    opts.HashPrefixLen,
+		HashPrefixes:     hashPrefixes,
+		TryAlgorithms:    tryAlgorithms,
+		RehashModified:   opts.RehashModified,
+		OutputSort:       opts.OutputSort,
+		TreeHash:         opts.TreeHash || expectTree != "",
+		ExpectTreeHash:   expectTree,
+		NormalizeUnicode: opts.Normalize,
+		HashSource:       hashSource,
+		Decrypt:          decryptor,
+		DecryptLimit:     decryptLimit,
+		Limiter:          limiter,
+		SkipHidden:       opts.SkipHidden,
+		NoRecurse:        opts.NoRecurse,
+		ExcludeTypes:     opts.ExcludeTypes,
+		MinSize:          minSize,
+		MaxSize:          maxSize,
+		ByteBudget:       byteBudget,
+		ExcludeNewerThan: opts.ExcludeNewer,
+		LimitFiles:       opts.LimitFiles,
+		ReportEmptyDirs:  opts.EmptyDirs,
+		Previous:         previous,
+		LongPaths:        opts.LongPaths,
+	}, nil
+}
+
+// isRemote reports whether the path is the URL of a folder on another host,
+// which only the commands checking folders support.
+func isRemote(path string) bool {
+	return strings.HasPrefix(path, "sftp://") || manifest.IsURL(path)
+}
+
+// processFolder validates a local folder, or a remote one when the path is an
+// sftp:// or http(s):// URL.
+func processFolder(folderPath string, opts validator.Options) (*validator.Result, error) {
+	var remotePath string
+	switch {
+	case strings.HasPrefix(folderPath, "sftp://"):
+		src, path, err := source.DialSFTP(folderPath)
+		if err != nil {
+			slog.Error("connecting to remote folder failed", "folder", folderPath, "error", err)
+			return nil, err
+		}
+		defer src.Close()
+		opts.Source, remotePath = src, path
+	case manifest.IsURL(folderPath):
+		src, path, err := source.NewHTTP(folderPath)
+		if err != nil {
+			return nil, err
+		}
+		opts.Source, remotePath = src, path
+		folderPath = source.RedactURL(folderPath)
+	default:
+		return validator.ProcessFolder(folderPath, opts)
+	}
+
+	result, err := validator.ProcessFolder(remotePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.FolderPath = folderPath
+	return result, nil
+}
+
+func runChecker(cmd *cobra.Command, opts VerifyDataOptions, _ []string) error {
+	start := time.Now()
 	folderPaths, err := getFolderPaths(opts)
 	if err != nil {
-		fmt.Printf("Error getting folder paths: %v\n", err)
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+
+	failOn, err := parseFailOn(opts.FailOn)
+	if err != nil {
+		return err
+	}
+	if opts.JSONStream && (opts.SummaryOnly || opts.JSONCanon) {
+		return fmt.Errorf("--json-stream cannot be combined with --summary-only or --json-canonical")
+	}
+	if opts.Findings != "" && (opts.JSON || opts.JSONCompact || opts.JSONCanon || opts.JSONStream || opts.SummaryOnly || opts.SignReport != "") {
+		return fmt.Errorf("--finding-template cannot be combined with JSON output, --summary-only or --sign-report")
+	}
+	if opts.ReportUnder != "" {
+		if !filepath.IsLocal(opts.ReportUnder) {
+			return fmt.Errorf("--report-under must be a relative path inside the folder, got %q", opts.ReportUnder)
+		}
+		if opts.JSONStream || opts.Findings != "" || opts.Manifest != "" {
+			return fmt.Errorf("--report-under cannot be combined with --json-stream, --finding-template or --manifest")
+		}
+	}
+	if opts.Lock && opts.Manifest != "" {
+		return fmt.Errorf("--lock cannot be combined with --manifest")
+	}
+	if (opts.TreeHash || opts.ExpectTree != "") && opts.Manifest != "" {
+		return fmt.Errorf("--tree-hash and --expect-tree-hash cannot be combined with --manifest, which lists the hash of every file already")
+	}
+	if opts.ExpectTree != "" && len(folderPaths) != 1 {
+		return fmt.Errorf("--expect-tree-hash requires a single folder, got %d", len(folderPaths))
+	}
+	// With --report-format, the report is printed on its own.
+	var reportOpts *ui.Options
+	if opts.ReportFormat != "" {
+		if opts.Report == "" {
+			return fmt.Errorf("--report-format requires --report")
+		}
+		if opts.JSONStream || opts.Findings != "" {
+			return fmt.Errorf("--report-format cannot be combined with --json-stream or --finding-template")
+		}
+		printOpts, err := reportFormat(opts.ReportFormat)
+		if err != nil {
+			return err
+		}
+		reportOpts = &printOpts
+	}
+	var signingKey ed25519.PrivateKey
+	if opts.SignReport != "" {
+		if opts.Report == "" {
+			return fmt.Errorf("--sign-report requires --report")
+		}
+		if signingKey, err = loadSigningKey(opts.SignReport); err != nil {
+			return err
+		}
+		// Only JSON is signed; any of its forms verifies the same.
+		switch {
+		case reportOpts != nil:
+			if !reportOpts.JSON && !reportOpts.CompactJSON && !reportOpts.CanonicalJSON {
+				return fmt.Errorf("--sign-report requires a JSON --report-format")
+			}
+		case !opts.JSON && !opts.JSONCompact && !opts.JSONStream:
+			opts.JSONCanon = true
+		}
+	}
+	release, err := lockFolders(opts, folderPaths)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	defer release()
+
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.MaxRuntime > 0 {
+		validatorOpts.Deadline = start.Add(opts.MaxRuntime)
+	}
+	// An interrupt stops the run with the results so far, which are still
+	// written out. A second one kills the process as usual.
+	ctx, stopSignals := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	go func() {
+		<-ctx.Done()
+		stopSignals()
+	}()
+	validatorOpts.Context = ctx
+
+	var db *verifydb.DB
+	if opts.VerifyDB != "" {
+		db, err = verifydb.Open(opts.VerifyDB)
+		if err != nil {
+			slog.Error("opening verify database failed", "path", opts.VerifyDB, "error", err)
+			return err
+		}
+		validatorOpts.Verified = db.Record
+	}
+
+	var sqliteOut *resultdb.Writer
+	if opts.SQLiteOut != "" {
+		sqliteOut, err = resultdb.Create(opts.SQLiteOut)
+		if err != nil {
+			slog.Error("opening SQLite output failed", "path", opts.SQLiteOut, "error", err)
+			return err
+		}
+		validatorOpts.Checked = sqliteOut.Add
+		// The rows checked before a failure are written all the same.
+		defer func() {
+			if sqliteOut != nil {
+				sqliteOut.Close()
+			}
+		}()
+	}
+
+	var hooks *hook.Runner
+	if opts.OnCorrupt != "" {
+		hooks = hook.New(opts.OnCorrupt, opts.HookJobs, opts.DryRun, cmd.ErrOrStderr())
+		validatorOpts.Corrupted = hooks.Corrupted
+	}
+
+	stopProgress, err := startProgress(cmd, opts, &validatorOpts)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	var report *os.File
+	if opts.Report != "" {
+		if report, err = os.Create(opts.Report); err != nil {
+			stopProgress()
+			return err
+		}
+		// Whatever was written gets to the disk, even when the run fails.
+		defer func() {
+			if report != nil {
+				syncClose(report)
+			}
+		}()
+		if reportOpts == nil {
+			out = report
+		}
+	}
+
+	runID := uuid.NewString()
+	stamp := func(result *validator.Result, finished time.Time) {
+		result.ToolVersion = toolVersion()
+		result.RunID = runID
+		result.StartedAt = start.UTC().Format(time.RFC3339)
+		result.FinishedAt = finished.UTC().Format(time.RFC3339)
+		setStatus(result, failOn, opts.FailOnZero)
+	}
+	// With --json-stream, every result is written as soon as it is complete.
+	var stream *ui.JSONStream
+	var done func(*validator.Result) error
+	if opts.JSONStream {
+		stream = ui.NewJSONStream(out)
+		validatorOpts.Lists = stream
+		done = func(result *validator.Result) error {
+			stamp(result, time.Now())
+			return stream.End(result)
+		}
+	}
+	// With --finding-template, only the findings are written, as they come.
+	var findings *ui.FindingTemplate
+	if opts.Findings != "" {
+		if findings, err = ui.NewFindingTemplate(opts.Findings, out); err != nil {
+			stopProgress()
+			return fmt.Errorf("--finding-template: %w", err)
+		}
+		validatorOpts.Lists = findings
+	}
+
+	var results []*validator.Result
+	if opts.Manifest != "" {
+		var result *validator.Result
+		result, err = processManifest(opts.Manifest, opts.ManifestDir, opts.ManifestFormat, validatorOpts)
+		if err != nil {
+			slog.Error("processing manifest failed", "manifest", opts.Manifest, "error", err)
+		} else if done != nil {
+			err = done(result)
+		}
+		results = []*validator.Result{result}
+	} else {
+		results, err = processFolders(folderPaths, opts, validatorOpts, done)
+	}
+	// The progress line must be gone before errors or results are printed.
+	stopProgress()
+	if stream != nil {
+		if closeErr := stream.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if findings != nil && err == nil {
+		err = findings.Err()
+	}
+	if err != nil {
 		return err
 	}
 
-	exclude := collectExcludePatterns(opts)
+	if hooks != nil {
+		for _, failure := range hooks.Wait() {
+			slog.Error("running --on-corrupt failed", "path", failure.FilePath, "error", failure.Err, "output", failure.Output)
+		}
+	}
 
-	results := make([]*validator.Result, len(folderPaths))
+	if db != nil {
+		if err := db.Save(); err != nil {
+			slog.Error("saving verify database failed", "path", opts.VerifyDB, "error", err)
+			return err
+		}
+	}
 
-	for idx, folderPath := range folderPaths {
-		result, err := validator.ProcessFolder(folderPath, exclude, numWorkers)
+	if sqliteOut != nil {
+		err := sqliteOut.Close()
+		sqliteOut = nil
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			slog.Error("writing SQLite output failed", "path", opts.SQLiteOut, "error", err)
 			return err
 		}
-		results[idx] = result
 	}
 
-	ui.PrintResult(results, jsonOutput, cmd.OutOrStdout())
+	if stream == nil {
+		finished := time.Now()
+		for _, result := range results {
+			stamp(result, finished)
+			if opts.ReportUnder != "" {
+				result.KeepUnder(opts.ReportUnder)
+			}
+		}
+		// The report goes first, since --summary-only drops the lists of the
+		// results.
+		if reportOpts != nil {
+			ui.PrintResult(results, *reportOpts, report)
+		}
+		if findings == nil {
+			ui.PrintResult(results, ui.Options{JSON: opts.JSON, CompactJSON: opts.JSONCompact, CanonicalJSON: opts.JSONCanon, SummaryOnly: opts.SummaryOnly, Compact: opts.Compact}, out)
+		}
+	}
+	if report != nil {
+		err := syncClose(report)
+		report = nil
+		if err != nil {
+			return err
+		}
+		if signingKey != nil {
+			if err := signReport(opts.Report, signingKey); err != nil {
+				return err
+			}
+		}
+	}
+	// Warned last, so that it is not scrolled away by the results.
+	for _, result := range results {
+		if result.MostlyInvalid() {
+			slog.Warn("most file names are not SHA256 hashes: is this the right folder? Run verifydata doctor to find out how the files are named, then try --name-pattern, --hash-source or --hash-prefix-len",
+				"folder", result.FolderPath, "invalid_files", result.InvalidFiles, "total_files", result.TotalFiles)
+		}
+	}
+
+	for _, result := range results {
+		if result.DeadlineExceeded {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("deadline exceeded: stopped after --max-runtime %s", opts.MaxRuntime)
+		}
+	}
+	if ctx.Err() != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("interrupted: the results are incomplete")
+	}
+	if err := checkFailOn(results, failOn); err != nil {
+		// The results have been printed; usage would only bury them.
+		cmd.SilenceUsage = true
+		return err
+	}
+	if err := checkTreeHash(results); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if opts.FailOnZero {
+		if err := checkZeroFiles(results); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
 	return nil
 }
+
+// processManifest validates the files listed in the manifest at location,
+// read in the given format, resolving relative paths against base.
+func processManifest(location, base, format string, opts validator.Options) (*validator.Result, error) {
+	dialect, err := manifest.ParseDialect(format)
+	if err != nil {
+		return nil, err
+	}
+	entries, header, err := manifest.LoadHeader(location, base, dialect)
+	if err != nil {
+		return nil, err
+	}
+	opts.ExpectedTotal, opts.HasExpectedTotal = header.TotalBytes, header.HasTotal
+	return validator.ProcessManifest(location, entries, opts)
+}
+
+// syncClose flushes the file to the disk and closes it.
+func syncClose(f *os.File) error {
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// processFolders validates each folder in turn, calling done, when it is not
+// nil, with the result of every folder as soon as it is complete.
+func processFolders(folderPaths []string, opts VerifyDataOptions, validatorOpts validator.Options, done func(*validator.Result) error) ([]*validator.Result, error) {
+	results := make([]*validator.Result, 0, len(folderPaths))
+
+	for _, folderPath := range folderPaths {
+		// The folders after an interrupt are not started.
+		if validatorOpts.Context != nil && validatorOpts.Context.Err() != nil {
+			break
+		}
+		if target, ok := validator.SymlinkTarget(folderPath); ok && opts.Verbose {
+			slog.Info("following symlinked folder", "folder", folderPath, "target", target)
+		}
+		result, err := processFolder(folderPath, validatorOpts)
+		if err != nil {
+			slog.Error("processing folder failed", "folder", folderPath, "error", err)
+			return nil, err
+		}
+		results = append(results, result)
+		if opts.Verbose && opts.DedupInodes {
+			slog.Info("deduplicated hard links", "folder", folderPath, "links", result.DedupedFiles, "bytes", result.DedupedBytes)
+		}
+		if opts.Verbose && opts.ContentCache {
+			slog.Info("trusted cached content hashes", "folder", folderPath, "files", result.ContentCacheHits, "bytes", result.ContentCacheBytes)
+		}
+		if done != nil {
+			if err := done(result); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/konidev20/verifydata/internal/manifest"
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+func newPacksCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "packs <pack-index>",
+		Short: "Verify the chunks stored in pack files against their hashes",
+		Long: `packs checks a store of content-defined chunks kept in pack files, as backup tools
+store them, and reports the chunks that are corrupted. Every line of the pack index
+has the form "<offset>,<length>,<hash>,<pack>", giving the SHA256 hash of a chunk
+and the pack it is stored in, relative to the directory of the index. Every pack is
+read once, --workers packs at a time, and the command exits with a non-zero status
+when a chunk does not match or a pack is missing or cannot be read.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPacks(cmd, verifyDataOptions, args[0])
+		},
+	}
+}
+
+func runPacks(cmd *cobra.Command, opts VerifyDataOptions, indexPath string) error {
+	if isRemote(indexPath) {
+		return fmt.Errorf("packs does not support remote pack indexes: %s", indexPath)
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return err
+	}
+	packs, err := manifest.ParsePackIndex(file, filepath.Dir(indexPath))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", indexPath, err)
+	}
+
+	result := validator.VerifyPacks(packs, validatorOpts)
+	for _, failure := range result.Errors {
+		slog.Error("verifying pack failed", "path", failure.FilePath, "error", failure.Error)
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+	} else {
+		fmt.Fprintln(w, "Total Packs:", result.TotalPacks)
+		fmt.Fprintln(w, "Intact Packs:", result.IntactPacks)
+		fmt.Fprintln(w, "Total Chunks:", result.TotalChunks)
+		fmt.Fprintln(w, "Intact Chunks:", result.IntactChunks)
+		fmt.Fprintln(w, "\nCorrupted Chunks:")
+		if len(result.CorruptedPacks) == 0 {
+			fmt.Fprintln(w, "None")
+		} else {
+			tbl := table.New("Pack", "Offset", "Length", "Expected Hash", "Actual Hash", "Reason")
+			tbl.WithWriter(w)
+			tbl.WithHeaderSeparatorRow('-')
+			tbl.WithPadding(10)
+			for _, pack := range result.CorruptedPacks {
+				for _, chunk := range pack.CorruptedChunks {
+					tbl.AddRow(pack.FilePath, chunk.Offset, chunk.Length, chunk.ExpectedHash, chunk.ActualHash, chunk.Reason)
+				}
+			}
+			tbl.Print()
+		}
+		if len(result.MissingPacks) > 0 {
+			fmt.Fprintln(w, "\nMissing Packs:")
+			for _, path := range result.MissingPacks {
+				fmt.Fprintln(w, path)
+			}
+		}
+	}
+
+	if len(result.CorruptedPacks) > 0 || len(result.MissingPacks) > 0 || len(result.Errors) > 0 {
+		cmd.SilenceUsage = true
+		return errors.New("corrupted, missing or unreadable packs found")
+	}
+	return nil
+}
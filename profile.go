@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// withProfiling runs fn while recording the execution trace, CPU profile and
+// heap profile requested with --trace, --cpuprofile and --memprofile.
+func withProfiling(opts VerifyDataOptions, fn func() error) (err error) {
+	if opts.TraceFile != "" {
+		file, err := os.Create(opts.TraceFile)
+		if err != nil {
+			return fmt.Errorf("creating trace: %w", err)
+		}
+		defer closeProfile(opts.TraceFile, file)
+		if err := trace.Start(file); err != nil {
+			return fmt.Errorf("starting trace: %w", err)
+		}
+		defer trace.Stop()
+	}
+	if opts.CPUProfile != "" {
+		file, err := os.Create(opts.CPUProfile)
+		if err != nil {
+			return fmt.Errorf("creating CPU profile: %w", err)
+		}
+		defer closeProfile(opts.CPUProfile, file)
+		if err := pprof.StartCPUProfile(file); err != nil {
+			return fmt.Errorf("starting CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	err = fn()
+
+	if opts.MemProfile != "" {
+		file, createErr := os.Create(opts.MemProfile)
+		if createErr != nil {
+			slog.Error("creating memory profile failed", "path", opts.MemProfile, "error", createErr)
+			return err
+		}
+		defer closeProfile(opts.MemProfile, file)
+		// Collect garbage first so that the profile shows live objects only.
+		runtime.GC()
+		if writeErr := pprof.WriteHeapProfile(file); writeErr != nil {
+			slog.Error("writing memory profile failed", "path", opts.MemProfile, "error", writeErr)
+		}
+	}
+	return err
+}
+
+// closeProfile closes the file of a profile, logging rather than returning
+// an error so that it does not hide the outcome of the run.
+func closeProfile(path string, file *os.File) {
+	if err := file.Close(); err != nil {
+		slog.Error("closing profile failed", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/konidev20/verifydata/internal/ui"
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// startProgress starts reporting the progress of the run as requested with
+// --progress, --progress-json and --progress-interval. The returned function
+// stops reporting.
+func startProgress(cmd *cobra.Command, opts VerifyDataOptions, validatorOpts *validator.Options) (stop func(), err error) {
+	// Setting the interval asks for status lines, unless it is only meant for
+	// --progress-json. They replace the line --progress redraws in place.
+	statusLines := cmd.Flags().Changed("progress-interval") && (opts.Progress || opts.ProgressJSON == "")
+	if !opts.Progress && opts.ProgressJSON == "" && !statusLines {
+		return func() {}, nil
+	}
+	if opts.ProgressEvery <= 0 {
+		return nil, fmt.Errorf("--progress-interval must be positive")
+	}
+
+	var events io.Writer
+	var eventsFile *os.File
+	switch opts.ProgressJSON {
+	case "":
+	case "stderr", "2":
+		events = cmd.ErrOrStderr()
+	default:
+		fd, err := strconv.Atoi(opts.ProgressJSON)
+		if err != nil || fd < 3 {
+			return nil, fmt.Errorf("--progress-json must be stderr or a file descriptor number other than stdin and stdout, got %q", opts.ProgressJSON)
+		}
+		eventsFile = os.NewFile(uintptr(fd), "progress-json")
+		if eventsFile == nil {
+			return nil, fmt.Errorf("--progress-json: invalid file descriptor %d", fd)
+		}
+		events = eventsFile
+	}
+
+	validatorOpts.Progress = &validator.Progress{}
+	var stops []func()
+	if statusLines {
+		stops = append(stops, ui.LogProgress(cmd.ErrOrStderr(), validatorOpts.Progress, opts.ProgressEvery))
+	} else if opts.Progress {
+		stops = append(stops, ui.ShowProgress(cmd.ErrOrStderr(), validatorOpts.Progress, opts.ProgressEvery))
+	}
+	if events != nil {
+		stops = append(stops, ui.StreamProgress(events, validatorOpts.Progress, opts.ProgressEvery))
+	}
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+		// Closing the descriptor tells a wrapper reading it that the run is over.
+		if eventsFile != nil {
+			eventsFile.Close()
+		}
+	}, nil
+}
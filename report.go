@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/konidev20/verifydata/internal/signature"
+	"github.com/konidev20/verifydata/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// reportFormats are the values of --report-format, with how each is printed.
+var reportFormats = map[string]ui.Options{
+	"table":          {},
+	"compact":        {Compact: true},
+	"json":           {JSON: true},
+	"json-compact":   {CompactJSON: true},
+	"json-canonical": {CanonicalJSON: true},
+	"csv":            {CSV: true},
+	"sarif":          {SARIF: true},
+	"junit":          {JUnit: true},
+}
+
+// reportFormat returns how the --report file is printed in the format.
+func reportFormat(format string) (ui.Options, error) {
+	printOpts, ok := reportFormats[format]
+	if !ok {
+		return ui.Options{}, fmt.Errorf("unknown --report-format %q: expected table, compact, json, json-compact, json-canonical, csv, sarif or junit", format)
+	}
+	return printOpts, nil
+}
+
+// loadSigningKey reads the private key of --sign-report.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := signature.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("--sign-report: %w", err)
+	}
+	return key, nil
+}
+
+// signReport writes the signature of the report at reportPath next to it,
+// with the .sig extension added.
+func signReport(reportPath string, key ed25519.PrivateKey) error {
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		return err
+	}
+	sig, err := signature.Sign(key, report)
+	if err != nil {
+		return fmt.Errorf("signing %s: %w", reportPath, err)
+	}
+	return os.WriteFile(reportPath+".sig", sig, 0o644)
+}
+
+func newVerifyReportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-report <report.json> <signature> <public key>",
+		Short: "Check the signature of a report written with --sign-report",
+		Long: `verify-report checks that a JSON report was signed with the private key
+matching the given Ed25519 public key, and so was not altered since it was
+written with --report and --sign-report. The public key is a PEM file, as
+written by "openssl pkey -pubout", or the 32 byte key, raw or hex encoded.
+Reports are compared in their canonical JSON form, so a report that was only
+reformatted still verifies. The command exits with a non-zero status when the
+signature does not match.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVerifyReport(cmd, args[0], args[1], args[2])
+		},
+	}
+}
+
+func runVerifyReport(cmd *cobra.Command, reportPath, sigPath, keyPath string) error {
+	var files [3][]byte
+	for i, path := range []string{reportPath, sigPath, keyPath} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[i] = data
+	}
+	key, err := signature.ParsePublicKey(files[2])
+	if err != nil {
+		return err
+	}
+
+	err = signature.Verify(key, files[0], files[1])
+	if errors.Is(err, signature.ErrInvalid) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%s: %w", reportPath, err)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Signature OK:", reportPath)
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/konidev20/verifydata/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+func newSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the JSON output",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := cmd.OutOrStdout().Write(schema.Result)
+			return err
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/konidev20/verifydata/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var serveSocket string
+
+func newServeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Check files submitted over a Unix socket",
+		Long: `serve listens on the Unix socket given by --socket and checks the files that
+clients submit, one per line: either the path of a file, checked against the
+hash from its name like the files of --path, or a JSON object such as
+{"id": 1, "path": "/data/blob", "expected_hash": "6ae8..."}. Every result is
+written back as a JSON object on a line of its own, with the id of the
+request, once the file is checked, so results may come back in a different
+order than the requests. At most --workers files are checked at a time. The
+server runs until it is interrupted, then stops reading requests, answers
+those already read and exits, even while clients stay connected. A socket
+left behind by a server that crashed is replaced.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd, verifyDataOptions, serveSocket)
+		},
+	}
+	cmd.Flags().StringVar(&serveSocket, "socket", "", "Path of the Unix socket to listen on")
+	cmd.MarkFlagRequired("socket")
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, opts VerifyDataOptions, socket string) error {
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	listener, err := server.Listen(socket)
+	if err != nil {
+		return err
+	}
+	// Closing the listener removes the socket.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	slog.Info("serving", "socket", socket, "workers", validatorOpts.Workers)
+	if err := server.New(validatorOpts).Serve(listener); err != nil {
+		return err
+	}
+	slog.Info("stopped serving", "socket", socket)
+	return nil
+}
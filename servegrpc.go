@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/konidev20/verifydata/internal/rpc"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var serveGRPCAddr string
+
+func newServeGRPCCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-grpc",
+		Short: "Verify folders requested over gRPC, streaming the findings",
+		Long: `serve-grpc listens on the TCP address given by --addr and serves the Verifier
+service of internal/rpc/verifydata.proto. Its Verify call checks the folders of
+the request on the server and streams every file that is not intact as it is
+found, or every file with record_files, followed by a summary of every folder.
+The flags of the command line, such as --exclude, --template and --workers,
+apply to every request, which can add exclude patterns and change the number of
+workers. The server runs until it is interrupted, which stops the runs in
+progress. Connections are not encrypted and not authenticated, so --addr should
+only be reachable by trusted clients.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServeGRPC(cmd, verifyDataOptions, serveGRPCAddr)
+		},
+	}
+	cmd.Flags().StringVar(&serveGRPCAddr, "addr", "", "TCP address to listen on, such as localhost:7443")
+	cmd.MarkFlagRequired("addr")
+	return cmd
+}
+
+func runServeGRPC(cmd *cobra.Command, opts VerifyDataOptions, addr string) error {
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer()
+	rpc.RegisterVerifierServer(server, rpc.NewService(validatorOpts))
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+
+	slog.Info("serving gRPC", "addr", listener.Addr().String(), "workers", validatorOpts.Workers)
+	if err := server.Serve(listener); err != nil {
+		return err
+	}
+	slog.Info("stopped serving gRPC", "addr", addr)
+	return nil
+}
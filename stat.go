@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/konidev20/verifydata/internal/validator"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+var statExtensions bool
+
+func newStatCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stat",
+		Short: "Count the files of the given paths and their size without hashing them",
+		Long: `stat walks the given paths as a regular run does, skipping the same files, and
+reports the number of files that would be checked and their total size without
+reading any of them, to size up a store before verifying it. --extensions breaks
+the files down by extension and --size-histogram by size. Since no file is read,
+--exclude-type has no effect.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStat(cmd, verifyDataOptions, statExtensions)
+		},
+	}
+	cmd.Flags().BoolVar(&statExtensions, "extensions", false, "Report the number and size of the files of every extension")
+	return cmd
+}
+
+func runStat(cmd *cobra.Command, opts VerifyDataOptions, extensions bool) error {
+	folderPaths, err := getFolderPaths(opts)
+	if err != nil {
+		slog.Error("getting folder paths failed", "error", err)
+		return err
+	}
+	for _, folderPath := range folderPaths {
+		if isRemote(folderPath) {
+			return fmt.Errorf("stat does not support remote folders: %s", folderPath)
+		}
+	}
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	var results []*validator.StatResult
+	for _, folderPath := range folderPaths {
+		result, err := validator.Stat(folderPath, extensions, validatorOpts)
+		if err != nil {
+			slog.Error("walking folder failed", "folder", folderPath, "error", err)
+			cmd.SilenceUsage = true
+			return err
+		}
+		results = append(results, result)
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.JSON {
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Fprintln(w, string(jsonData))
+		return nil
+	}
+	for _, result := range results {
+		fmt.Fprintln(w, "Folder Path:", result.FolderPath)
+		fmt.Fprintln(w, "Total Files:", result.TotalFiles)
+		fmt.Fprintln(w, "Total Bytes:", result.TotalBytes)
+		fmt.Fprintln(w, "Excluded Files:", result.ExcludedFiles)
+		fmt.Fprintln(w, "Skipped Special:", result.SkippedSpecial)
+		if extensions {
+			fmt.Fprintln(w, "\nExtensions:")
+			tbl := table.New("Extension", "Files", "Bytes")
+			tbl.WithWriter(w)
+			tbl.WithHeaderSeparatorRow('-')
+			tbl.WithPadding(10)
+			for _, ext := range result.Extensions {
+				name := ext.Extension
+				if name == "" {
+					name = "(none)"
+				}
+				tbl.AddRow(name, ext.Files, ext.Bytes)
+			}
+			tbl.Print()
+		}
+		if result.SizeHistogram != nil {
+			fmt.Fprintln(w, "\nSize Histogram:")
+			tbl := table.New("Range", "Count")
+			tbl.WithWriter(w)
+			tbl.WithHeaderSeparatorRow('-')
+			tbl.WithPadding(10)
+			for _, bucket := range result.SizeHistogram {
+				tbl.AddRow(bucket.Range, bucket.Count)
+			}
+			tbl.Print()
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// version and commit are set at build time with
+//
+//	go build -ldflags "-X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD)"
+//
+// When they are not set, commit falls back to the VCS revision recorded by the Go toolchain.
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// toolVersion returns the version, commit and Go version, such as
+// "v1.2.3 (4f2a9c1, go1.22.1)".
+func toolVersion() string {
+	c := commit
+	if c == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					c = setting.Value
+				}
+			}
+		}
+	}
+	if len(c) > 12 {
+		c = c[:12]
+	}
+	if c == "" {
+		return fmt.Sprintf("%s (%s)", version, runtime.Version())
+	}
+	return fmt.Sprintf("%s (%s, %s)", version, c, runtime.Version())
+}
+
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit and Go version verifydata was built with",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintf(cmd.OutOrStdout(), "verifydata %s %s/%s\n", toolVersion(), runtime.GOOS, runtime.GOARCH)
+		},
+	}
+}
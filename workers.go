@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"github.com/konidev20/verifydata/internal/manifest"
+	"github.com/konidev20/verifydata/internal/storage"
+)
+
+// autoIOWorkers is the value of --workers picking the number of workers from
+// the kind of storage that is checked.
+const autoIOWorkers = "auto-io"
+
+// workersValue is the value of --workers: a number of workers or auto-io.
+type workersValue struct {
+	opts *VerifyDataOptions
+}
+
+func (v workersValue) String() string {
+	if v.opts.AutoWorkers {
+		return autoIOWorkers
+	}
+	return strconv.Itoa(v.opts.Workers)
+}
+
+func (v workersValue) Set(s string) error {
+	if s == autoIOWorkers {
+		v.opts.AutoWorkers = true
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("expected a number of workers or %s", autoIOWorkers)
+	}
+	v.opts.Workers, v.opts.AutoWorkers = n, false
+	return nil
+}
+
+func (v workersValue) Type() string {
+	return "int|" + autoIOWorkers
+}
+
+// autoWorkers returns the number of workers for the storage of the first
+// --path, or of the files of --manifest.
+func autoWorkers(opts VerifyDataOptions) int {
+	path := "."
+	switch {
+	case opts.Manifest != "" && opts.ManifestDir != "":
+		path = opts.ManifestDir
+	case opts.Manifest != "" && !manifest.IsURL(opts.Manifest):
+		path = filepath.Dir(opts.Manifest)
+	case opts.Manifest == "" && len(opts.Paths) > 0:
+		path = opts.Paths[0]
+	}
+
+	kind := storage.Network
+	if !isRemote(path) {
+		kind = storage.Detect(path)
+	}
+	workers := kind.Workers()
+	if opts.Verbose {
+		slog.Info("picked the number of workers for the storage", "path", path, "storage", kind, "workers", workers)
+	}
+	return workers
+}